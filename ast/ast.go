@@ -2,6 +2,7 @@ package ast
 
 import "strconv"
 import "bytes"
+import "fmt"
 
 type NodeType string
 
@@ -22,6 +23,8 @@ const (
 	ContinueStatementType    NodeType = "ContinueStatement"
 	ImportStatementType      NodeType = "ImportStatement"
 	ClassDeclarationType     NodeType = "ClassDeclaration"
+	ThrowStatementType       NodeType = "ThrowStatement"
+	TryStatementType         NodeType = "TryStatement"
 	CommentType              NodeType = "Comment"
 
 	PropertyType              NodeType = "Property"
@@ -36,16 +39,50 @@ const (
 	UnaryExpressionType       NodeType = "UnaryExpression"
 	LogicalExpressionType     NodeType = "LogicalExpression"
 	StringLiteralType         NodeType = "StringLiteral"
+	TemplateLiteralType       NodeType = "TemplateLiteral"
 	NullLiteralType           NodeType = "NullLiteral"
 	TypeDeclarationType       NodeType = "TypeDeclaration"
+	ObjectTypeLiteralType     NodeType = "ObjectTypeLiteral"
 )
 
+// Position is the place in the source a node was parsed from. Line and Column
+// start at 1; a zero Line means the position is unknown.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) Pos() Position {
+	return p
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.File != "" {
+			return p.File
+		}
+		return "unknown position"
+	}
+	if p.File == "" {
+		return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
 type Statement interface {
 	Kind() NodeType
 	ToString() string
+	Pos() Position
 }
 
 type Program struct {
+	Position
+
 	Body []Statement
 }
 
@@ -83,6 +120,8 @@ const (
 )
 
 type VariableDeclaration struct {
+	Position
+
 	Constant          bool
 	Identifier        string
 	Value             Expression
@@ -108,12 +147,48 @@ func (v *VariableDeclaration) ToString() string {
 	return s
 }
 
+// Parameter is a single entry of a function's parameter list. Type is AnyType
+// when the parameter is untyped; UserDefinedType is set when it names a type
+// alias or object type. Default is evaluated when the argument is omitted,
+// and a rest parameter collects the remaining arguments into an array.
+type Parameter struct {
+	Position
+
+	Name            string
+	Type            VariableType
+	UserDefinedType Expression
+	Default         Expression
+	IsRest          bool
+}
+
+func (p Parameter) ToString() string {
+	s := p.Name
+	if p.IsRest {
+		s = "..." + s
+	}
+	if p.UserDefinedType != nil {
+		s += ": " + p.UserDefinedType.ToString()
+	} else if p.Type != AnyType {
+		s += ": " + string(p.Type)
+	}
+	if p.Default != nil {
+		s += " = " + p.Default.ToString()
+	}
+	return s
+}
+
 type FunctionDeclaration struct {
-	Parameters  []string
+	Position
+
+	Parameters  []Parameter
 	Name        string
 	Body        []Statement
 	ReturnType  VariableType
 	IsAnonymous bool
+
+	// UserDefinedReturnType is the type alias or object type written after
+	// the parameter list, nil when the return type is builtin.
+	UserDefinedReturnType Expression
 }
 
 func (f *FunctionDeclaration) Kind() NodeType {
@@ -131,7 +206,7 @@ func (f *FunctionDeclaration) ToString() string {
 		if i > 0 {
 			s += ", "
 		}
-		s += param
+		s += param.ToString()
 	}
 	s += ") {\n"
 
@@ -150,13 +225,15 @@ func (f *FunctionDeclaration) CloneBody() []Statement {
 	return body
 }
 
-func (f *FunctionDeclaration) CloneParameters() []string {
-	params := make([]string, len(f.Parameters))
+func (f *FunctionDeclaration) CloneParameters() []Parameter {
+	params := make([]Parameter, len(f.Parameters))
 	copy(params, f.Parameters)
 	return params
 }
 
 type ReturnStatement struct {
+	Position
+
 	Value Expression
 }
 
@@ -168,7 +245,9 @@ func (r *ReturnStatement) ToString() string {
 	return "return " + r.Value.ToString()
 }
 
-type BreakStatement struct{}
+type BreakStatement struct {
+	Position
+}
 
 func (b *BreakStatement) Kind() NodeType {
 	return BreakStatementType
@@ -178,7 +257,9 @@ func (b *BreakStatement) ToString() string {
 	return "break"
 }
 
-type ContinueStatement struct{}
+type ContinueStatement struct {
+	Position
+}
 
 func (c *ContinueStatement) Kind() NodeType {
 	return ContinueStatementType
@@ -188,7 +269,68 @@ func (c *ContinueStatement) ToString() string {
 	return "continue"
 }
 
+type ThrowStatement struct {
+	Position
+
+	Value Expression
+}
+
+func (t *ThrowStatement) Kind() NodeType {
+	return ThrowStatementType
+}
+
+func (t *ThrowStatement) ToString() string {
+	return "throw " + t.Value.ToString()
+}
+
+// TryStatement is `try { } catch (name) { } finally { }`. At least one of the
+// catch and finally clauses is present; the catch clause may omit its name.
+type TryStatement struct {
+	Position
+
+	Body       []Statement
+	HasCatch   bool
+	CatchName  string
+	Catch      []Statement
+	HasFinally bool
+	Finally    []Statement
+}
+
+func (t *TryStatement) Kind() NodeType {
+	return TryStatementType
+}
+
+func (t *TryStatement) ToString() string {
+	s := "try {\n"
+	for _, statement := range t.Body {
+		s += statement.ToString()
+	}
+	s += "}"
+	if t.HasCatch {
+		s += " catch "
+		if t.CatchName != "" {
+			s += "(" + t.CatchName + ") "
+		}
+		s += "{\n"
+		for _, statement := range t.Catch {
+			s += statement.ToString()
+		}
+		s += "}"
+	}
+	if t.HasFinally {
+		s += " finally {\n"
+		for _, statement := range t.Finally {
+			s += statement.ToString()
+		}
+		s += "}"
+	}
+
+	return s + "\n"
+}
+
 type ImportStatement struct {
+	Position
+
 	Path string
 }
 
@@ -201,8 +343,11 @@ func (i *ImportStatement) ToString() string {
 }
 
 type ClassDeclaration struct {
-	Name string
-	Body []Statement
+	Position
+
+	Name   string
+	Parent string
+	Body   []Statement
 }
 
 func (c *ClassDeclaration) Kind() NodeType {
@@ -210,7 +355,11 @@ func (c *ClassDeclaration) Kind() NodeType {
 }
 
 func (c *ClassDeclaration) ToString() string {
-	s := "class " + c.Name + " {\n"
+	s := "class " + c.Name
+	if c.Parent != "" {
+		s += " extends " + c.Parent
+	}
+	s += " {\n"
 
 	for _, statement := range c.Body {
 		s += statement.ToString()
@@ -222,6 +371,8 @@ func (c *ClassDeclaration) ToString() string {
 }
 
 type Comment struct {
+	Position
+
 	Text string
 }
 
@@ -238,6 +389,8 @@ type Expression interface {
 }
 
 type ConditionalStatement struct {
+	Position
+
 	Condition        Expression
 	Body             []Statement
 	ElseIfConditions []Expression
@@ -264,6 +417,8 @@ func (c *ConditionalStatement) ToString() string {
 }
 
 type WhileStatement struct {
+	Position
+
 	Condition Expression
 	Body      []Statement
 }
@@ -283,6 +438,8 @@ func (w *WhileStatement) ToString() string {
 }
 
 type LoopStatement struct {
+	Position
+
 	Body []Statement
 }
 
@@ -301,6 +458,8 @@ func (l *LoopStatement) ToString() string {
 }
 
 type ForEachStatement struct {
+	Position
+
 	Variable   string
 	Key        string
 	Value      string
@@ -323,6 +482,8 @@ func (f *ForEachStatement) ToString() string {
 }
 
 type ForStatement struct {
+	Position
+
 	Init      Statement
 	Condition Expression
 	Update    Expression
@@ -344,6 +505,8 @@ func (f *ForStatement) ToString() string {
 }
 
 type AssignmentExpression struct {
+	Position
+
 	Assignee Expression
 	Value    Expression
 }
@@ -357,6 +520,8 @@ func (a *AssignmentExpression) ToString() string {
 }
 
 type BinaryExpression struct {
+	Position
+
 	Left     Expression
 	Right    Expression
 	Operator string
@@ -371,6 +536,8 @@ func (b *BinaryExpression) ToString() string {
 }
 
 type UnaryExpression struct {
+	Position
+
 	Value    Expression
 	Operator string
 }
@@ -384,6 +551,8 @@ func (u *UnaryExpression) ToString() string {
 }
 
 type LogicalExpression struct {
+	Position
+
 	Left     Expression
 	Right    Expression
 	Operator string
@@ -402,6 +571,8 @@ func (l *LogicalExpression) ToString() string {
 }
 
 type Identifier struct {
+	Position
+
 	Symbol string
 	// VarType VariableType
 }
@@ -415,6 +586,8 @@ func (i *Identifier) ToString() string {
 }
 
 type NumericIntegerLiteral struct {
+	Position
+
 	Value int64
 }
 
@@ -427,6 +600,8 @@ func (n *NumericIntegerLiteral) ToString() string {
 }
 
 type NumericFloatLiteral struct {
+	Position
+
 	Value float64
 }
 
@@ -439,6 +614,8 @@ func (n *NumericFloatLiteral) ToString() string {
 }
 
 type NumericLiteral struct {
+	Position
+
 	Value float64
 }
 
@@ -451,6 +628,8 @@ func (n *NumericLiteral) ToString() string {
 }
 
 type StringLiteral struct {
+	Position
+
 	Value string
 }
 
@@ -462,7 +641,31 @@ func (s *StringLiteral) ToString() string {
 	return "\"" + s.Value + "\""
 }
 
-type NullLiteral struct{}
+// TemplateLiteral is a backtick string with ${} expressions. Strings holds
+// the text around the expressions, so it has one more element than
+// Expressions.
+type TemplateLiteral struct {
+	Position
+
+	Strings     []string
+	Expressions []Expression
+}
+
+func (t *TemplateLiteral) Kind() NodeType {
+	return TemplateLiteralType
+}
+
+func (t *TemplateLiteral) ToString() string {
+	str := "`" + t.Strings[0]
+	for i, expression := range t.Expressions {
+		str += "${" + expression.ToString() + "}" + t.Strings[i+1]
+	}
+	return str + "`"
+}
+
+type NullLiteral struct {
+	Position
+}
 
 func (n *NullLiteral) Kind() NodeType {
 	return NullLiteralType
@@ -473,6 +676,8 @@ func (n *NullLiteral) ToString() string {
 }
 
 type Property struct {
+	Position
+
 	Key   string
 	Value Expression
 }
@@ -486,6 +691,8 @@ func (p *Property) ToString() string {
 }
 
 type ObjectLiteral struct {
+	Position
+
 	Properties []Property
 }
 
@@ -507,6 +714,8 @@ func (o *ObjectLiteral) ToString() string {
 }
 
 type ArrayLiteral struct {
+	Position
+
 	Elements []Expression
 }
 
@@ -528,6 +737,8 @@ func (a *ArrayLiteral) ToString() string {
 }
 
 type TupleLiteral struct {
+	Position
+
 	Elements []Expression
 }
 
@@ -549,8 +760,20 @@ func (t *TupleLiteral) ToString() string {
 }
 
 type CallExpression struct {
-	Args   []Expression
-	Caller Expression
+	Position
+
+	Args []Expression
+	// NamedArgs are the arguments passed by parameter name, as in
+	// f(1, b: 2). They follow Args.
+	NamedArgs []NamedArgument
+	Caller    Expression
+}
+
+type NamedArgument struct {
+	Position
+
+	Name  string
+	Value Expression
 }
 
 func (c CallExpression) Kind() NodeType {
@@ -563,7 +786,13 @@ func (c CallExpression) ToString() string {
 	buffer.WriteString("(")
 	for i, arg := range c.Args {
 		buffer.WriteString(arg.ToString())
-		if i < len(c.Args)-1 {
+		if i < len(c.Args)-1 || len(c.NamedArgs) > 0 {
+			buffer.WriteString(", ")
+		}
+	}
+	for i, arg := range c.NamedArgs {
+		buffer.WriteString(arg.Name + " = " + arg.Value.ToString())
+		if i < len(c.NamedArgs)-1 {
 			buffer.WriteString(", ")
 		}
 	}
@@ -572,6 +801,8 @@ func (c CallExpression) ToString() string {
 }
 
 type MemberExpression struct {
+	Position
+
 	Object   Expression
 	Property Expression
 	Computed bool
@@ -590,6 +821,8 @@ func (m *MemberExpression) ToString() string {
 }
 
 type TypeDeclaration struct {
+	Position
+
 	Name string
 	Type Expression
 }
@@ -599,5 +832,39 @@ func (t *TypeDeclaration) Kind() NodeType {
 }
 
 func (t *TypeDeclaration) ToString() string {
-	return t.Name + ": " + t.Type.ToString()
+	return "type " + t.Name + " = " + t.Type.ToString() + ";\n"
+}
+
+// TypeField is a single `name: type` entry of an object type. Type is either
+// an Identifier naming a builtin type or alias, or a nested ObjectTypeLiteral.
+type TypeField struct {
+	Position
+
+	Name string
+	Type Expression
+}
+
+type ObjectTypeLiteral struct {
+	Position
+
+	Fields []TypeField
+}
+
+func (o *ObjectTypeLiteral) Kind() NodeType {
+	return ObjectTypeLiteralType
+}
+
+func (o *ObjectTypeLiteral) ToString() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("{ ")
+	for i, field := range o.Fields {
+		buffer.WriteString(field.Name)
+		buffer.WriteString(": ")
+		buffer.WriteString(field.Type.ToString())
+		if i < len(o.Fields)-1 {
+			buffer.WriteString(", ")
+		}
+	}
+	buffer.WriteString(" }")
+	return buffer.String()
 }
@@ -0,0 +1,817 @@
+// Package checker type-checks a parsed Jamlang program without running it.
+package checker
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+// Checker infers the types of expressions in a program and reports
+// mismatched annotations, wrong return types, undeclared identifiers and
+// calls with the wrong number of arguments.
+type Checker struct {
+	// Importer loads the program behind an import statement. When it is nil
+	// imports are not followed, and names they could declare are not
+	// reported as undeclared.
+	Importer func(path string) (ast.Program, error)
+
+	globals     []string
+	diagnostics []parser.Diagnostic
+}
+
+// NewChecker returns a Checker for programs run in an environment that
+// already declares globals.
+func NewChecker(globals []string) *Checker {
+	return &Checker{globals: globals}
+}
+
+type symbol struct {
+	typ Type
+	// declared is the annotated type, anyType when the variable is untyped.
+	declared Type
+	constant bool
+	// typeExpr is set for names declared with `type`.
+	typeExpr ast.Expression
+	class    *class
+}
+
+type class struct {
+	name        string
+	parent      *class
+	constructor *Signature
+}
+
+type scope struct {
+	parent   *scope
+	symbols  map[string]*symbol
+	function *Signature
+	// opaque is set once an import that could not be followed ran in this
+	// scope.
+	opaque bool
+	// deferred holds function bodies, checked once the enclosing block is
+	// complete so that they see names declared after them.
+	deferred []func()
+}
+
+func newScope(parent *scope) *scope {
+	s := &scope{
+		parent:  parent,
+		symbols: make(map[string]*symbol),
+	}
+	if parent != nil {
+		s.function = parent.function
+	}
+	return s
+}
+
+func (s *scope) lookup(name string) (*symbol, bool) {
+	for current := s; current != nil; current = current.parent {
+		if sym, ok := current.symbols[name]; ok {
+			return sym, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) isOpaque() bool {
+	for current := s; current != nil; current = current.parent {
+		if current.opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the diagnostics for program, sorted by position.
+func (c *Checker) Check(program ast.Program) []parser.Diagnostic {
+	c.diagnostics = nil
+
+	global := newScope(nil)
+	for _, name := range c.globals {
+		global.symbols[name] = &symbol{typ: anyType, declared: anyType, constant: true}
+	}
+	global.symbols["true"] = &symbol{typ: Type{Kind: ast.BoolType}, constant: true}
+	global.symbols["false"] = &symbol{typ: Type{Kind: ast.BoolType}, constant: true}
+	global.symbols["null"] = &symbol{typ: Type{Kind: ast.NullType}, constant: true}
+
+	c.checkBlock(program.Body, global)
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i].Position, c.diagnostics[j].Position
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return c.diagnostics
+}
+
+func (c *Checker) report(pos ast.Position, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, parser.Diagnostic{
+		Position: pos,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *Checker) declare(s *scope, pos ast.Position, name string, sym *symbol) {
+	if _, ok := s.symbols[name]; ok {
+		c.report(pos, "%s already declared", name)
+	}
+	s.symbols[name] = sym
+}
+
+func (c *Checker) checkBlock(body []ast.Statement, s *scope) {
+	for _, statement := range body {
+		if declaration, ok := statement.(*ast.TypeDeclaration); ok {
+			c.declare(s, declaration.Pos(), declaration.Name, &symbol{
+				typ:      anyType,
+				constant: true,
+				typeExpr: declaration.Type,
+			})
+		}
+	}
+
+	for _, statement := range body {
+		c.statement(statement, s)
+	}
+
+	for len(s.deferred) > 0 {
+		deferred := s.deferred
+		s.deferred = nil
+		for _, check := range deferred {
+			check()
+		}
+	}
+}
+
+func (c *Checker) statement(statement ast.Statement, s *scope) {
+	switch statement := statement.(type) {
+	case nil:
+	case *ast.Comment, *ast.BreakStatement, *ast.ContinueStatement:
+	case *ast.VariableDeclaration:
+		c.variableDeclaration(statement, s)
+	case *ast.FunctionDeclaration:
+		c.function(statement, s)
+	case *ast.ClassDeclaration:
+		c.class(statement, s)
+	case *ast.TypeDeclaration:
+		c.typeExpression(statement.Type, s)
+	case *ast.ImportStatement:
+		c.importStatement(statement, s)
+	case *ast.ReturnStatement:
+		value := c.expr(statement.Value, s)
+		if s.function != nil {
+			c.assign(statement.Value.Pos(), value, s.function.Result, "return value of "+signatureName(s.function), false, s)
+		}
+	case *ast.ConditionalStatement:
+		c.condition(statement.Condition, s)
+		c.checkBlock(statement.Body, newScope(s))
+		for i, condition := range statement.ElseIfConditions {
+			c.condition(condition, s)
+			c.checkBlock(statement.ElseIfBodies[i], newScope(s))
+		}
+		c.checkBlock(statement.Alternate, newScope(s))
+	case *ast.WhileStatement:
+		c.condition(statement.Condition, s)
+		c.checkBlock(statement.Body, newScope(s))
+	case *ast.LoopStatement:
+		c.checkBlock(statement.Body, newScope(s))
+	case *ast.ForEachStatement:
+		c.expr(statement.Collection, s)
+		inner := newScope(s)
+		for _, name := range []string{statement.Variable, statement.Key, statement.Value} {
+			if name != "" {
+				inner.symbols[name] = &symbol{typ: anyType, declared: anyType}
+			}
+		}
+		c.checkBlock(statement.Body, inner)
+	case *ast.ForStatement:
+		inner := newScope(s)
+		c.statement(statement.Init, inner)
+		if statement.Condition != nil {
+			c.condition(statement.Condition, inner)
+		}
+		if statement.Update != nil {
+			c.expr(statement.Update, inner)
+		}
+		c.checkBlock(statement.Body, newScope(inner))
+	case *ast.ThrowStatement:
+		c.expr(statement.Value, s)
+	case *ast.TryStatement:
+		c.checkBlock(statement.Body, newScope(s))
+		inner := newScope(s)
+		if statement.CatchName != "" {
+			inner.symbols[statement.CatchName] = &symbol{typ: anyType, declared: anyType}
+		}
+		c.checkBlock(statement.Catch, inner)
+		c.checkBlock(statement.Finally, newScope(s))
+	default:
+		c.expr(statement, s)
+	}
+}
+
+func (c *Checker) condition(condition ast.Expression, s *scope) {
+	t := c.expr(condition, s)
+	if !t.isAny() && t.Kind != ast.BoolType {
+		c.report(condition.Pos(), "Condition must be bool, got %s", t)
+	}
+}
+
+func (c *Checker) variableDeclaration(declaration *ast.VariableDeclaration, s *scope) {
+	value := c.expr(declaration.Value, s)
+
+	declared := Type{Kind: declaration.Type}
+	if declaration.IsUserDefinedType {
+		declared = c.annotation(declaration.UserDefinedType, s)
+	}
+	c.assign(declaration.Value.Pos(), value, declared, declaration.Identifier, false, s)
+
+	typ := declared
+	if declared.isAny() || (declared.Kind == ast.FunctionType && value.Signature != nil) {
+		typ = value
+	}
+
+	c.declare(s, declaration.Pos(), declaration.Identifier, &symbol{
+		typ:      typ,
+		declared: declared,
+		constant: declaration.Constant,
+	})
+}
+
+func (c *Checker) signature(declaration *ast.FunctionDeclaration, s *scope) *Signature {
+	result := Type{Kind: declaration.ReturnType}
+	if declaration.UserDefinedReturnType != nil {
+		result = c.annotation(declaration.UserDefinedReturnType, s)
+	}
+
+	return &Signature{
+		Name:       declaration.Name,
+		Parameters: declaration.Parameters,
+		Result:     result,
+	}
+}
+
+func (c *Checker) function(declaration *ast.FunctionDeclaration, s *scope) Type {
+	sig := c.signature(declaration, s)
+	t := Type{Kind: ast.FunctionType, Signature: sig}
+
+	if declaration.Name != "" {
+		c.declare(s, declaration.Pos(), declaration.Name, &symbol{
+			typ:      t,
+			declared: anyType,
+			constant: true,
+		})
+	}
+
+	s.deferred = append(s.deferred, func() {
+		c.functionBody(declaration, sig, newScope(s))
+	})
+
+	return t
+}
+
+func (c *Checker) functionBody(declaration *ast.FunctionDeclaration, sig *Signature, inner *scope) {
+	inner.function = sig
+
+	for _, param := range declaration.Parameters {
+		t := c.parameterType(param, inner)
+		if param.Default != nil {
+			c.assign(param.Default.Pos(), c.expr(param.Default, inner), t, "argument "+param.Name, false, inner)
+		}
+		if param.IsRest {
+			t = Type{Kind: ast.ArrayType}
+		}
+		inner.symbols[param.Name] = &symbol{typ: t, declared: t}
+	}
+
+	c.checkBlock(declaration.Body, inner)
+}
+
+func (c *Checker) parameterType(param ast.Parameter, s *scope) Type {
+	if param.UserDefinedType != nil {
+		return c.annotation(param.UserDefinedType, s)
+	}
+	return Type{Kind: param.Type}
+}
+
+func (c *Checker) class(declaration *ast.ClassDeclaration, s *scope) {
+	cls := &class{name: declaration.Name}
+
+	if declaration.Parent != "" {
+		parent, ok := s.lookup(declaration.Parent)
+		switch {
+		case !ok:
+			c.undeclared(declaration.Pos(), declaration.Parent, s)
+		case parent.class == nil:
+			if !parent.typ.isAny() || parent.typeExpr != nil {
+				c.report(declaration.Pos(), "Class %s cannot extend %s, which is not a class", declaration.Name, declaration.Parent)
+			}
+		default:
+			cls.parent = parent.class
+		}
+	}
+
+	instance := Type{Kind: ast.ObjectType, Name: declaration.Name}
+	inner := newScope(s)
+	inner.symbols["this"] = &symbol{typ: instance, declared: instance, constant: true}
+	if declaration.Parent != "" {
+		inner.symbols["super"] = &symbol{typ: anyType, declared: anyType, constant: true}
+	}
+
+	methods := make(map[string]bool)
+	for _, member := range declaration.Body {
+		switch member := member.(type) {
+		case *ast.FunctionDeclaration:
+			sig := c.signature(member, s)
+			if member.Name == "constructor" {
+				cls.constructor = &Signature{
+					Name:       declaration.Name,
+					Parameters: sig.Parameters,
+					Result:     instance,
+					Constructs: true,
+				}
+			} else if methods[member.Name] {
+				c.report(member.Pos(), "Method %s already declared in class %s", member.Name, declaration.Name)
+			}
+			methods[member.Name] = true
+
+			s.deferred = append(s.deferred, func() {
+				c.functionBody(member, sig, newScope(inner))
+			})
+		case *ast.VariableDeclaration:
+			c.variableDeclaration(member, newScope(s))
+		}
+	}
+
+	constructor := &Signature{Name: declaration.Name, Result: instance, Constructs: true}
+	for current := cls; current != nil; current = current.parent {
+		if current.constructor != nil {
+			constructor = &Signature{
+				Name:       declaration.Name,
+				Parameters: current.constructor.Parameters,
+				Result:     instance,
+				Constructs: true,
+			}
+			break
+		}
+	}
+
+	c.declare(s, declaration.Pos(), declaration.Name, &symbol{
+		typ:      Type{Kind: ast.AnyType, Signature: constructor},
+		declared: anyType,
+		constant: true,
+		class:    cls,
+	})
+}
+
+func (c *Checker) importStatement(statement *ast.ImportStatement, s *scope) {
+	if c.Importer == nil {
+		s.opaque = true
+		return
+	}
+
+	program, err := c.Importer(statement.Path)
+	if err != nil {
+		c.report(statement.Pos(), "%s", err.Error())
+		s.opaque = true
+		return
+	}
+
+	// Like the runtime, which runs the imported functions and classes in the
+	// importing scope, every function and class is declared, but only
+	// capitalized types and variables.
+	exported := func(name string) bool {
+		return name[0] >= 'A' && name[0] <= 'Z'
+	}
+
+	imported := newScope(nil)
+	imported.opaque = true
+	for _, statement := range program.Body {
+		switch statement := statement.(type) {
+		case *ast.FunctionDeclaration:
+			s.symbols[statement.Name] = &symbol{
+				typ:      Type{Kind: ast.FunctionType, Signature: c.signature(statement, imported)},
+				declared: anyType,
+				constant: true,
+			}
+		case *ast.ClassDeclaration:
+			s.symbols[statement.Name] = &symbol{typ: anyType, declared: anyType, constant: true, class: &class{name: statement.Name}}
+		case *ast.TypeDeclaration:
+			if exported(statement.Name) {
+				s.symbols[statement.Name] = &symbol{typ: anyType, constant: true, typeExpr: statement.Type}
+			}
+		case *ast.VariableDeclaration:
+			if exported(statement.Identifier) {
+				s.symbols[statement.Identifier] = &symbol{typ: anyType, declared: anyType}
+			}
+		}
+	}
+}
+
+func (c *Checker) undeclared(pos ast.Position, name string, s *scope) {
+	if !s.isOpaque() {
+		c.report(pos, "%s is not declared", name)
+	}
+}
+
+// typeExpression reports names in a type that do not refer to types.
+func (c *Checker) typeExpression(typeExpr ast.Expression, s *scope) {
+	switch typeExpr := typeExpr.(type) {
+	case *ast.Identifier:
+		c.annotation(typeExpr, s)
+	case *ast.ObjectTypeLiteral:
+		for _, field := range typeExpr.Fields {
+			c.typeExpression(field.Type, s)
+		}
+	}
+}
+
+// annotation resolves a type written after `:`, following type aliases.
+func (c *Checker) annotation(typeExpr ast.Expression, s *scope) Type {
+	switch typeExpr := typeExpr.(type) {
+	case *ast.ObjectTypeLiteral:
+		return Type{Kind: ast.ObjectType, Object: typeExpr}
+	case *ast.Identifier:
+		name := typeExpr.Symbol
+		seen := make(map[string]bool)
+		for {
+			if kind, ok := parser.Types[name]; ok {
+				t := Type{Kind: kind}
+				if name != typeExpr.Symbol {
+					t.Name = typeExpr.Symbol
+				}
+				return t
+			}
+			if seen[name] {
+				c.report(typeExpr.Pos(), "Type %s refers to itself", name)
+				return anyType
+			}
+			seen[name] = true
+
+			sym, ok := s.lookup(name)
+			if !ok {
+				if !s.isOpaque() {
+					c.report(typeExpr.Pos(), "Unknown type %s", name)
+				}
+				return anyType
+			}
+			if sym.typeExpr == nil {
+				c.report(typeExpr.Pos(), "%s is not a type", name)
+				return anyType
+			}
+
+			switch target := sym.typeExpr.(type) {
+			case *ast.Identifier:
+				name = target.Symbol
+			case *ast.ObjectTypeLiteral:
+				return Type{Kind: ast.ObjectType, Name: typeExpr.Symbol, Object: target}
+			default:
+				return anyType
+			}
+		}
+	default:
+		return anyType
+	}
+}
+
+// assign reports if a value of type from cannot be stored where to is
+// expected. strict applies the rules for assigning to a variable with a
+// builtin annotation, where a number may only widen; otherwise any integer
+// fits an integer type and any float a float type, as for declarations,
+// arguments, return values and type aliases, since whether it fits depends on
+// its value.
+func (c *Checker) assign(pos ast.Position, from, to Type, what string, strict bool, s *scope) {
+	if (to.isAny() && to.Object == nil) || from.isAny() {
+		return
+	}
+
+	mismatch := func() {
+		c.report(pos, "Expected %s for %s, got %s", to, what, from)
+	}
+
+	if to.Object != nil {
+		switch {
+		case from.Kind == ast.NullType:
+		case from.Object == to.Object, from.Name != "" && from.Name == to.Name:
+		case from.Fields != nil:
+			c.assignFields(pos, from, to, what, s)
+		case from.Kind == ast.ObjectType && from.Object == nil:
+		default:
+			mismatch()
+		}
+		return
+	}
+
+	switch {
+	case isInteger(to.Kind):
+		if !isInteger(from.Kind) || (strict && integerRanks[from.Kind] > integerRanks[to.Kind]) {
+			mismatch()
+		}
+	case isFloat(to.Kind):
+		if !isFloat(from.Kind) || (strict && floatRanks[from.Kind] > floatRanks[to.Kind]) {
+			mismatch()
+		}
+	case to.Kind == ast.ObjectType:
+		if from.Kind != ast.ObjectType && from.Kind != ast.NullType {
+			mismatch()
+		}
+	case to.Kind == ast.FunctionType:
+		if from.Kind != ast.FunctionType && from.Signature == nil {
+			mismatch()
+		}
+	default:
+		if from.Kind != to.Kind {
+			mismatch()
+		}
+	}
+}
+
+func (c *Checker) assignFields(pos ast.Position, from, to Type, what string, s *scope) {
+	fields := make(map[string]bool)
+	for _, field := range to.Object.Fields {
+		fields[field.Name] = true
+		value, ok := from.Fields[field.Name]
+		if !ok {
+			c.report(pos, "%s is missing field %s of %s", what, field.Name, to)
+			continue
+		}
+		c.assign(pos, value, c.annotation(field.Type, s), what+"."+field.Name, false, s)
+	}
+
+	var extra []string
+	for name := range from.Fields {
+		if !fields[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		c.report(pos, "%s has unexpected field %s, not in %s", what, name, to)
+	}
+}
+
+func signatureName(sig *Signature) string {
+	if sig.Name == "" {
+		return "<anonymous>"
+	}
+	return sig.Name
+}
+
+func (c *Checker) expr(expr ast.Expression, s *scope) Type {
+	switch expr := expr.(type) {
+	case nil:
+		return anyType
+	case *ast.NumericIntegerLiteral:
+		if expr.Value >= math.MinInt32 && expr.Value <= math.MaxInt32 {
+			return Type{Kind: ast.Int32Type}
+		}
+		return Type{Kind: ast.Int64Type}
+	case *ast.NumericFloatLiteral:
+		if math.Abs(expr.Value) <= 0x1.0p127 {
+			return Type{Kind: ast.Float32Type}
+		}
+		return Type{Kind: ast.Float64Type}
+	case *ast.StringLiteral:
+		return Type{Kind: ast.StringType}
+	case *ast.TemplateLiteral:
+		for _, expression := range expr.Expressions {
+			c.expr(expression, s)
+		}
+		return Type{Kind: ast.StringType}
+	case *ast.NullLiteral:
+		return Type{Kind: ast.NullType}
+	case *ast.Identifier:
+		sym, ok := s.lookup(expr.Symbol)
+		if !ok {
+			c.undeclared(expr.Pos(), expr.Symbol, s)
+			return anyType
+		}
+		return sym.typ
+	case *ast.ObjectLiteral:
+		fields := make(map[string]Type)
+		for _, property := range expr.Properties {
+			if property.Value == nil {
+				fields[property.Key] = c.expr(&ast.Identifier{Position: property.Position, Symbol: property.Key}, s)
+				continue
+			}
+			fields[property.Key] = c.expr(property.Value, s)
+		}
+		return Type{Kind: ast.ObjectType, Fields: fields}
+	case *ast.ArrayLiteral:
+		for _, element := range expr.Elements {
+			c.expr(element, s)
+		}
+		return Type{Kind: ast.ArrayType}
+	case *ast.TupleLiteral:
+		for _, element := range expr.Elements {
+			c.expr(element, s)
+		}
+		return Type{Kind: ast.TupleType}
+	case *ast.FunctionDeclaration:
+		return c.function(expr, s)
+	case *ast.BinaryExpression:
+		return c.binary(expr, s)
+	case *ast.UnaryExpression:
+		value := c.expr(expr.Value, s)
+		if expr.Operator == "!" {
+			if !value.isAny() && value.Kind != ast.BoolType {
+				c.report(expr.Pos(), "! operator can only be applied to bool, got %s", value)
+			}
+			return Type{Kind: ast.BoolType}
+		}
+		return value
+	case *ast.LogicalExpression:
+		c.expr(expr.Left, s)
+		c.expr(expr.Right, s)
+		return Type{Kind: ast.BoolType}
+	case *ast.MemberExpression:
+		return c.member(expr, s)
+	case *ast.CallExpression:
+		return c.call(expr, s)
+	case *ast.AssignmentExpression:
+		return c.assignment(expr, s)
+	default:
+		return anyType
+	}
+}
+
+func (c *Checker) binary(expr *ast.BinaryExpression, s *scope) Type {
+	left := c.expr(expr.Left, s)
+	right := c.expr(expr.Right, s)
+
+	switch expr.Operator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return Type{Kind: ast.BoolType}
+	}
+
+	if expr.Operator == "+" && (left.Kind == ast.StringType || right.Kind == ast.StringType) {
+		return Type{Kind: ast.StringType}
+	}
+
+	switch {
+	case isInteger(left.Kind) && isInteger(right.Kind):
+		if integerRanks[left.Kind] >= integerRanks[right.Kind] {
+			return Type{Kind: left.Kind}
+		}
+		return Type{Kind: right.Kind}
+	case isFloat(left.Kind) && isFloat(right.Kind):
+		if floatRanks[left.Kind] >= floatRanks[right.Kind] {
+			return Type{Kind: left.Kind}
+		}
+		return Type{Kind: right.Kind}
+	// Mixed numbers follow the runtime: a float may be divided by an integer
+	// and either may be taken modulo the other, giving the float's type, and
+	// the other arithmetic operators need a cast.
+	case isFloat(left.Kind) && isInteger(right.Kind):
+		switch expr.Operator {
+		case "/", "//", "%":
+			return Type{Kind: left.Kind}
+		case "+", "-", "*", "**":
+			c.report(expr.Pos(), "Cannot use %s and %s together with %s, cast one of them first", left, right, expr.Operator)
+		}
+	case isInteger(left.Kind) && isFloat(right.Kind):
+		switch expr.Operator {
+		case "%":
+			return Type{Kind: right.Kind}
+		case "+", "-", "*", "/", "//", "**":
+			c.report(expr.Pos(), "Cannot use %s and %s together with %s, cast one of them first", left, right, expr.Operator)
+		}
+	}
+
+	return anyType
+}
+
+func (c *Checker) member(expr *ast.MemberExpression, s *scope) Type {
+	object := c.expr(expr.Object, s)
+
+	if expr.Computed {
+		c.expr(expr.Property, s)
+		return anyType
+	}
+
+	property, ok := expr.Property.(*ast.Identifier)
+	if !ok {
+		return anyType
+	}
+
+	if object.Fields != nil {
+		if t, ok := object.Fields[property.Symbol]; ok {
+			return t
+		}
+	}
+	if object.Object != nil {
+		for _, field := range object.Object.Fields {
+			if field.Name == property.Symbol {
+				return c.annotation(field.Type, s)
+			}
+		}
+	}
+
+	return anyType
+}
+
+func (c *Checker) call(expr *ast.CallExpression, s *scope) Type {
+	callee := c.expr(expr.Caller, s)
+
+	args := make([]Type, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i] = c.expr(arg, s)
+	}
+	named := make([]Type, len(expr.NamedArgs))
+	for i, arg := range expr.NamedArgs {
+		named[i] = c.expr(arg.Value, s)
+	}
+
+	sig := callee.Signature
+	if sig == nil {
+		switch callee.Kind {
+		case ast.AnyType, ast.FunctionType, ast.ObjectType, "":
+		default:
+			c.report(expr.Pos(), "Cannot call a value of type %s", callee)
+		}
+		return anyType
+	}
+
+	if message := sig.arityError(len(args) + len(named)); message != "" {
+		c.report(expr.Pos(), "%s", message)
+	}
+
+	for i, arg := range args {
+		var param ast.Parameter
+		switch {
+		case i < len(sig.Parameters):
+			param = sig.Parameters[i]
+		case len(sig.Parameters) > 0 && sig.Parameters[len(sig.Parameters)-1].IsRest:
+			param = sig.Parameters[len(sig.Parameters)-1]
+		default:
+			continue
+		}
+		c.assign(expr.Args[i].Pos(), arg, c.parameterType(param, s), "argument "+param.Name, false, s)
+	}
+
+	for i, arg := range expr.NamedArgs {
+		index := slices.IndexFunc(sig.Parameters, func(param ast.Parameter) bool {
+			return param.Name == arg.Name
+		})
+		switch {
+		case index < 0:
+			c.report(arg.Pos(), "%s has no parameter named %s", sig.displayName(), arg.Name)
+		case sig.Parameters[index].IsRest:
+			c.report(arg.Pos(), "Rest parameter %s of %s cannot be passed by name", arg.Name, sig.displayName())
+		case index < len(args):
+			c.report(arg.Pos(), "%s was given argument %s both by position and by name", sig.displayName(), arg.Name)
+		default:
+			c.assign(arg.Value.Pos(), named[i], c.parameterType(sig.Parameters[index], s), "argument "+arg.Name, false, s)
+		}
+	}
+	for i, param := range sig.Parameters {
+		if len(named) == 0 || i < len(args) || param.IsRest || param.Default != nil {
+			continue
+		}
+		if !slices.ContainsFunc(expr.NamedArgs, func(arg ast.NamedArgument) bool { return arg.Name == param.Name }) {
+			c.report(expr.Pos(), "%s is missing argument %s", sig.displayName(), param.Name)
+		}
+	}
+
+	return sig.Result
+}
+
+func (c *Checker) assignment(expr *ast.AssignmentExpression, s *scope) Type {
+	value := c.expr(expr.Value, s)
+
+	switch assignee := expr.Assignee.(type) {
+	case *ast.Identifier:
+		sym, ok := s.lookup(assignee.Symbol)
+		if !ok {
+			c.undeclared(assignee.Pos(), assignee.Symbol, s)
+			return value
+		}
+		if sym.constant {
+			c.report(assignee.Pos(), "Variable %s is constant. Cannot reassign a constant.", assignee.Symbol)
+			return value
+		}
+		c.assign(expr.Value.Pos(), value, sym.declared, assignee.Symbol, sym.declared.Name == "" && sym.declared.Object == nil, s)
+		if sym.declared.isAny() && sym.declared.Object == nil && !sameType(sym.typ, value) {
+			sym.typ = anyType
+		}
+	case *ast.MemberExpression:
+		c.expr(assignee.Object, s)
+		if assignee.Computed {
+			c.expr(assignee.Property, s)
+		}
+	default:
+		c.expr(assignee, s)
+	}
+
+	return value
+}
+
+func sameType(a, b Type) bool {
+	return a.Kind == b.Kind && a.Name == b.Name && a.Object == b.Object && a.Fields == nil && b.Fields == nil && a.Signature == b.Signature
+}
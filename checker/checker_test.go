@@ -0,0 +1,109 @@
+package checker_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/checker"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+// check type-checks source with println and int8 declared and returns the messages
+// of its diagnostics.
+func check(t *testing.T, source string) []string {
+	t.Helper()
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics("test.jam", source)
+	if len(diagnostics) > 0 {
+		t.Fatalf("syntax errors: %v", diagnostics)
+	}
+
+	var messages []string
+	for _, diagnostic := range checker.NewChecker([]string{"println", "int8"}).Check(program) {
+		messages = append(messages, diagnostic.Message)
+	}
+	return messages
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"float divided by integer", "let x: f32 = 7.5 / 2", nil},
+		{"float modulo integer", "let x: f32 = 7.5 % 2", nil},
+		{"integer modulo float", "let x: f32 = 7 % 2.5", nil},
+		{"integer plus float", "let x = 2 + 7.5", []string{"Cannot use i32 and f32 together with +, cast one of them first"}},
+		{"integer divided by float", "let x = 2 / 7.5", []string{"Cannot use i32 and f32 together with /, cast one of them first"}},
+		{"float quotient is a float", "let x: i32 = 7.5 / 2", []string{"Expected i32 for x, got f32"}},
+		{"integers widen", "let x: i64 = int8(1) + 2", nil},
+		{"literal narrows in declaration", "let x: i8 = 3", nil},
+		{"literal narrows in argument", "fn g(a: i8) {}\ng(3)", nil},
+		{"assignment only widens", "let x: i8 = 3\nx = 4", []string{"Expected i8 for x, got i32"}},
+		{"string annotation", `let s: string = 1`, []string{"Expected string for s, got i32"}},
+		{"undeclared", "println(y)", []string{"y is not declared"}},
+		{"arity", "fn f(a, b) {}\nf(1)", []string{"f expects 2 arguments, got 1"}},
+		{"return type", "fn f(): string { return 1 }", []string{"Expected string for return value of f, got i32"}},
+		{"named arguments", "fn f(a, b = 2) {}\nf(b: 3, a: 1)", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := check(t, test.source); !slices.Equal(got, test.want) {
+				t.Errorf("diagnostics = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckImport(t *testing.T) {
+	files := map[string]string{
+		"lib.jam": `
+			fn helper() { return 1 }
+			class box {}
+			type Pair = { a: i32 }
+			type pair = { a: i32 }
+			let Max = 3
+			let hidden = 1`,
+	}
+	importer := func(path string) (ast.Program, error) {
+		source, ok := files[path]
+		if !ok {
+			data, err := os.ReadFile("../" + path)
+			if err != nil {
+				return ast.Program{}, err
+			}
+			source = string(data)
+		}
+		diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics(path, source)
+		if len(diagnostics) > 0 {
+			return ast.Program{}, fmt.Errorf("cannot import %s: %s", path, diagnostics[0])
+		}
+		return program, nil
+	}
+
+	source := `import "std/algorithm.jam";
+import "lib.jam";
+let a = sort([3, 1, 2])
+let b = helper() + Max
+let c = box()
+let p: Pair = { a: 1 }
+println(hidden)`
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics("test.jam", source)
+	if len(diagnostics) > 0 {
+		t.Fatalf("syntax errors: %v", diagnostics)
+	}
+	typeChecker := checker.NewChecker([]string{"println"})
+	typeChecker.Importer = importer
+
+	var got []string
+	for _, diagnostic := range typeChecker.Check(program) {
+		got = append(got, diagnostic.Message)
+	}
+	if want := []string{"hidden is not declared"}; !slices.Equal(got, want) {
+		t.Errorf("diagnostics = %q, want %q", got, want)
+	}
+}
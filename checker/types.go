@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Jamlie/Jamlang/ast"
+)
+
+// Type is what the checker knows about a value. Kind is AnyType when the
+// type cannot be inferred without running the program.
+type Type struct {
+	Kind ast.VariableType
+	// Name is the type alias or class name the type was written as.
+	Name string
+	// Object holds the fields of an object type alias or annotation.
+	Object *ast.ObjectTypeLiteral
+	// Fields holds the inferred fields of an object literal.
+	Fields map[string]Type
+	// Signature is set for functions and classes with a known declaration.
+	Signature *Signature
+}
+
+// Signature describes a callable declared in the checked program. Classes
+// have Constructs set and produce an instance of Result when called.
+type Signature struct {
+	Name       string
+	Parameters []ast.Parameter
+	Result     Type
+	Constructs bool
+}
+
+var anyType = Type{Kind: ast.AnyType}
+
+func (t Type) String() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if t.Object != nil {
+		return t.Object.ToString()
+	}
+	if t.Fields != nil {
+		names := make([]string, 0, len(t.Fields))
+		for name := range t.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		s := "{ "
+		for i, name := range names {
+			if i > 0 {
+				s += ", "
+			}
+			s += name + ": " + t.Fields[name].String()
+		}
+		return s + " }"
+	}
+	return string(t.Kind)
+}
+
+func (t Type) isAny() bool {
+	return t.Kind == ast.AnyType || t.Kind == ""
+}
+
+var integerRanks = map[ast.VariableType]int{
+	ast.Int8Type:  1,
+	ast.Int16Type: 2,
+	ast.Int32Type: 3,
+	ast.Int64Type: 4,
+}
+
+var floatRanks = map[ast.VariableType]int{
+	ast.Float32Type: 1,
+	ast.Float64Type: 2,
+}
+
+func isInteger(kind ast.VariableType) bool {
+	_, ok := integerRanks[kind]
+	return ok
+}
+
+func isFloat(kind ast.VariableType) bool {
+	_, ok := floatRanks[kind]
+	return ok
+}
+
+// displayName is the name of sig for messages.
+func (sig *Signature) displayName() string {
+	if sig.Name == "" {
+		return "<anonymous>"
+	}
+	return sig.Name
+}
+
+// arity returns how many arguments sig requires and accepts; max is -1 when
+// the signature has a rest parameter.
+func (sig *Signature) arity() (required, max int) {
+	max = len(sig.Parameters)
+	for _, param := range sig.Parameters {
+		if param.IsRest {
+			return required, -1
+		}
+		if param.Default == nil {
+			required++
+		}
+	}
+	return required, max
+}
+
+func (sig *Signature) arityError(argc int) string {
+	required, max := sig.arity()
+	name := sig.displayName()
+
+	switch {
+	case argc < required && required == max:
+		return fmt.Sprintf("%s expects %d arguments, got %d", name, required, argc)
+	case argc < required:
+		return fmt.Sprintf("%s expects at least %d arguments, got %d", name, required, argc)
+	case max >= 0 && argc > max && required == max:
+		return fmt.Sprintf("%s expects %d arguments, got %d", name, max, argc)
+	case max >= 0 && argc > max:
+		return fmt.Sprintf("%s expects at most %d arguments, got %d", name, max, argc)
+	}
+	return ""
+}
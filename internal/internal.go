@@ -1,7 +0,0 @@
-package internal
-
-var LineCount int = 0
-
-func Line() int {
-	return LineCount / 2
-}
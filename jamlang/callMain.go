@@ -2,24 +2,34 @@ package jamlang
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/checker"
 	"github.com/Jamlie/Jamlang/parser"
 	"github.com/Jamlie/Jamlang/runtimelang"
 )
 
 func Repl(env *runtimelang.Environment) {
+	repl(env, false)
+}
+
+func repl(env *runtimelang.Environment, useVM bool) {
 	parser := parser.NewParser()
 	fmt.Println("Repl mode. Type 'exit' to exit.")
+	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Print("> ")
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
+		if !scanner.Scan() {
+			break
+		}
 		text := scanner.Text()
 		if text == "exit" {
 			break
@@ -28,11 +38,19 @@ func Repl(env *runtimelang.Environment) {
 			continue
 		}
 
-		program := parser.ProduceAST(text)
-		runtimeValue, err := runtimelang.Evaluate(&program, *env)
+		diagnostics, program := parser.ProduceFileASTWithDiagnostics("<repl>", text)
+		if len(diagnostics) > 0 {
+			printDiagnostics(stderr(env), diagnostics)
+			continue
+		}
+		runtimeValue, err := runProgram(program, env, useVM)
+		var exitErr *runtimelang.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(0)
+			fmt.Fprintln(stderr(env), err)
+			continue
 		}
 		fmt.Println(runtimeValue.Get())
 	}
@@ -42,10 +60,20 @@ func CallMain(env *runtimelang.Environment) {
 	runFlag := flag.Bool("r", false, "Run a file")
 	helpFlag := flag.Bool("h", false, "Help")
 	installFlag := flag.Bool("i", false, "Install a package")
+	vmFlag := flag.Bool("vm", false, "Run with the bytecode compiler and VM")
+	var fsFlag pathsFlag
+	flag.Var(&fsFlag, "allow-fs", "Allow reading and writing files, in the given comma-separated directories if any")
+	netFlag := flag.Bool("allow-net", false, "Allow HTTP requests and servers")
+	allFlag := flag.Bool("allow-all", false, "Allow everything")
+	denyImportFlag := flag.Bool("deny-import", false, "Deny import statements")
+	denyEvalFlag := flag.Bool("deny-eval", false, "Deny eval")
 	flag.Parse()
 	args := flag.Args()
-	if flag.NFlag() == 0 && len(args) == 0 {
-		Repl(env)
+	if engine := env.Engine(); engine != nil {
+		engine.Permissions = cliPermissions(fsFlag, *netFlag, *allFlag, *denyImportFlag, *denyEvalFlag)
+	}
+	if len(args) == 0 && !*runFlag && !*helpFlag && !*installFlag {
+		repl(env, *vmFlag)
 	} else {
 		if *runFlag {
 			if len(args) < 1 {
@@ -64,11 +92,14 @@ func CallMain(env *runtimelang.Environment) {
 				os.Exit(0)
 			}
 
-			program := parser.NewParser().ProduceAST(string(data))
-			_, err = runtimelang.Evaluate(&program, *env)
+			diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics(args[0], string(data))
+			if len(diagnostics) > 0 {
+				printDiagnostics(stderr(env), diagnostics)
+				os.Exit(1)
+			}
+			_, err = runProgram(program, env, *vmFlag)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(0)
+				exitWithError(env, err)
 			}
 		} else if *helpFlag {
 			fmt.Println("Usage: jamlang [options] [file]")
@@ -76,7 +107,14 @@ func CallMain(env *runtimelang.Environment) {
 			fmt.Println("  -r\t\tRun a file")
 			fmt.Println("  -h\t\tShow this help message")
 			fmt.Println("  -i\t\tInstall a package")
+			fmt.Println("  -vm\t\tRun with the bytecode compiler and VM")
+			fmt.Println("  --allow-fs[=dirs]\tAllow reading and writing files, only in dirs if given")
+			fmt.Println("  --allow-net\tAllow HTTP requests and servers")
+			fmt.Println("  --allow-all\tAllow everything")
+			fmt.Println("  --deny-import\tDeny import statements")
+			fmt.Println("  --deny-eval\tDeny eval")
 			fmt.Println("  run\t\tRun a file")
+			fmt.Println("  check\t\tType-check files without running them")
 			fmt.Println("  help\t\tShow this help message")
 			fmt.Println("  install\tInstall a package")
 		} else if *installFlag {
@@ -121,20 +159,39 @@ func CallMain(env *runtimelang.Environment) {
 				}
 
 				parser := parser.NewParser()
-				program := parser.ProduceAST(string(data))
+				diagnostics, program := parser.ProduceFileASTWithDiagnostics(args[1], string(data))
+				if len(diagnostics) > 0 {
+					printDiagnostics(stderr(env), diagnostics)
+					os.Exit(1)
+				}
 
-				_, err = runtimelang.Evaluate(&program, *env)
+				_, err = runProgram(program, env, *vmFlag)
 				if err != nil {
-					fmt.Println(err)
+					exitWithError(env, err)
+				}
+			} else if option == "check" {
+				if len(args) < 2 {
+					fmt.Println("No file specified")
 					os.Exit(0)
 				}
+
+				if !checkFiles(args[1:], env) {
+					os.Exit(1)
+				}
 			} else if args[0] == "help" {
 				fmt.Println("Usage: jamlang [options] [file]")
 				fmt.Println("Options:")
 				fmt.Println("  -r\t\tRun a file")
 				fmt.Println("  -h\t\tShow this help message")
 				fmt.Println("  -i\t\tInstall a library")
+				fmt.Println("  -vm\t\tRun with the bytecode compiler and VM")
+				fmt.Println("  --allow-fs[=dirs]\tAllow reading and writing files, only in dirs if given")
+				fmt.Println("  --allow-net\tAllow HTTP requests and servers")
+				fmt.Println("  --allow-all\tAllow everything")
+				fmt.Println("  --deny-import\tDeny import statements")
+				fmt.Println("  --deny-eval\tDeny eval")
 				fmt.Println("  run\t\tRun a file")
+				fmt.Println("  check\t\tType-check files without running them")
 				fmt.Println("  help\t\tShow this help message")
 				fmt.Println("  install\tInstall a library")
 			} else if args[0] == "install" {
@@ -163,6 +220,8 @@ func CallMain(env *runtimelang.Environment) {
 			} else {
 				fmt.Println("Unknown option")
 				fmt.Println("Usage: jamlang [run] [file]")
+				fmt.Println("Usage: jamlang -vm run [file]")
+				fmt.Println("Usage: jamlang check [files...]")
 				fmt.Println("Usage: jamlang -r [file]")
 				fmt.Println("Usage: jamlang -i [library]")
 				fmt.Println("Usage: jamlang install [library]")
@@ -174,6 +233,133 @@ func CallMain(env *runtimelang.Environment) {
 	}
 }
 
+// pathsFlag is a flag that may be given alone or with a comma-separated list
+// of paths, as in --allow-fs or --allow-fs=./data,./out.
+type pathsFlag struct {
+	set   bool
+	paths []string
+}
+
+func (f *pathsFlag) String() string {
+	return strings.Join(f.paths, ",")
+}
+
+func (f *pathsFlag) Set(value string) error {
+	f.set = true
+	if value != "true" {
+		f.paths = append(f.paths, strings.Split(value, ",")...)
+	}
+	return nil
+}
+
+func (f *pathsFlag) IsBoolFlag() bool {
+	return true
+}
+
+// cliPermissions returns the permissions scripts run from the command line
+// get: import, eval and exit unless denied, plus files and the network when
+// the flags allow them. Limiting files to some directories limits imports to
+// them too.
+func cliPermissions(fs pathsFlag, net, all, denyImport, denyEval bool) runtimelang.Permissions {
+	permissions := runtimelang.Permissions{
+		Granted: []runtimelang.Capability{runtimelang.Import, runtimelang.Eval, runtimelang.ProcessExit},
+	}
+	if all {
+		permissions = runtimelang.AllPermissions()
+	}
+	if fs.set {
+		permissions.Granted = append(permissions.Granted, runtimelang.FSRead, runtimelang.FSWrite)
+		permissions.Paths = fs.paths
+	}
+	if net {
+		permissions.Granted = append(permissions.Granted, runtimelang.NetClient, runtimelang.NetServer)
+	}
+	permissions.Granted = slices.DeleteFunc(permissions.Granted, func(capability runtimelang.Capability) bool {
+		return denyImport && capability == runtimelang.Import || denyEval && capability == runtimelang.Eval
+	})
+	return permissions
+}
+
+// stderr returns where errors from running code in env are printed.
+func stderr(env *runtimelang.Environment) io.Writer {
+	if engine := env.Engine(); engine != nil && engine.Stderr != nil {
+		return engine.Stderr
+	}
+	return os.Stderr
+}
+
+// exitWithError ends the process after running a program failed with err:
+// with the status the script passed to exit, or else with 1 after printing
+// err.
+func exitWithError(env *runtimelang.Environment, err error) {
+	var exitErr *runtimelang.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+	fmt.Fprintln(stderr(env), err)
+	os.Exit(1)
+}
+
+// runProgram runs program in env with the tree-walking evaluator, or with the
+// bytecode VM when useVM is set.
+func runProgram(program ast.Program, env *runtimelang.Environment, useVM bool) (runtimelang.RuntimeValue, error) {
+	if !useVM {
+		return runtimelang.Evaluate(&program, env)
+	}
+
+	chunk, err := runtimelang.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+	return runtimelang.Execute(chunk, env)
+}
+
+// checkFiles parses and type-checks each file without running it, printing
+// any diagnostics. It reports whether every file passed.
+func checkFiles(files []string, env *runtimelang.Environment) bool {
+	ok := true
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(stderr(env), err)
+			ok = false
+			continue
+		}
+
+		diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics(file, string(data))
+		if len(diagnostics) == 0 {
+			typeChecker := checker.NewChecker(env.Names())
+			typeChecker.Importer = loadProgram
+			diagnostics = typeChecker.Check(program)
+		}
+
+		if len(diagnostics) > 0 {
+			printDiagnostics(stderr(env), diagnostics)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func loadProgram(path string) (ast.Program, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ast.Program{}, err
+	}
+
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics(path, string(data))
+	if len(diagnostics) > 0 {
+		return ast.Program{}, fmt.Errorf("cannot import %s: %s", path, diagnostics[0])
+	}
+	return program, nil
+}
+
+func printDiagnostics(w io.Writer, diagnostics []parser.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(w, diagnostic)
+	}
+}
+
 func getLibrary(name string) {
 	link := fmt.Sprintf("https://raw.githubusercontent.com/Jamlie/Jamlang/main/std/%s.jam", name)
 	resp, err := http.Get(link)
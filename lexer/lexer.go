@@ -1,18 +1,22 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/Jamlie/Jamlang/internal"
 	"github.com/Jamlie/Jamlang/tokentype"
 )
 
 type Token struct {
-	Type  tokentype.TokenType
-	Value string
+	Type   tokentype.TokenType
+	Value  string
+	File   string
+	Line   int
+	Column int
 }
 
 var Keywords map[string]tokentype.TokenType = map[string]tokentype.TokenType{
@@ -35,17 +39,59 @@ var Keywords map[string]tokentype.TokenType = map[string]tokentype.TokenType{
 	"or":      tokentype.LogicalOperator,
 	"import":  tokentype.Import,
 	"class":   tokentype.Class,
+	"extends": tokentype.Extends,
+	"type":    tokentype.Type,
+	"throw":   tokentype.Throw,
+	"try":     tokentype.Try,
+	"catch":   tokentype.Catch,
+	"finally": tokentype.Finally,
 }
 
-func createToken(value string, tokenType tokentype.TokenType) Token {
+func createToken(value string, tokenType tokentype.TokenType, pos position) Token {
 	return Token{
-		Type:  tokenType,
-		Value: value,
+		Type:   tokenType,
+		Value:  value,
+		File:   pos.file,
+		Line:   pos.line,
+		Column: pos.column,
 	}
 }
 
+type position struct {
+	file   string
+	line   int
+	column int
+}
+
+func (p position) String() string {
+	if p.file == "" {
+		return fmt.Sprintf("line %d, column %d", p.line, p.column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.file, p.line, p.column)
+}
+
+// positions returns the position of every character in src, plus one past the
+// end for the EndOfFile token.
+func positions(file string, src []string) []position {
+	result := make([]position, 0, len(src)+1)
+	line, column := 1, 1
+	for _, char := range src {
+		result = append(result, position{file, line, column})
+		if char == "\n" {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return append(result, position{file, line, column})
+}
+
+// isAlpha reports whether src, a single character, can be part of an
+// identifier: a Unicode letter, digit or combining mark, or an underscore.
 func isAlpha(src string) bool {
-	return strings.ContainsAny(src, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890_")
+	r, _ := utf8.DecodeRuneInString(src)
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
 }
 
 func isInt(src string) bool {
@@ -62,159 +108,259 @@ func isWhitespace(src string) bool {
 	return src == " " || src == "\t" || src == "\n" || src == "\r"
 }
 
-func Tokenize(sourceCode string) []Token {
+// Tokenize returns the tokens in sourceCode, failing with every error Scan
+// finds joined together if there are any. Use Scan to get each error and the
+// tokens around them.
+func Tokenize(sourceCode string) ([]Token, error) {
+	return TokenizeFile("", sourceCode)
+}
+
+// TokenizeFile is Tokenize, recording fileName on every token.
+func TokenizeFile(fileName string, sourceCode string) ([]Token, error) {
+	tokens, scanErrors := Scan(fileName, sourceCode)
+	if len(scanErrors) > 0 {
+		errs := make([]error, len(scanErrors))
+		for i, err := range scanErrors {
+			errs[i] = err
+		}
+		return nil, errors.Join(errs...)
+	}
+	return tokens, nil
+}
+
+// Error is a problem found while scanning, such as an unterminated string.
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("Error at %s: %s", position{e.File, e.Line, e.Column}, e.Message)
+}
+
+// Scan tokenizes sourceCode, collecting every invalid character or
+// unterminated string. fileName is recorded on every token.
+func Scan(fileName string, sourceCode string) ([]Token, []Error) {
 	tokens := []Token{}
+	errors := []Error{}
 	src := strings.Split(sourceCode, "")
+	srcPositions := positions(fileName, src)
 
 	for len(src) > 0 {
+		pos := srcPositions[len(srcPositions)-1-len(src)]
+		next := ""
+		if len(src) > 1 {
+			next = src[1]
+		}
 		if src[0] == "(" {
-			tokens = append(tokens, createToken(src[0], tokentype.OpenParen))
+			tokens = append(tokens, createToken(src[0], tokentype.OpenParen, pos))
 			src = src[1:]
 		} else if src[0] == ")" {
-			tokens = append(tokens, createToken(src[0], tokentype.CloseParen))
+			tokens = append(tokens, createToken(src[0], tokentype.CloseParen, pos))
 			src = src[1:]
 		} else if src[0] == "{" {
-			tokens = append(tokens, createToken(src[0], tokentype.LSquirly))
+			tokens = append(tokens, createToken(src[0], tokentype.LSquirly, pos))
 			src = src[1:]
 		} else if src[0] == "}" {
-			tokens = append(tokens, createToken(src[0], tokentype.RSquirly))
+			tokens = append(tokens, createToken(src[0], tokentype.RSquirly, pos))
 			src = src[1:]
 		} else if src[0] == "[" {
-			tokens = append(tokens, createToken(src[0], tokentype.OpenBracket))
+			tokens = append(tokens, createToken(src[0], tokentype.OpenBracket, pos))
 			src = src[1:]
 		} else if src[0] == "]" {
-			tokens = append(tokens, createToken(src[0], tokentype.CloseBracket))
+			tokens = append(tokens, createToken(src[0], tokentype.CloseBracket, pos))
 			src = src[1:]
 		} else if src[0] == "+" || src[0] == "-" || src[0] == "*" || src[0] == "/" || src[0] == "%" || src[0] == "&" || src[0] == "|" || src[0] == "^" {
-			if (src[0] == "-" && isInt(src[1])) || (src[0] == "-" && isFloat(src[1])) || (src[0] == "-" && isAlpha(src[1])) {
-				tokens = append(tokens, createToken(src[0], tokentype.UnaryOperator))
+			if (src[0] == "-" && isInt(next)) || (src[0] == "-" && isFloat(next)) || (src[0] == "-" && isAlpha(next)) {
+				tokens = append(tokens, createToken(src[0], tokentype.UnaryOperator, pos))
 				src = src[1:]
 				continue
 			}
-			if (src[0] == "+" && isInt(src[1])) || (src[0] == "+" && isFloat(src[1])) || (src[0] == "+" && isAlpha(src[1])) {
-				tokens = append(tokens, createToken(src[0], tokentype.UnaryOperator))
+			if (src[0] == "+" && isInt(next)) || (src[0] == "+" && isFloat(next)) || (src[0] == "+" && isAlpha(next)) {
+				tokens = append(tokens, createToken(src[0], tokentype.UnaryOperator, pos))
 				src = src[1:]
 				continue
 			}
-			if src[0] == "+" && src[1] == "+" {
-				tokens = append(tokens, createToken("++", tokentype.UnaryOperator))
+			if src[0] == "+" && next == "+" {
+				tokens = append(tokens, createToken("++", tokentype.UnaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			if src[0] == "-" && src[1] == "-" {
-				tokens = append(tokens, createToken("--", tokentype.UnaryOperator))
+			if src[0] == "-" && next == "-" {
+				tokens = append(tokens, createToken("--", tokentype.UnaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			if src[0] == "*" && src[1] == "*" {
-				tokens = append(tokens, createToken("**", tokentype.BinaryOperator))
+			if src[0] == "*" && next == "*" {
+				tokens = append(tokens, createToken("**", tokentype.BinaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			if src[0] == "/" && src[1] == "*" {
-				tokens = append(tokens, createToken("/*", tokentype.OpenComment))
+			if src[0] == "/" && next == "*" {
+				tokens = append(tokens, createToken("/*", tokentype.OpenComment, pos))
 				src = src[2:]
 				continue
 			}
-			if src[0] == "*" && src[1] == "/" {
-				tokens = append(tokens, createToken("*/", tokentype.CloseComment))
+			if src[0] == "*" && next == "/" {
+				tokens = append(tokens, createToken("*/", tokentype.CloseComment, pos))
 				src = src[2:]
 				continue
 			}
-			if src[0] == "/" && src[1] == "/" {
-				tokens = append(tokens, createToken("//", tokentype.BinaryOperator))
+			if src[0] == "/" && next == "/" {
+				tokens = append(tokens, createToken("//", tokentype.BinaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			tokens = append(tokens, createToken(src[0], tokentype.BinaryOperator))
+			tokens = append(tokens, createToken(src[0], tokentype.BinaryOperator, pos))
 			src = src[1:]
 		} else if src[0] == "=" {
-			tokens = append(tokens, createToken(src[0], tokentype.Equals))
+			tokens = append(tokens, createToken(src[0], tokentype.Equals, pos))
 			src = src[1:]
 		} else if src[0] == ">" {
-			if src[1] == ">" {
-				tokens = append(tokens, createToken(">>", tokentype.BinaryOperator))
+			if next == ">" {
+				tokens = append(tokens, createToken(">>", tokentype.BinaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			tokens = append(tokens, createToken(src[0], tokentype.ComparisonOperator))
+			tokens = append(tokens, createToken(src[0], tokentype.ComparisonOperator, pos))
 			src = src[1:]
 		} else if src[0] == "<" {
-			if src[1] == "<" {
-				tokens = append(tokens, createToken("<<", tokentype.BinaryOperator))
+			if next == "<" {
+				tokens = append(tokens, createToken("<<", tokentype.BinaryOperator, pos))
 				src = src[2:]
 				continue
 			}
-			tokens = append(tokens, createToken(src[0], tokentype.ComparisonOperator))
+			tokens = append(tokens, createToken(src[0], tokentype.ComparisonOperator, pos))
 			src = src[1:]
-		} else if src[0] == ">" && src[1] == "=" {
-			tokens = append(tokens, createToken(">=", tokentype.ComparisonOperator))
+		} else if src[0] == ">" && next == "=" {
+			tokens = append(tokens, createToken(">=", tokentype.ComparisonOperator, pos))
 			src = src[2:]
-		} else if src[0] == "<" && src[1] == "=" {
-			tokens = append(tokens, createToken("<=", tokentype.ComparisonOperator))
+		} else if src[0] == "<" && next == "=" {
+			tokens = append(tokens, createToken("<=", tokentype.ComparisonOperator, pos))
 			src = src[2:]
-		} else if src[0] == "=" && src[1] == "=" {
-			tokens = append(tokens, createToken("==", tokentype.ComparisonOperator))
+		} else if src[0] == "=" && next == "=" {
+			tokens = append(tokens, createToken("==", tokentype.ComparisonOperator, pos))
 			src = src[2:]
-		} else if src[0] == "!" && src[1] == "=" {
-			tokens = append(tokens, createToken("!=", tokentype.ComparisonOperator))
+		} else if src[0] == "!" && next == "=" {
+			tokens = append(tokens, createToken("!=", tokentype.ComparisonOperator, pos))
 			src = src[2:]
 		} else if src[0] == ";" {
-			tokens = append(tokens, createToken(src[0], tokentype.SemiColon))
+			tokens = append(tokens, createToken(src[0], tokentype.SemiColon, pos))
 			src = src[1:]
 		} else if src[0] == "," {
-			tokens = append(tokens, createToken(src[0], tokentype.Comma))
+			tokens = append(tokens, createToken(src[0], tokentype.Comma, pos))
 			src = src[1:]
+		} else if src[0] == "." && len(src) > 2 && next == "." && src[2] == "." {
+			tokens = append(tokens, createToken("...", tokentype.Ellipsis, pos))
+			src = src[3:]
 		} else if src[0] == "." {
-			tokens = append(tokens, createToken(src[0], tokentype.Dot))
+			tokens = append(tokens, createToken(src[0], tokentype.Dot, pos))
 			src = src[1:]
 		} else if src[0] == ":" {
-			if src[1] == ":" {
-				tokens = append(tokens, createToken("::", tokentype.ColonColon))
+			if next == ":" {
+				tokens = append(tokens, createToken("::", tokentype.ColonColon, pos))
 				src = src[2:]
 				continue
 			}
-			tokens = append(tokens, createToken(src[0], tokentype.Colon))
+			tokens = append(tokens, createToken(src[0], tokentype.Colon, pos))
 			src = src[1:]
-		} else if src[0] == "\"" || src[0] == "`" || src[0] == "'" {
-			quotationOrBacktick := src[0]
+		} else if src[0] == "\"" || src[0] == "'" {
+			quote := src[0]
 			src = src[1:]
 			str := ""
-			if quotationOrBacktick == "'" {
-				for len(src) > 0 && src[0] != "'" {
-					if src[0] == "\n" || src[0] == "\r" {
-						internal.LineCount++
+			for len(src) > 0 && src[0] != quote {
+				if src[0] == "\\" {
+					decoded, n, message := escape(src)
+					if message != "" {
+						escapePos := srcPositions[len(srcPositions)-1-len(src)]
+						errors = append(errors, Error{escapePos.file, escapePos.line, escapePos.column, message})
 					}
-					str += src[0]
-					src = src[1:]
+					str += decoded
+					src = src[n:]
+					continue
 				}
-			} else if quotationOrBacktick == "`" {
-				for len(src) > 0 && src[0] != "`" {
-					if src[0] == "\n" || src[0] == "\r" {
-						internal.LineCount++
+				str += src[0]
+				src = src[1:]
+			}
+
+			if len(src) == 0 {
+				errors = append(errors, Error{pos.file, pos.line, pos.column, "Unterminated string"})
+				continue
+			}
+
+			tokens = append(tokens, createToken(str, tokentype.String, pos))
+			src = src[1:]
+		} else if src[0] == "`" {
+			src = src[1:]
+			str := ""
+			strPos := pos
+			isTemplate := false
+			unterminated := Error{pos.file, pos.line, pos.column, "Unterminated string"}
+			for len(src) > 0 && src[0] != "`" {
+				here := srcPositions[len(srcPositions)-1-len(src)]
+				if src[0] == "\\" {
+					decoded, n, message := escape(src)
+					if message != "" {
+						errors = append(errors, Error{here.file, here.line, here.column, message})
 					}
-					str += src[0]
-					src = src[1:]
+					str += decoded
+					src = src[n:]
+					continue
 				}
-			} else {
-				for len(src) > 0 && src[0] != "\"" {
-					if src[0] == "\n" || src[0] == "\r" {
-						internal.LineCount++
-					}
+				if src[0] != "$" || len(src) < 2 || src[1] != "{" {
 					str += src[0]
 					src = src[1:]
+					continue
+				}
+
+				end := closingBrace(src[2:])
+				if end < 0 {
+					unterminated = Error{here.file, here.line, here.column, "Unterminated template expression"}
+					src = nil
+					break
+				}
+
+				if isTemplate {
+					tokens = append(tokens, createToken(str, tokentype.TemplateMiddle, strPos))
+				} else {
+					tokens = append(tokens, createToken(str, tokentype.TemplateHead, strPos))
+				}
+				isTemplate = true
+
+				start := srcPositions[len(srcPositions)-len(src)+1]
+				exprTokens, exprErrors := Scan(fileName, strings.Join(src[2:2+end], ""))
+				exprTokens = exprTokens[:len(exprTokens)-1]
+				for _, token := range exprTokens {
+					token.Line, token.Column = offset(start, token.Line, token.Column)
+					tokens = append(tokens, token)
+				}
+				for _, err := range exprErrors {
+					err.Line, err.Column = offset(start, err.Line, err.Column)
+					errors = append(errors, err)
+				}
+
+				src = src[2+end+1:]
+				str = ""
+				if len(src) > 0 {
+					strPos = srcPositions[len(srcPositions)-1-len(src)]
 				}
 			}
 
 			if len(src) == 0 {
-				fmt.Fprintf(os.Stderr, "Error on line %d: Unterminated string", internal.Line())
-				os.Exit(0)
+				errors = append(errors, unterminated)
+				continue
 			}
 
-			tokens = append(tokens, createToken(str, tokentype.String))
+			if isTemplate {
+				tokens = append(tokens, createToken(str, tokentype.TemplateTail, strPos))
+			} else {
+				tokens = append(tokens, createToken(str, tokentype.String, pos))
+			}
 			src = src[1:]
 		} else {
-			if isInt(src[0]) || (src[0] == "-" && isInt(src[1])) {
+			if isInt(src[0]) || (src[0] == "-" && isInt(next)) {
 				num := ""
 				isFloatNum := false
 
@@ -232,9 +378,9 @@ func Tokenize(sourceCode string) []Token {
 				}
 
 				if isFloatNum {
-					tokens = append(tokens, createToken(num, tokentype.Float))
+					tokens = append(tokens, createToken(num, tokentype.Float, pos))
 				} else {
-					tokens = append(tokens, createToken(num, tokentype.Integer))
+					tokens = append(tokens, createToken(num, tokentype.Integer, pos))
 				}
 			} else if isAlpha(src[0]) {
 				identifier := ""
@@ -245,22 +391,112 @@ func Tokenize(sourceCode string) []Token {
 
 				reserved, ok := Keywords[identifier]
 				if ok {
-					tokens = append(tokens, createToken(identifier, reserved))
+					tokens = append(tokens, createToken(identifier, reserved, pos))
 				} else {
-					tokens = append(tokens, createToken(identifier, tokentype.Identifier))
+					tokens = append(tokens, createToken(identifier, tokentype.Identifier, pos))
 				}
 			} else if isWhitespace(src[0]) {
-				if src[0] == "\n" || src[0] == "\r" {
-					internal.LineCount++
-				}
 				src = src[1:]
 			} else {
-				fmt.Fprintf(os.Stderr, "Error on line %d: Invalid character '%s'", internal.Line(), string(src[0]))
-				os.Exit(0)
+				errors = append(errors, Error{pos.file, pos.line, pos.column, fmt.Sprintf("Invalid character '%s'", src[0])})
+				src = src[1:]
+			}
+		}
+	}
+
+	tokens = append(tokens, createToken("EndOfFile", tokentype.EndOfFile, srcPositions[len(srcPositions)-1]))
+	return tokens, errors
+}
+
+// escape decodes the escape sequence at the start of src, which begins with a
+// backslash. It returns the decoded text, how many characters it used, and a
+// message if the sequence is invalid.
+func escape(src []string) (string, int, string) {
+	if len(src) < 2 {
+		return "", 1, "Unterminated escape sequence"
+	}
+
+	switch src[1] {
+	case "n":
+		return "\n", 2, ""
+	case "t":
+		return "\t", 2, ""
+	case "r":
+		return "\r", 2, ""
+	case "b":
+		return "\b", 2, ""
+	case "f":
+		return "\f", 2, ""
+	case "v":
+		return "\v", 2, ""
+	case "0":
+		return "\x00", 2, ""
+	case "\\", "\"", "'", "`", "$":
+		return src[1], 2, ""
+	case "x":
+		return hexEscape(src, 2, 2)
+	case "u":
+		if len(src) > 2 && src[2] == "{" {
+			end := 3
+			for end < len(src) && src[end] != "}" && end < 10 {
+				end++
+			}
+			if end == len(src) || src[end] != "}" {
+				return "", 2, "Invalid unicode escape sequence"
 			}
+			decoded, _, message := hexEscape(src, 3, end-3)
+			return decoded, end + 1, message
 		}
+		return hexEscape(src, 2, 4)
+	}
+
+	return src[1], 2, fmt.Sprintf("Invalid escape sequence '\\%s'", src[1])
+}
+
+// hexEscape decodes the digits hex digits at src[start:] as a code point.
+func hexEscape(src []string, start, digits int) (string, int, string) {
+	if digits == 0 || len(src) < start+digits {
+		return "", start, "Invalid escape sequence '\\" + src[1] + "'"
 	}
 
-	tokens = append(tokens, createToken("EndOfFile", tokentype.EndOfFile))
-	return tokens
+	code, err := strconv.ParseUint(strings.Join(src[start:start+digits], ""), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return "", start + digits, "Invalid escape sequence '\\" + src[1] + strings.Join(src[start:start+digits], "") + "'"
+	}
+
+	return string(rune(code)), start + digits, ""
+}
+
+// closingBrace returns the index in src of the '}' closing a template
+// expression, skipping nested braces and strings, or -1 if there is none.
+func closingBrace(src []string) int {
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case "{":
+			depth++
+		case "}":
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case "\"", "'", "`":
+			quote := src[i]
+			for i++; i < len(src) && src[i] != quote; i++ {
+				if src[i] == "\\" {
+					i++
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// offset moves a line and column, counted from the start of a template
+// expression, to where that expression starts in the file.
+func offset(start position, line, column int) (int, int) {
+	if line == 1 {
+		column += start.column - 1
+	}
+	return line + start.line - 1, column
 }
@@ -0,0 +1,179 @@
+package lexer_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Jamlie/Jamlang/lexer"
+	"github.com/Jamlie/Jamlang/tokentype"
+)
+
+// token is the type and value of a lexer.Token.
+type token struct {
+	Type  tokentype.TokenType
+	Value string
+}
+
+// scan returns the types and values of the tokens in source, without the
+// final EndOfFile, failing the test if there are errors.
+func scan(t *testing.T, source string) []token {
+	t.Helper()
+	tokens, errs := lexer.Scan("test.jam", source)
+	if len(errs) > 0 {
+		t.Fatalf("Scan(%q) errors: %v", source, errs)
+	}
+	var got []token
+	for _, tok := range tokens[:len(tokens)-1] {
+		got = append(got, token{tok.Type, tok.Value})
+	}
+	return got
+}
+
+func TestScanErrors(t *testing.T) {
+	source := "let a = 1 @\nlet b = #\nlet s = \"abc"
+	tokens, errs := lexer.Scan("bad.jam", source)
+	want := []lexer.Error{
+		{File: "bad.jam", Line: 1, Column: 11, Message: "Invalid character '@'"},
+		{File: "bad.jam", Line: 2, Column: 9, Message: "Invalid character '#'"},
+		{File: "bad.jam", Line: 3, Column: 9, Message: "Unterminated string"},
+	}
+	if !slices.Equal(errs, want) {
+		t.Errorf("errors = %v, want %v", errs, want)
+	}
+	if len(tokens) == 0 {
+		t.Error("Scan returned no tokens, want the ones around the errors")
+	}
+
+	tokens, err := lexer.TokenizeFile("bad.jam", source)
+	var scanErr lexer.Error
+	if tokens != nil || !errors.As(err, &scanErr) || scanErr != want[0] {
+		t.Errorf("TokenizeFile = %d tokens, %v, want no tokens and the errors", len(tokens), err)
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	tests := map[string]string{
+		`"a\nb"`:          "a\nb",
+		`'\t|\r|\0'`:      "\t|\r|\x00",
+		`"\"quoted\" \\"`: `"quoted" \`,
+		`'it\'s'`:         "it's",
+		`"\x41\u00e9"`:    "Aé",
+		`"\u{1F600}"`:     "😀",
+		"`\\${x}`":        "${x}",
+		"`tab\\t`":        "tab\t",
+	}
+	for source, want := range tests {
+		got := scan(t, source)
+		if len(got) != 1 || got[0].Value != want {
+			t.Errorf("%s scanned as %q, want %q", source, got, want)
+		}
+	}
+
+	_, errs := lexer.Scan("test.jam", `"\q" "\u{zz}" "\u{110000}"`)
+	want := []lexer.Error{
+		{File: "test.jam", Line: 1, Column: 2, Message: `Invalid escape sequence '\q'`},
+		{File: "test.jam", Line: 1, Column: 7, Message: `Invalid escape sequence '\uzz'`},
+		{File: "test.jam", Line: 1, Column: 16, Message: `Invalid escape sequence '\u110000'`},
+	}
+	if !slices.Equal(errs, want) {
+		t.Errorf("errors = %v, want %v", errs, want)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []token
+	}{
+		{"`plain`", []token{{tokentype.String, "plain"}}},
+		{"`a ${x} b ${y} c`", []token{
+			{tokentype.TemplateHead, "a "},
+			{tokentype.Identifier, "x"},
+			{tokentype.TemplateMiddle, " b "},
+			{tokentype.Identifier, "y"},
+			{tokentype.TemplateTail, " c"},
+		}},
+		{"`a ${`in ${y}`} c`", []token{
+			{tokentype.TemplateHead, "a "},
+			{tokentype.TemplateHead, "in "},
+			{tokentype.Identifier, "y"},
+			{tokentype.TemplateTail, ""},
+			{tokentype.TemplateTail, " c"},
+		}},
+		{"`${ {k: 1}.k }${\"}\"}`", []token{
+			{tokentype.TemplateHead, ""},
+			{tokentype.LSquirly, "{"},
+			{tokentype.Identifier, "k"},
+			{tokentype.Colon, ":"},
+			{tokentype.Integer, "1"},
+			{tokentype.RSquirly, "}"},
+			{tokentype.Dot, "."},
+			{tokentype.Identifier, "k"},
+			{tokentype.TemplateMiddle, ""},
+			{tokentype.String, "}"},
+			{tokentype.TemplateTail, ""},
+		}},
+	}
+	for _, test := range tests {
+		if got := scan(t, test.source); !slices.Equal(got, test.want) {
+			t.Errorf("%s scanned as %v, want %v", test.source, got, test.want)
+		}
+	}
+
+	_, errs := lexer.Scan("test.jam", "`a ${x`")
+	if want := []lexer.Error{{File: "test.jam", Line: 1, Column: 4, Message: "Unterminated template expression"}}; !slices.Equal(errs, want) {
+		t.Errorf("errors = %v, want %v", errs, want)
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	source := "let 名前 = \"héllo 😀\"\nlet café = 'ü' + café"
+	want := []token{
+		{tokentype.Let, "let"},
+		{tokentype.Identifier, "名前"},
+		{tokentype.Equals, "="},
+		{tokentype.String, "héllo 😀"},
+		{tokentype.Let, "let"},
+		{tokentype.Identifier, "café"},
+		{tokentype.Equals, "="},
+		{tokentype.String, "ü"},
+		{tokentype.BinaryOperator, "+"},
+		{tokentype.Identifier, "café"},
+	}
+	if got := scan(t, source); !slices.Equal(got, want) {
+		t.Errorf("scanned as %v, want %v", got, want)
+	}
+
+	_, errs := lexer.Scan("test.jam", "let ö = 1 €")
+	if want := []lexer.Error{{File: "test.jam", Line: 1, Column: 11, Message: "Invalid character '€'"}}; !slices.Equal(errs, want) {
+		t.Errorf("errors = %v, want %v", errs, want)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	source := "let s = \"a\\n\\u{1F600}\" + x\nlet t = `é\n${y}!` ; z\n\n  w"
+	tokens, errs := lexer.Scan("pos.jam", source)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	type at struct {
+		Value        string
+		Line, Column int
+	}
+	want := []at{
+		{"let", 1, 1}, {"s", 1, 5}, {"=", 1, 7}, {"a\n😀", 1, 9}, {"+", 1, 24}, {"x", 1, 26},
+		{"let", 2, 1}, {"t", 2, 5}, {"=", 2, 7}, {"é\n", 2, 9}, {"y", 3, 3}, {"!", 3, 5}, {";", 3, 8}, {"z", 3, 10},
+		{"w", 5, 3}, {"EndOfFile", 5, 4},
+	}
+	var got []at
+	for _, tok := range tokens {
+		if tok.File != "pos.jam" {
+			t.Errorf("%q is in file %q, want pos.jam", tok.Value, tok.File)
+		}
+		got = append(got, at{tok.Value, tok.Line, tok.Column})
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/tokentype"
+)
+
+type Diagnostic struct {
+	Position ast.Position
+	Message  string
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("Error at %s: %s", d.Position, d.Message)
+}
+
+func (d Diagnostic) String() string {
+	return d.Error()
+}
+
+// syntaxError is the panic value used to unwind out of a statement that
+// cannot be parsed; parseRecoverableStatement catches it.
+type syntaxError struct{}
+
+func (p *Parser) report(pos ast.Position, message string) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{Position: pos, Message: message})
+}
+
+func (p *Parser) fail(format string, args ...any) {
+	p.failAt(p.position(), format, args...)
+}
+
+func (p *Parser) failAt(pos ast.Position, format string, args ...any) {
+	p.report(pos, fmt.Sprintf(format, args...))
+	panic(syntaxError{})
+}
+
+// parseRecoverableStatement parses a statement, and if it contains a syntax
+// error, skips ahead to the start of the next statement and returns nil.
+func (p *Parser) parseRecoverableStatement() (statement ast.Statement) {
+	remaining := len(p.tokens)
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(syntaxError); !ok {
+				panic(r)
+			}
+			p.synchronize(remaining)
+			statement = nil
+		}
+	}()
+
+	return p.parseStatement()
+}
+
+func (p *Parser) synchronize(remaining int) {
+	if len(p.tokens) == remaining {
+		p.eat()
+	}
+
+	for p.notEndOfFile() {
+		switch p.at().Type {
+		case tokentype.SemiColon:
+			p.eat()
+			return
+		case tokentype.RSquirly, tokentype.Let, tokentype.Constant, tokentype.Function,
+			tokentype.Return, tokentype.If, tokentype.While, tokentype.Loop, tokentype.ForEach,
+			tokentype.For, tokentype.Import, tokentype.Break, tokentype.Continue, tokentype.Class, tokentype.Type,
+			tokentype.Throw, tokentype.Try:
+			return
+		}
+		p.eat()
+	}
+}
+
+// parseBody parses the statements of a block up to, but not including, its
+// closing '}'.
+func (p *Parser) parseBody() []ast.Statement {
+	var body []ast.Statement
+	for p.notEndOfFile() && p.at().Type != tokentype.RSquirly {
+		if statement := p.parseRecoverableStatement(); statement != nil {
+			body = append(body, statement)
+		}
+	}
+	return body
+}
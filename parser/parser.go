@@ -1,30 +1,67 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"sort"
 	"strconv"
 
 	"github.com/Jamlie/Jamlang/ast"
-	"github.com/Jamlie/Jamlang/internal"
 	"github.com/Jamlie/Jamlang/lexer"
 	"github.com/Jamlie/Jamlang/tokentype"
 )
 
 type Parser struct {
-	tokens     []lexer.Token
-	isFunction bool
-	isLoop     bool
+	tokens      []lexer.Token
+	isFunction  bool
+	isLoop      bool
+	diagnostics []Diagnostic
 }
 
 func NewParser() *Parser {
 	return &Parser{}
 }
 
-func (p *Parser) ProduceAST(sourceCode string) ast.Program {
-	p.tokens = lexer.Tokenize(sourceCode)
+// ProduceAST parses sourceCode, failing with every syntax error joined
+// together if there are any. Use ProduceASTWithDiagnostics to get each
+// error and the statements that did parse.
+func (p *Parser) ProduceAST(sourceCode string) (ast.Program, error) {
+	return p.ProduceFileAST("", sourceCode)
+}
+
+// ProduceFileAST is ProduceAST, recording fileName on every token and node.
+func (p *Parser) ProduceFileAST(fileName string, sourceCode string) (ast.Program, error) {
+	diagnostics, program := p.ProduceFileASTWithDiagnostics(fileName, sourceCode)
+	if len(diagnostics) > 0 {
+		errs := make([]error, len(diagnostics))
+		for i, diagnostic := range diagnostics {
+			errs[i] = diagnostic
+		}
+		return ast.Program{}, errors.Join(errs...)
+	}
+	return program, nil
+}
+
+// ProduceASTWithDiagnostics parses sourceCode. Every syntax error found is
+// returned, the parser skipping to the next statement after each one, along
+// with the statements that did parse.
+func (p *Parser) ProduceASTWithDiagnostics(sourceCode string) ([]Diagnostic, ast.Program) {
+	return p.ProduceFileASTWithDiagnostics("", sourceCode)
+}
+
+// ProduceFileASTWithDiagnostics is ProduceASTWithDiagnostics, recording
+// fileName on every token and node so diagnostics can point back into the
+// file.
+func (p *Parser) ProduceFileASTWithDiagnostics(fileName string, sourceCode string) ([]Diagnostic, ast.Program) {
+	var lexErrors []lexer.Error
+	p.tokens, lexErrors = lexer.Scan(fileName, sourceCode)
+	p.diagnostics = nil
+	for _, err := range lexErrors {
+		p.report(ast.Position{File: err.File, Line: err.Line, Column: err.Column}, err.Message)
+	}
 	program := ast.Program{
-		Body: []ast.Statement{},
+		Position: p.position(),
+		Body:     []ast.Statement{},
 	}
 
 	for p.notEndOfFile() {
@@ -32,13 +69,21 @@ func (p *Parser) ProduceAST(sourceCode string) ast.Program {
 			p.eat()
 			continue
 		}
-		program.Body = append(program.Body, p.parseStatement())
+		if statement := p.parseRecoverableStatement(); statement != nil {
+			program.Body = append(program.Body, statement)
+		}
 	}
 
-	return program
+	sort.SliceStable(p.diagnostics, func(i, j int) bool {
+		a, b := p.diagnostics[i].Position, p.diagnostics[j].Position
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+
+	return p.diagnostics, program
 }
 
 func (p *Parser) parseStatement() ast.Statement {
+	pos := p.position()
 	switch p.at().Type {
 	case tokentype.OpenComment:
 		return p.parseComment()
@@ -48,31 +93,26 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseFunctionDeclaration()
 	case tokentype.Return:
 		if !p.isFunction {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Return statement outside of function\n", internal.Line())
-			os.Exit(0)
+			p.report(p.position(), "Return statement outside of function")
 		}
 		return p.parseReturnStatement()
 	case tokentype.Break:
 		if !p.isLoop {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Break statement outside of loop\n", internal.Line())
-			os.Exit(0)
+			p.report(p.position(), "Break statement outside of loop")
 		}
 		return p.parseBreakStatement()
 	case tokentype.Continue:
 		if !p.isLoop {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Continue statement outside of loop\n", internal.Line())
-			os.Exit(0)
+			p.report(p.position(), "Continue statement outside of loop")
 		}
 		return p.parseContinueStatement()
 	case tokentype.If:
 		return p.parseIfStatement()
 	case tokentype.ElseIf:
-		fmt.Fprintf(os.Stderr, "Error on line %d: Else if statement outside of if statement", internal.Line())
-		os.Exit(0)
+		p.fail("Else if statement outside of if statement")
 		return nil
 	case tokentype.Else:
-		fmt.Fprintf(os.Stderr, "Error on line %d: Else statement outside of if statement", internal.Line())
-		os.Exit(0)
+		p.fail("Else statement outside of if statement")
 		return nil
 	case tokentype.While:
 		return p.parseWhileStatement()
@@ -84,160 +124,203 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseForStatement()
 	case tokentype.Import:
 		return p.parseImportStatement()
+	case tokentype.Class:
+		return p.parseClassDeclaration()
+	case tokentype.Type:
+		return p.parseTypeDeclaration()
+	case tokentype.Throw:
+		return p.parseThrowStatement()
+	case tokentype.Try:
+		return p.parseTryStatement()
+	case tokentype.Catch:
+		p.fail("Catch clause outside of try statement")
+		return nil
+	case tokentype.Finally:
+		p.fail("Finally clause outside of try statement")
+		return nil
 	case tokentype.SemiColon:
 		p.eat()
-		return &ast.NullLiteral{}
+		return &ast.NullLiteral{Position: pos}
 	default:
 		return p.parseExpression()
 	}
 }
 
+func (p *Parser) parseThrowStatement() ast.Statement {
+	pos := p.position()
+	p.eat()
+	return &ast.ThrowStatement{
+		Position: pos,
+		Value:    p.parseExpression(),
+	}
+}
+
+func (p *Parser) parseTryStatement() ast.Statement {
+	pos := p.position()
+	p.eat()
+
+	p.expect(tokentype.LSquirly, "Expected { after try")
+	statement := &ast.TryStatement{Position: pos, Body: p.parseBody()}
+	p.expect(tokentype.RSquirly, "Expected } after try block")
+
+	if p.at().Type == tokentype.Catch {
+		p.eat()
+		statement.HasCatch = true
+		if p.at().Type == tokentype.OpenParen {
+			p.eat()
+			statement.CatchName = p.expect(tokentype.Identifier, "Expected identifier in catch clause").Value
+			p.expect(tokentype.CloseParen, "Expected ) after catch variable")
+		}
+		p.expect(tokentype.LSquirly, "Expected { after catch")
+		statement.Catch = p.parseBody()
+		p.expect(tokentype.RSquirly, "Expected } after catch block")
+	}
+
+	if p.at().Type == tokentype.Finally {
+		p.eat()
+		statement.HasFinally = true
+		p.expect(tokentype.LSquirly, "Expected { after finally")
+		statement.Finally = p.parseBody()
+		p.expect(tokentype.RSquirly, "Expected } after finally block")
+	}
+
+	if !statement.HasCatch && !statement.HasFinally {
+		p.report(pos, "Try statement needs a catch or finally clause")
+	}
+
+	return statement
+}
+
 func (p *Parser) parseComment() ast.Statement {
-	for p.at().Type != tokentype.CloseComment {
+	pos := p.position()
+	for p.notEndOfFile() && p.at().Type != tokentype.CloseComment {
 		p.eat()
 	}
-	p.expect(tokentype.CloseComment, fmt.Sprintf("Error on line %d: Expected close comment", internal.Line()))
-	return &ast.NullLiteral{}
+	p.expect(tokentype.CloseComment, "Expected close comment")
+	return &ast.NullLiteral{Position: pos}
 }
 
 func (p *Parser) parseImportStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
-	path := p.expect(tokentype.String, fmt.Sprintf("Error on line %d: Expected string after import statement", internal.Line())).Value
-	p.expect(tokentype.SemiColon, fmt.Sprintf("Error on line %d: Expected ';' after import statement", internal.Line()))
-	return &ast.ImportStatement{Path: path}
+	path := p.expect(tokentype.String, "Expected string after import statement").Value
+	p.expect(tokentype.SemiColon, "Expected ';' after import statement")
+	return &ast.ImportStatement{Position: pos, Path: path}
 }
 
 func (p *Parser) parseForStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
+	defer func(isLoop bool) { p.isLoop = isLoop }(p.isLoop)
 	p.isLoop = true
-	defer func() { p.isLoop = false }()
 	init := p.parseStatement()
-	p.expect(tokentype.SemiColon, fmt.Sprintf("Error on line %d: Expected ';' after for statement", internal.Line()))
+	p.expect(tokentype.SemiColon, "Expected ';' after for statement")
 	condition := p.parseExpression()
-	p.expect(tokentype.SemiColon, fmt.Sprintf("Error on line %d: Expected ';' after for statement", internal.Line()))
+	p.expect(tokentype.SemiColon, "Expected ';' after for statement")
 	increment := p.parseExpression()
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected '{' after for statement", internal.Line()))
-	var body []ast.Statement
-	for p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected '}' after for statement", internal.Line()))
-	return &ast.ForStatement{Init: init, Condition: condition, Update: increment, Body: body}
+	p.expect(tokentype.LSquirly, "Expected '{' after for statement")
+	body := p.parseBody()
+	p.expect(tokentype.RSquirly, "Expected '}' after for statement")
+	return &ast.ForStatement{Position: pos, Init: init, Condition: condition, Update: increment, Body: body}
 }
 
 func (p *Parser) parseForEachStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
 
+	defer func(isLoop bool) { p.isLoop = isLoop }(p.isLoop)
 	p.isLoop = true
-	defer func() { p.isLoop = false }()
 
-	value := p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected identifier in for each statement", internal.Line())).Value
+	value := p.expect(tokentype.Identifier, "Expected identifier in for each statement").Value
 	if p.at().Type == tokentype.Comma {
 		key := value
 		p.eat()
-		val := p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected identifier in for each statement", internal.Line())).Value
-		p.expect(tokentype.In, fmt.Sprintf("Error on line %d: Expected in after identifier in for each statement", internal.Line()))
+		val := p.expect(tokentype.Identifier, "Expected identifier in for each statement").Value
+		p.expect(tokentype.In, "Expected in after identifier in for each statement")
 		obj := p.parseExpression()
 
-		p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after for each statement", internal.Line()))
+		p.expect(tokentype.LSquirly, "Expected { after for each statement")
 
-		var body []ast.Statement
-		for p.at().Type != tokentype.RSquirly {
-			body = append(body, p.parseStatement())
-		}
+		body := p.parseBody()
 
-		p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after for each statement", internal.Line()))
+		p.expect(tokentype.RSquirly, "Expected } after for each statement")
 
-		return &ast.ForEachStatement{Key: key, Value: val, Variable: "", Collection: obj, Body: body}
+		return &ast.ForEachStatement{Position: pos, Key: key, Value: val, Variable: "", Collection: obj, Body: body}
 	}
-	p.expect(tokentype.In, fmt.Sprintf("Error on line %d: Expected in after identifier in for each statement", internal.Line()))
+	p.expect(tokentype.In, "Expected in after identifier in for each statement")
 	array := p.parseExpression()
 
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after for each statement", internal.Line()))
+	p.expect(tokentype.LSquirly, "Expected { after for each statement")
 
-	var body []ast.Statement
-	for p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
+	body := p.parseBody()
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after for each statement", internal.Line()))
+	p.expect(tokentype.RSquirly, "Expected } after for each statement")
 
-	return &ast.ForEachStatement{Variable: value, Key: "", Value: "", Collection: array, Body: body}
+	return &ast.ForEachStatement{Position: pos, Variable: value, Key: "", Value: "", Collection: array, Body: body}
 }
 
 func (p *Parser) parseLoopStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after loop statement", internal.Line()))
+	p.expect(tokentype.LSquirly, "Expected { after loop statement")
 
+	defer func(isLoop bool) { p.isLoop = isLoop }(p.isLoop)
 	p.isLoop = true
-	defer func() { p.isLoop = false }()
-	var body []ast.Statement
-	for p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
+	body := p.parseBody()
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after loop statement", internal.Line()))
+	p.expect(tokentype.RSquirly, "Expected } after loop statement")
 
-	return &ast.LoopStatement{Body: body}
+	return &ast.LoopStatement{Position: pos, Body: body}
 }
 
 func (p *Parser) parseWhileStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
 	condition := p.parseExpression()
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after while statement", internal.Line()))
+	p.expect(tokentype.LSquirly, "Expected { after while statement")
 
+	defer func(isLoop bool) { p.isLoop = isLoop }(p.isLoop)
 	p.isLoop = true
-	defer func() { p.isLoop = false }()
-	var body []ast.Statement
-	for p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
+	body := p.parseBody()
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after while statement", internal.Line()))
+	p.expect(tokentype.RSquirly, "Expected } after while statement")
 
 	return &ast.WhileStatement{
+		Position:  pos,
 		Condition: condition,
 		Body:      body,
 	}
 }
 
 func (p *Parser) parseIfStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
 	condition := p.parseExpression()
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after if statement", internal.Line()))
+	p.expect(tokentype.LSquirly, "Expected { after if statement")
 
-	var body []ast.Statement
-	for p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
+	body := p.parseBody()
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after if statement", internal.Line()))
+	p.expect(tokentype.RSquirly, "Expected } after if statement")
 
 	var elseifCondition []ast.Expression
 	var elseifBody [][]ast.Statement
 	if p.at().Type == tokentype.ElseIf {
 		p.eat()
 		elseifCondition = append(elseifCondition, p.parseExpression())
-		p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after else if statement", internal.Line()))
+		p.expect(tokentype.LSquirly, "Expected { after else if statement")
 
-		var elseifBodyTemp []ast.Statement
-		for p.at().Type != tokentype.RSquirly {
-			elseifBodyTemp = append(elseifBodyTemp, p.parseStatement())
-		}
+		elseifBodyTemp := p.parseBody()
 		elseifBody = append(elseifBody, elseifBodyTemp)
-		p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after else if statement", internal.Line()))
+		p.expect(tokentype.RSquirly, "Expected } after else if statement")
 
 		for p.at().Type == tokentype.ElseIf {
 			p.eat()
 			elseifCondition = append(elseifCondition, p.parseExpression())
-			p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after else if statement", internal.Line()))
+			p.expect(tokentype.LSquirly, "Expected { after else if statement")
 
-			var elseifBodyTemp []ast.Statement
-			for p.at().Type != tokentype.RSquirly {
-				elseifBodyTemp = append(elseifBodyTemp, p.parseStatement())
-			}
+			elseifBodyTemp := p.parseBody()
 			elseifBody = append(elseifBody, elseifBodyTemp)
-			p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after else if statement", internal.Line()))
+			p.expect(tokentype.RSquirly, "Expected } after else if statement")
 		}
 		// p.eat()
 		// elseifCondition = p.parseExpression()
@@ -262,16 +345,14 @@ func (p *Parser) parseIfStatement() ast.Statement {
 
 	if p.at().Type == tokentype.Else {
 		p.eat()
-		p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after else statement", internal.Line()))
+		p.expect(tokentype.LSquirly, "Expected { after else statement")
 
-		var elseBody []ast.Statement
-		for p.at().Type != tokentype.RSquirly {
-			elseBody = append(elseBody, p.parseStatement())
-		}
+		elseBody := p.parseBody()
 
-		p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after else statement", internal.Line()))
+		p.expect(tokentype.RSquirly, "Expected } after else statement")
 
 		return &ast.ConditionalStatement{
+			Position:         pos,
 			Condition:        condition,
 			Body:             body,
 			Alternate:        elseBody,
@@ -281,6 +362,7 @@ func (p *Parser) parseIfStatement() ast.Statement {
 	}
 
 	return &ast.ConditionalStatement{
+		Position:         pos,
 		Condition:        condition,
 		Body:             body,
 		ElseIfConditions: elseifCondition,
@@ -288,98 +370,170 @@ func (p *Parser) parseIfStatement() ast.Statement {
 	}
 }
 
-// func (p *Parser) parseClassDeclaration() ast.Statement {
-// 	p.eat()
-// 	name := p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected class name after class keyword", internal.Line())).Value
-// 	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected { after class name", internal.Line()))
-//
-// 	var body []ast.Statement
-// 	for p.at().Type != tokentype.RSquirly {
-// 		body = append(body, p.parseStatement())
-// 	}
-//
-// 	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected } after class declaration", internal.Line()))
-//
-// 	return &ast.ClassDeclaration{
-// 		Name: name,
-// 		Body: body,
-// 	}
-// }
+func (p *Parser) parseClassDeclaration() ast.Statement {
+	pos := p.position()
+	p.eat()
+	name := p.expect(tokentype.Identifier, "Expected class name after class keyword").Value
+	var parent string
+	if p.at().Type == tokentype.Extends {
+		p.eat()
+		parent = p.expect(tokentype.Identifier, "Expected parent class name after extends").Value
+	}
+	p.expect(tokentype.LSquirly, "Expected { after class name")
+
+	var body []ast.Statement
+	for p.notEndOfFile() && p.at().Type != tokentype.RSquirly {
+		switch p.at().Type {
+		case tokentype.Let, tokentype.Constant:
+			body = append(body, p.parseVariableDeclaration())
+		case tokentype.Function:
+			if p.peek().Type != tokentype.Identifier {
+				p.fail("Expected method name after fn keyword")
+			}
+			body = append(body, p.parseFunctionDeclaration())
+		case tokentype.SemiColon:
+			p.eat()
+		default:
+			p.fail("Expected field or method declaration in class body, got %s", p.at().Value)
+		}
+	}
+
+	p.expect(tokentype.RSquirly, "Expected } after class declaration")
+
+	return &ast.ClassDeclaration{
+		Position: pos,
+		Name:     name,
+		Parent:   parent,
+		Body:     body,
+	}
+}
 
 func (p *Parser) parseFunctionDeclaration() ast.Statement {
+	pos := p.position()
 	p.eat()
 	var name string
 
 	if p.at().Type != tokentype.OpenParen {
-		name = p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected function name after fn keyword", internal.Line())).Value
+		name = p.expect(tokentype.Identifier, "Expected function name after fn keyword").Value
 	}
 
-	args := p.parseArgs()
-	var params []string
-
-	for _, arg := range args {
-		if arg.Kind() != ast.IdentifierType {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected function parameter to be of type string, got %s\n", internal.Line(), arg.Kind())
-			os.Exit(0)
-			return nil
-		}
-
-		params = append(params, arg.(*ast.Identifier).Symbol)
-	}
+	params := p.parseParameters()
 
 	returnType := ast.AnyType
+	var userReturnType ast.Expression
 
 	if p.at().Type == tokentype.Colon {
 		p.eat()
-		var err error
-		returnType, err = p.parseType()
-		if err != nil {
-			fmt.Printf("Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-		}
+		returnType, userReturnType = p.parseTypeAnnotation()
 	}
 
-	p.expect(tokentype.LSquirly, fmt.Sprintf("Error on line %d: Expected '{' after function declaration", internal.Line()))
+	p.expect(tokentype.LSquirly, "Expected '{' after function declaration")
 
-	if !p.isFunction {
-		p.isFunction = true
-		defer func() { p.isFunction = false }()
-	}
+	defer func(isFunction, isLoop bool) {
+		p.isFunction, p.isLoop = isFunction, isLoop
+	}(p.isFunction, p.isLoop)
+	p.isFunction, p.isLoop = true, false
 
-	body := []ast.Statement{}
-	for p.at().Type != tokentype.EndOfFile && p.at().Type != tokentype.RSquirly {
-		body = append(body, p.parseStatement())
-	}
+	body := p.parseBody()
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Expected '}' after function declaration", internal.Line()))
+	p.expect(tokentype.RSquirly, "Expected '}' after function declaration")
 
 	return &ast.FunctionDeclaration{
-		Name:       name,
-		Parameters: params,
-		Body:       body,
-		ReturnType: returnType,
+		Position:              pos,
+		Name:                  name,
+		Parameters:            params,
+		Body:                  body,
+		ReturnType:            returnType,
+		UserDefinedReturnType: userReturnType,
+	}
+}
+
+func (p *Parser) parseParameters() []ast.Parameter {
+	p.expect(tokentype.OpenParen, "Expected '(' after function name")
+
+	params := []ast.Parameter{}
+	seen := make(map[string]bool)
+	hasDefault := false
+
+	for p.notEndOfFile() && p.at().Type != tokentype.CloseParen {
+		pos := p.position()
+		isRest := false
+		if p.at().Type == tokentype.Ellipsis {
+			p.eat()
+			isRest = true
+		}
+
+		name := p.expect(tokentype.Identifier, "Expected parameter name").Value
+		if seen[name] {
+			p.report(pos, fmt.Sprintf("Duplicate parameter %s", name))
+		}
+		seen[name] = true
+
+		param := ast.Parameter{
+			Position: pos,
+			Name:     name,
+			Type:     ast.AnyType,
+			IsRest:   isRest,
+		}
+
+		if p.at().Type == tokentype.Colon {
+			p.eat()
+			param.Type, param.UserDefinedType = p.parseTypeAnnotation()
+		}
+
+		if p.at().Type == tokentype.Equals {
+			p.eat()
+			if isRest {
+				p.report(pos, fmt.Sprintf("Rest parameter %s cannot have a default value", name))
+			}
+			param.Default = p.parseExpression()
+			hasDefault = true
+		} else if hasDefault && !isRest {
+			p.report(pos, fmt.Sprintf("Parameter %s without a default value follows a parameter with one", name))
+		}
+
+		params = append(params, param)
+
+		if isRest && p.at().Type != tokentype.CloseParen {
+			p.report(pos, fmt.Sprintf("Rest parameter %s must be the last parameter", name))
+		}
+
+		if p.at().Type == tokentype.Comma {
+			p.eat()
+		} else if p.at().Type != tokentype.CloseParen {
+			p.fail("Expected , or ) after parameter %s", name)
+		}
 	}
+
+	p.expect(tokentype.CloseParen, "Expected ')' after function parameters")
+
+	return params
 }
 
 func (p *Parser) parseContinueStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
-	return &ast.ContinueStatement{}
+	return &ast.ContinueStatement{Position: pos}
 }
 
 func (p *Parser) parseBreakStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
-	return &ast.BreakStatement{}
+	return &ast.BreakStatement{Position: pos}
 }
 
 func (p *Parser) parseReturnStatement() ast.Statement {
+	pos := p.position()
 	p.eat()
 	return &ast.ReturnStatement{
-		Value: p.parseExpression(),
+		Position: pos,
+		Value:    p.parseExpression(),
 	}
 }
 
 var Types = map[string]ast.VariableType{
 	"str":    ast.StringType,
+	"string": ast.StringType,
 	"i8":     ast.Int8Type,
 	"i16":    ast.Int16Type,
 	"i32":    ast.Int32Type,
@@ -402,66 +556,163 @@ func (p *Parser) parseType() (ast.VariableType, error) {
 		}
 	}
 
-	return ast.VariableType(""), fmt.Errorf("Error on line %d: Expected type", internal.Line())
+	return ast.VariableType(""), fmt.Errorf("Expected type")
+}
+
+// parseTypeAnnotation parses the type after a `:`. Builtin names resolve to
+// their VariableType; anything else is returned as a user-defined type
+// expression to be resolved at runtime.
+func (p *Parser) parseTypeAnnotation() (ast.VariableType, ast.Expression) {
+	typeExpr := p.parseTypeExpression()
+
+	switch typeExpr := typeExpr.(type) {
+	case *ast.Identifier:
+		if t, ok := Types[typeExpr.Symbol]; ok {
+			return t, nil
+		}
+		return ast.AnyType, typeExpr
+	default:
+		return ast.ObjectType, typeExpr
+	}
+}
+
+func (p *Parser) parseTypeExpression() ast.Expression {
+	pos := p.position()
+
+	switch p.at().Type {
+	case tokentype.Identifier:
+		return &ast.Identifier{
+			Position: pos,
+			Symbol:   p.eat().Value,
+		}
+	case tokentype.LSquirly:
+		return p.parseObjectType()
+	default:
+		p.fail("Expected type, got %s", p.at().Value)
+		return nil
+	}
+}
+
+func (p *Parser) parseObjectType() ast.Expression {
+	pos := p.position()
+	p.eat()
+
+	var fields []ast.TypeField
+	seen := make(map[string]bool)
+
+	for p.notEndOfFile() && p.at().Type != tokentype.RSquirly {
+		keyPos := p.position()
+		name := p.expect(tokentype.Identifier, "Expected field name in object type").Value
+		if seen[name] {
+			p.report(keyPos, fmt.Sprintf("Duplicate field %s in object type", name))
+		}
+		seen[name] = true
+
+		p.expect(tokentype.Colon, "Expected : after field name in object type")
+
+		fields = append(fields, ast.TypeField{
+			Position: keyPos,
+			Name:     name,
+			Type:     p.parseTypeExpression(),
+		})
+
+		if p.at().Type == tokentype.Comma || p.at().Type == tokentype.SemiColon {
+			p.eat()
+		} else if p.at().Type != tokentype.RSquirly {
+			p.fail("Expected , or } after field type in object type")
+		}
+	}
+
+	p.expect(tokentype.RSquirly, "Expected } after object type")
+
+	return &ast.ObjectTypeLiteral{
+		Position: pos,
+		Fields:   fields,
+	}
+}
+
+func (p *Parser) parseTypeDeclaration() ast.Statement {
+	pos := p.position()
+	p.eat()
+
+	namePos := p.position()
+	name := p.expect(tokentype.Identifier, "Expected type name after type keyword").Value
+	if _, ok := Types[name]; ok {
+		p.report(namePos, fmt.Sprintf("Cannot redefine builtin type %s", name))
+	}
+
+	p.expect(tokentype.Equals, "Expected = after type name")
+
+	typeExpr := p.parseTypeExpression()
+
+	if p.at().Type == tokentype.SemiColon {
+		p.eat()
+	}
+
+	return &ast.TypeDeclaration{
+		Position: pos,
+		Name:     name,
+		Type:     typeExpr,
+	}
 }
 
 func (p *Parser) parseVariableDeclaration() ast.Statement {
+	pos := p.position()
 	isConstant := p.eat().Type == tokentype.Constant
-	identifier := p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected identifier name after let/const keyword", internal.Line())).Value
+	identifier := p.expect(tokentype.Identifier, "Expected identifier name after let/const keyword").Value
 
 	if p.at().Type == tokentype.SemiColon {
 		p.eat()
 		if isConstant {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Constant declaration without assignment is not allowed\n", internal.Line())
-			os.Exit(0)
+			p.fail("Constant declaration without assignment is not allowed")
 			return nil
 		}
 
 		return &ast.VariableDeclaration{
+			Position:   pos,
 			Identifier: identifier,
 			Constant:   isConstant,
-			Value:      &ast.NullLiteral{},
+			Value:      &ast.NullLiteral{Position: pos},
 			Type:       ast.AnyType,
 		}
 	}
 
 	var varType = ast.AnyType
+	var userType ast.Expression
 
 	if p.at().Type == tokentype.Colon {
-		var err error
 		p.eat()
-		varType, err = p.parseType()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Unknown type\n", internal.Line())
-			os.Exit(0)
-		}
+		varType, userType = p.parseTypeAnnotation()
 
 		if isConstant && p.at().Type == tokentype.SemiColon {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Constant declaration without assignment is not allowed", internal.Line())
-			os.Exit(0)
+			p.fail("Constant declaration without assignment is not allowed")
 			return nil
 		}
 		if p.at().Type == tokentype.SemiColon {
 			p.eat()
 			return &ast.VariableDeclaration{
+				Position:          pos,
 				Identifier:        identifier,
 				Constant:          isConstant,
-				Value:             &ast.NullLiteral{},
+				Value:             &ast.NullLiteral{Position: pos},
 				Type:              varType,
-				IsUserDefinedType: false,
+				IsUserDefinedType: userType != nil,
+				UserDefinedType:   userType,
 			}
 		}
 	}
 
-	p.expect(tokentype.Equals, fmt.Sprintf("Error on line %d: Expected = after identifier name", internal.Line()))
+	p.expect(tokentype.Equals, "Expected = after identifier name")
 
 	var declaration ast.Statement
 	declaration = &ast.VariableDeclaration{
+		Position:          pos,
 		Identifier:        identifier,
 		Constant:          isConstant,
 		Value:             p.parseExpression(),
 		Type:              varType,
-		IsUserDefinedType: false,
+		IsUserDefinedType: userType != nil,
+		UserDefinedType:   userType,
 	}
 
 	if !p.isLoop {
@@ -488,6 +739,7 @@ func (p *Parser) parseOrExpression() ast.Expression {
 		p.eat()
 		right := p.parseAndExpression()
 		left = &ast.LogicalExpression{
+			Position: left.Pos(),
 			Operator: "or",
 			Left:     left,
 			Right:    right,
@@ -504,6 +756,7 @@ func (p *Parser) parseAndExpression() ast.Expression {
 		p.eat()
 		right := p.parseXorExpression()
 		left = &ast.LogicalExpression{
+			Position: left.Pos(),
 			Operator: "and",
 			Left:     left,
 			Right:    right,
@@ -519,6 +772,7 @@ func (p *Parser) parseXorExpression() ast.Expression {
 		p.eat()
 		right := p.parseNotExpression()
 		left = &ast.LogicalExpression{
+			Position: left.Pos(),
 			Operator: "xor",
 			Left:     left,
 			Right:    right,
@@ -528,9 +782,11 @@ func (p *Parser) parseXorExpression() ast.Expression {
 }
 
 func (p *Parser) parseNotExpression() ast.Expression {
+	pos := p.position()
 	if p.at().Value == "not" {
 		p.eat()
 		return &ast.LogicalExpression{
+			Position: pos,
 			Operator: "not",
 			Right:    p.parseNotExpression(),
 		}
@@ -539,9 +795,11 @@ func (p *Parser) parseNotExpression() ast.Expression {
 }
 
 func (p *Parser) parseArrayExpression() ast.Expression {
-	if p.at().Type != tokentype.OpenBracket {
-		return p.parseBitwise()
-	}
+	return p.parseBitwise()
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	pos := p.position()
 	p.eat()
 	elements := []ast.Expression{}
 	for p.at().Type != tokentype.CloseBracket {
@@ -550,8 +808,8 @@ func (p *Parser) parseArrayExpression() ast.Expression {
 			p.eat()
 		}
 	}
-	p.expect(tokentype.CloseBracket, fmt.Sprintf("Error on line %d: Expected closing bracket after array expression", internal.Line()))
-	return &ast.ArrayLiteral{Elements: elements}
+	p.expect(tokentype.CloseBracket, "Expected closing bracket after array expression")
+	return &ast.ArrayLiteral{Position: pos, Elements: elements}
 }
 
 func (p *Parser) parseAssignmentExpression() ast.Expression {
@@ -561,6 +819,7 @@ func (p *Parser) parseAssignmentExpression() ast.Expression {
 		p.eat()
 		value := p.parseAssignmentExpression()
 		return &ast.AssignmentExpression{
+			Position: left.Pos(),
 			Assignee: left,
 			Value:    value,
 		}
@@ -570,6 +829,7 @@ func (p *Parser) parseAssignmentExpression() ast.Expression {
 }
 
 func (p *Parser) parseObjectExpression() ast.Expression {
+	pos := p.position()
 	if p.at().Type != tokentype.LSquirly {
 		return p.parseArrayExpression()
 	}
@@ -578,57 +838,63 @@ func (p *Parser) parseObjectExpression() ast.Expression {
 	properties := []ast.Property{}
 
 	for p.notEndOfFile() && p.at().Type != tokentype.RSquirly {
+		keyPos := p.position()
 		if p.at().Type == tokentype.Integer {
 			v := p.parsePrimaryExpression()
 			key := v.(*ast.NumericIntegerLiteral)
-			p.expect(tokentype.Colon, fmt.Sprintf("Error on line %d: Expected : after object key", internal.Line()))
+			p.expect(tokentype.Colon, "Expected : after object key")
 			value := p.parseExpression()
 			properties = append(properties, ast.Property{
-				Key:   strconv.Itoa(int(key.Value)),
-				Value: value,
+				Position: keyPos,
+				Key:      strconv.Itoa(int(key.Value)),
+				Value:    value,
 			})
 		} else if p.at().Type == tokentype.Float {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Floats are not allowed as object keys", internal.Line())
-			os.Exit(0)
+			p.fail("Floats are not allowed as object keys")
 		} else if p.at().Type == tokentype.String {
 			v := p.parsePrimaryExpression()
 			key := v.(*ast.StringLiteral)
-			p.expect(tokentype.Colon, fmt.Sprintf("Error on line %d: Expected : after object key", internal.Line()))
+			p.expect(tokentype.Colon, "Expected : after object key")
 			value := p.parseExpression()
 			properties = append(properties, ast.Property{
-				Key:   key.Value,
-				Value: value,
+				Position: keyPos,
+				Key:      key.Value,
+				Value:    value,
 			})
 		} else if p.at().Type == tokentype.Identifier {
-			key := p.expect(tokentype.Identifier, fmt.Sprintf("Error on line %d: Expected identifier as object key", internal.Line())).Value
+			key := p.expect(tokentype.Identifier, "Expected identifier as object key").Value
 			if p.at().Type == tokentype.Comma {
 				p.eat()
 				properties = append(properties, ast.Property{
-					Key: key,
+					Position: keyPos,
+					Key:      key,
 				})
 				continue
 			} else if p.at().Type == tokentype.RSquirly {
 				properties = append(properties, ast.Property{
-					Key: key,
+					Position: keyPos,
+					Key:      key,
 				})
 				continue
 			}
 
-			p.expect(tokentype.Colon, fmt.Sprintf("Error on line %d: Expected : after object key", internal.Line()))
+			p.expect(tokentype.Colon, "Expected : after object key")
 			value := p.parseExpression()
 			properties = append(properties, ast.Property{
-				Key:   key,
-				Value: value,
+				Position: keyPos,
+				Key:      key,
+				Value:    value,
 			})
 		}
 
 		if p.at().Type != tokentype.RSquirly {
-			p.expect(tokentype.Comma, fmt.Sprintf("Error on line %d: Expected , after object property", internal.Line()))
+			p.expect(tokentype.Comma, "Expected , after object property")
 		}
 	}
 
-	p.expect(tokentype.RSquirly, fmt.Sprintf("Error on line %d: Object literal must end with a }", internal.Line()))
+	p.expect(tokentype.RSquirly, "Object literal must end with a }")
 	return &ast.ObjectLiteral{
+		Position:   pos,
 		Properties: properties,
 	}
 }
@@ -645,6 +911,7 @@ func (p *Parser) parseComparisonExpression() ast.Expression {
 		right := p.parseObjectExpression()
 
 		left = &ast.BinaryExpression{
+			Position: left.Pos(),
 			Left:     left,
 			Operator: operator,
 			Right:    right,
@@ -663,6 +930,7 @@ func (p *Parser) parseBitwise() ast.Expression {
 		right := p.parseBitwiseShiftBit()
 
 		left = &ast.BinaryExpression{
+			Position: left.Pos(),
 			Left:     left,
 			Operator: operator,
 			Right:    right,
@@ -681,6 +949,7 @@ func (p *Parser) parseBitwiseShiftBit() ast.Expression {
 		right := p.parseAdditiveExpression()
 
 		left = &ast.BinaryExpression{
+			Position: left.Pos(),
 			Left:     left,
 			Operator: operator,
 			Right:    right,
@@ -698,6 +967,7 @@ func (p *Parser) parseAdditiveExpression() ast.Expression {
 
 		right := p.parseMultiplicativeExpression()
 		left = &ast.BinaryExpression{
+			Position: left.Pos(),
 			Left:     left,
 			Operator: operator,
 			Right:    right,
@@ -715,6 +985,7 @@ func (p *Parser) parseMultiplicativeExpression() ast.Expression {
 
 		right := p.parseCallMemberExpression()
 		left = &ast.BinaryExpression{
+			Position: left.Pos(),
 			Left:     left,
 			Operator: operator,
 			Right:    right,
@@ -735,9 +1006,16 @@ func (p *Parser) parseCallMemberExpression() ast.Expression {
 }
 
 func (p *Parser) parseCallExpression(caller ast.Expression) ast.Expression {
+	args, namedArgs := p.parseArgs()
 	var callExpression ast.Expression = &ast.CallExpression{
-		Caller: caller,
-		Args:   p.parseArgs(),
+		Position:  caller.Pos(),
+		Caller:    caller,
+		Args:      args,
+		NamedArgs: namedArgs,
+	}
+
+	if p.at().Type == tokentype.Dot || p.at().Type == tokentype.OpenBracket {
+		callExpression = p.parseMemberAccess(callExpression)
 	}
 
 	if p.at().Type == tokentype.OpenParen {
@@ -747,35 +1025,66 @@ func (p *Parser) parseCallExpression(caller ast.Expression) ast.Expression {
 	return callExpression
 }
 
-func (p *Parser) parseArgs() []ast.Expression {
-	p.expect(tokentype.OpenParen, fmt.Sprintf("Error on line %d: Expected '(' after function name", internal.Line()))
+func (p *Parser) parseArgs() ([]ast.Expression, []ast.NamedArgument) {
+	p.expect(tokentype.OpenParen, "Expected '(' after function name")
 
-	var args []ast.Expression
-	if p.at().Type == tokentype.CloseParen {
-		args = []ast.Expression{}
-	} else {
-		args = p.parseArgumentsList()
+	args := []ast.Expression{}
+	var namedArgs []ast.NamedArgument
+	if p.at().Type != tokentype.CloseParen {
+		args, namedArgs = p.parseArgumentsList()
 	}
 
-	p.expect(tokentype.CloseParen, fmt.Sprintf("Error on line %d: Expected ')' after function arguments", internal.Line()))
+	p.expect(tokentype.CloseParen, "Expected ')' after function arguments")
 
-	return args
+	return args, namedArgs
 }
 
-func (p *Parser) parseArgumentsList() []ast.Expression {
-	args := []ast.Expression{p.parseAssignmentExpression()}
+// parseArgumentsList parses the arguments of a call. An argument written as
+// `name: value` is passed by name; the ones after it must be too.
+func (p *Parser) parseArgumentsList() ([]ast.Expression, []ast.NamedArgument) {
+	args := []ast.Expression{}
+	var namedArgs []ast.NamedArgument
+	for {
+		if p.at().Type == tokentype.Identifier && p.peek().Type == tokentype.Colon {
+			named := p.parseNamedArgument()
+			for _, other := range namedArgs {
+				if other.Name == named.Name {
+					p.report(named.Pos(), fmt.Sprintf("Duplicate named argument %s", named.Name))
+				}
+			}
+			namedArgs = append(namedArgs, named)
+		} else if arg := p.parseAssignmentExpression(); len(namedArgs) > 0 {
+			p.report(arg.Pos(), "Positional argument follows a named argument")
+		} else {
+			args = append(args, arg)
+		}
 
-	for p.at().Type == tokentype.Comma {
+		if p.at().Type != tokentype.Comma {
+			return args, namedArgs
+		}
 		p.eat()
-		args = append(args, p.parseAssignmentExpression())
 	}
+}
 
-	return args
+// parseNamedArgument parses an argument passed by name, `name: value`.
+func (p *Parser) parseNamedArgument() ast.NamedArgument {
+	pos := p.position()
+	name := p.eat().Value
+	p.eat()
+	return ast.NamedArgument{
+		Position: pos,
+		Name:     name,
+		Value:    p.parseAssignmentExpression(),
+	}
 }
 
 func (p *Parser) parseMemberExpression() ast.Expression {
-	object := p.parsePrimaryExpression()
+	return p.parseMemberAccess(p.parsePrimaryExpression())
+}
 
+// parseMemberAccess parses the '.name' and '[expr]' accesses following
+// object, which may itself be a call.
+func (p *Parser) parseMemberAccess(object ast.Expression) ast.Expression {
 	for p.at().Type == tokentype.Dot || p.at().Type == tokentype.OpenBracket {
 		operator := p.eat()
 		var property ast.Expression
@@ -786,17 +1095,17 @@ func (p *Parser) parseMemberExpression() ast.Expression {
 			property = p.parsePrimaryExpression()
 
 			if property.Kind() != ast.IdentifierType {
-				fmt.Fprintln(os.Stderr, fmt.Sprintf("Error on line %d: Expected identifier after '.'", internal.Line()))
-				os.Exit(0)
+				p.fail("Expected identifier after '.'")
 				return nil
 			}
 		} else {
 			computed = true
 			property = p.parseExpression()
-			p.expect(tokentype.CloseBracket, fmt.Sprintf("Error on line %d: Expected ']' after computed property", internal.Line()))
+			p.expect(tokentype.CloseBracket, "Expected ']' after computed property")
 		}
 
 		object = &ast.MemberExpression{
+			Position: object.Pos(),
 			Object:   object,
 			Property: property,
 			Computed: computed,
@@ -807,33 +1116,41 @@ func (p *Parser) parseMemberExpression() ast.Expression {
 }
 
 func (p *Parser) parsePrimaryExpression() ast.Expression {
+	pos := p.position()
 	token := p.at().Type
 
 	switch token {
 	case tokentype.Identifier:
 		return &ast.Identifier{
-			Symbol: p.eat().Value,
+			Position: pos,
+			Symbol:   p.eat().Value,
 		}
 	case tokentype.Integer:
 		value, err := strconv.ParseInt(p.eat().Value, 10, 64)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(0)
+			p.failAt(pos, "%s", err.Error())
 			return nil
 		}
-		return &ast.NumericIntegerLiteral{Value: value}
+		return &ast.NumericIntegerLiteral{Position: pos, Value: value}
 	case tokentype.Float:
 		value, err := strconv.ParseFloat(p.eat().Value, 64)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(0)
+			p.failAt(pos, "%s", err.Error())
 			return nil
 		}
-		return &ast.NumericFloatLiteral{Value: value}
+		return &ast.NumericFloatLiteral{Position: pos, Value: value}
 	case tokentype.String:
 		return &ast.StringLiteral{
-			Value: p.eat().Value,
+			Position: pos,
+			Value:    p.eat().Value,
 		}
+	case tokentype.TemplateHead:
+		return p.parseTemplateLiteral()
+	case tokentype.OpenBracket:
+		return p.parseArrayLiteral()
+	case tokentype.TemplateMiddle, tokentype.TemplateTail:
+		p.fail("Expected expression before '}' in template string")
+		return nil
 	case tokentype.Whitespace:
 		p.eat()
 		return p.parsePrimaryExpression()
@@ -843,6 +1160,7 @@ func (p *Parser) parsePrimaryExpression() ast.Expression {
 		if p.at().Type == tokentype.CloseParen {
 			p.eat()
 			return &ast.TupleLiteral{
+				Position: pos,
 				Elements: []ast.Expression{},
 			}
 		}
@@ -859,48 +1177,87 @@ func (p *Parser) parsePrimaryExpression() ast.Expression {
 				}
 			}
 
-			p.expect(tokentype.CloseParen, fmt.Sprintf("Error on line %d: Expected closing parenthesis", internal.Line()))
+			p.expect(tokentype.CloseParen, "Expected closing parenthesis")
 			return &ast.TupleLiteral{
+				Position: pos,
 				Elements: elements,
 			}
 		}
 
-		p.expect(tokentype.CloseParen, fmt.Sprintf("Error on line %d: Expected closing parenthesis", internal.Line()))
+		p.expect(tokentype.CloseParen, "Expected closing parenthesis")
 		return value
 	case tokentype.UnaryOperator:
 		operator := p.eat().Value
 		value := p.parsePrimaryExpression()
 		return &ast.UnaryExpression{
+			Position: pos,
 			Operator: operator,
 			Value:    value,
 		}
 	case tokentype.Function:
 		return p.parseFunctionDeclaration()
 	default:
-		fmt.Fprintf(os.Stderr, "Error on line %d: Unexpected token found: %s", internal.Line(), p.at().Value)
-		os.Exit(0)
+		p.fail("Unexpected token found: %s", p.at().Value)
 		return nil
 	}
 }
 
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	template := &ast.TemplateLiteral{
+		Position: p.position(),
+		Strings:  []string{p.eat().Value},
+	}
+
+	for {
+		if p.at().Type == tokentype.TemplateMiddle || p.at().Type == tokentype.TemplateTail {
+			p.fail("Empty expression in template string")
+		}
+		template.Expressions = append(template.Expressions, p.parseExpression())
+
+		switch p.at().Type {
+		case tokentype.TemplateMiddle:
+			template.Strings = append(template.Strings, p.eat().Value)
+		case tokentype.TemplateTail:
+			template.Strings = append(template.Strings, p.eat().Value)
+			return template
+		default:
+			p.fail("Expected '}' after template expression")
+			return nil
+		}
+	}
+}
+
+func (p *Parser) position() ast.Position {
+	token := p.at()
+	return ast.Position{
+		File:   token.File,
+		Line:   token.Line,
+		Column: token.Column,
+	}
+}
+
 func (p *Parser) at() lexer.Token {
 	return p.tokens[0]
 }
 
 func (p *Parser) eat() lexer.Token {
 	prev := p.tokens[0]
-	p.tokens = p.tokens[1:]
+	if prev.Type != tokentype.EndOfFile {
+		p.tokens = p.tokens[1:]
+	}
 	return prev
 }
 
 func (p *Parser) peek() lexer.Token {
+	if len(p.tokens) < 2 {
+		return p.tokens[0]
+	}
 	return p.tokens[1]
 }
 
 func (p *Parser) expect(token tokentype.TokenType, message string) lexer.Token {
 	if p.at().Type != token {
-		fmt.Fprintln(os.Stderr, message)
-		os.Exit(0)
+		p.fail("%s", message)
 	}
 	return p.eat()
 }
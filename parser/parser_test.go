@@ -0,0 +1,89 @@
+package parser_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+const invalidSource = `let a = 1
+let = 2
+let b = 3
+return 4
+let c = 5 @
+let d = 6
+`
+
+func TestDiagnosticsFromOneFile(t *testing.T) {
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics("bad.jam", invalidSource)
+	want := []parser.Diagnostic{
+		{Position: ast.Position{File: "bad.jam", Line: 2, Column: 5}, Message: "Expected identifier name after let/const keyword"},
+		{Position: ast.Position{File: "bad.jam", Line: 4, Column: 1}, Message: "Return statement outside of function"},
+		{Position: ast.Position{File: "bad.jam", Line: 5, Column: 11}, Message: "Invalid character '@'"},
+	}
+	if !slices.Equal(diagnostics, want) {
+		t.Errorf("diagnostics = %v, want %v", diagnostics, want)
+	}
+
+	var declared []string
+	for _, statement := range program.Body {
+		if declaration, ok := statement.(*ast.VariableDeclaration); ok {
+			declared = append(declared, declaration.Identifier)
+		}
+	}
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(declared, want) {
+		t.Errorf("declared %v, want the statements around the errors %v", declared, want)
+	}
+}
+
+func TestProduceASTFails(t *testing.T) {
+	program, err := parser.NewParser().ProduceFileAST("bad.jam", invalidSource)
+	if len(program.Body) != 0 {
+		t.Errorf("program has %d statements, want none", len(program.Body))
+	}
+	var diagnostic parser.Diagnostic
+	if !errors.As(err, &diagnostic) || diagnostic.Position.Line != 2 {
+		t.Errorf("err = %v, want the syntax errors starting at line 2", err)
+	}
+
+	if _, err := parser.NewParser().ProduceAST("let a = 1"); err != nil {
+		t.Errorf("valid source failed: %v", err)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	source := "let a = 1\nfn f(x) {\n  return x + `v${a}`\n}\n  f(a)"
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics("pos.jam", source)
+	if len(diagnostics) > 0 {
+		t.Fatal(diagnostics)
+	}
+
+	function := program.Body[1].(*ast.FunctionDeclaration)
+	returned := function.Body[0].(*ast.ReturnStatement)
+	sum := returned.Value.(*ast.BinaryExpression)
+	template := sum.Right.(*ast.TemplateLiteral)
+	call := program.Body[2].(*ast.CallExpression)
+	nodes := []struct {
+		name string
+		node ast.Statement
+		want ast.Position
+	}{
+		{"program", &program, ast.Position{File: "pos.jam", Line: 1, Column: 1}},
+		{"declaration", program.Body[0], ast.Position{File: "pos.jam", Line: 1, Column: 1}},
+		{"function", function, ast.Position{File: "pos.jam", Line: 2, Column: 1}},
+		{"return", returned, ast.Position{File: "pos.jam", Line: 3, Column: 3}},
+		{"x", sum.Left, ast.Position{File: "pos.jam", Line: 3, Column: 10}},
+		{"template", template, ast.Position{File: "pos.jam", Line: 3, Column: 14}},
+		{"a in template", template.Expressions[0], ast.Position{File: "pos.jam", Line: 3, Column: 18}},
+		{"call", call, ast.Position{File: "pos.jam", Line: 5, Column: 3}},
+		{"argument", call.Args[0], ast.Position{File: "pos.jam", Line: 5, Column: 5}},
+	}
+	for _, node := range nodes {
+		if got := node.node.Pos(); got != node.want {
+			t.Errorf("%s is at %s, want %s", node.name, got, node.want)
+		}
+	}
+}
@@ -1,105 +1,488 @@
 package runtimelang
 
 import (
+	"cmp"
 	"fmt"
-	"os"
 	"slices"
+	"strings"
+
+	"github.com/Jamlie/Jamlang/ast"
 )
 
-func jamlangArrayPush(arr **[]RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+// jamlangArrayPush appends to arr in place and returns it, so that calls can
+// be chained.
+func jamlangArrayPush(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: push takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "push takes 1 argument")
 		}
 
-		newArray := append(**arr, args[0])
-		*arr = &newArray
-		return MakeArrayValue(newArray)
+		arr.Push(args[0])
+		return arr, nil
 	}, "push")
 }
 
-func jamlangArrayPop(arr []RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
-		if len(args) == 1 {
-			if args[0].Type() != I8 || args[0].Type() != I16 || args[0].Type() != I32 {
-				fmt.Fprintln(os.Stderr, "Error: pop takes a small int as an argument")
-				os.Exit(0)
-			}
-
-			index := int(args[0].(Int32Value).Value)
-			if index < 0 || index >= len(arr) {
-				fmt.Fprintln(os.Stderr, "Error: pop index out of bounds")
-				os.Exit(0)
-			}
+// jamlangArrayPop removes the last element of arr, or the one at the given
+// index, and returns it.
+func jamlangArrayPop(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) > 1 {
+			return nil, NewJamError(ArgumentError, "pop takes 0 or 1 arguments")
+		}
+		if len(arr.Values) == 0 {
+			return nil, NewJamError(IndexError, "pop on empty array")
+		}
+		if len(args) == 0 {
+			return arr.Pop(), nil
+		}
 
-			arr = append(arr[:index], arr[index+1:]...)
-		} else if len(args) == 0 {
-			arr = arr[:len(arr)-1]
+		index, ok := args[0].(IntValue)
+		if !ok {
+			return nil, NewJamError(TypeError, "pop takes an integer index")
+		}
+		i := index.GetInt()
+		if i < 0 || i >= len(arr.Values) {
+			return nil, NewJamError(IndexError, "pop index out of bounds")
 		}
 
-		return MakeArrayValue(arr)
+		value := arr.Values[i]
+		arr.Values = slices.Delete(arr.Values, i, i+1)
+		return value, nil
 	}, "pop")
 }
 
-func jamlangArrayShift(arr []RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
-		if len(arr) == 0 {
-			fmt.Fprintln(os.Stderr, "Error: shift on empty array")
-			os.Exit(0)
+// jamlangArrayShift removes the first element of arr and returns it.
+func jamlangArrayShift(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "shift takes 0 arguments")
 		}
-		arr = arr[1:]
-		return MakeArrayValue(arr)
+		if len(arr.Values) == 0 {
+			return nil, NewJamError(IndexError, "shift on empty array")
+		}
+
+		value := arr.Values[0]
+		arr.Values = slices.Delete(arr.Values, 0, 1)
+		return value, nil
 	}, "shift")
 }
 
-func jamlangArrayContains(arr []RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+func jamlangArrayContains(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: contains takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "contains takes 1 argument")
 		}
 
-		return MakeBoolValue(slices.Contains(arr, args[0]))
+		return MakeBoolValue(slices.ContainsFunc(arr.Values, func(value RuntimeValue) bool {
+			return equalValues(value, args[0], map[[2]any]bool{})
+		})), nil
 	}, "contains")
 }
 
-func jamlangArrayInsertInto(arr []RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+// jamlangArrayInsertInto inserts a value into arr in place before the given
+// index, which may be the length of arr to append, and returns arr.
+func jamlangArrayInsertInto(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 2 {
-			fmt.Fprintln(os.Stderr, "Error: insert takes 2 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "insert takes 2 arguments")
 		}
 
-		if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 {
-			fmt.Fprintln(os.Stderr, "Error: insert takes a number as an argument")
-			os.Exit(0)
+		index, ok := args[0].(IntValue)
+		if !ok {
+			return nil, NewJamError(TypeError, "insert takes a number as an argument")
 		}
-
-		index := int(args[0].(Int32Value).Value)
-		if index < 0 || index >= len(arr) {
-			fmt.Fprintln(os.Stderr, "Error: insert index out of bounds")
-			os.Exit(0)
+		i := index.GetInt()
+		if i < 0 || i > len(arr.Values) {
+			return nil, NewJamError(IndexError, "insert index out of bounds")
 		}
 
-		arr = append(arr[:index], append([]RuntimeValue{args[1]}, arr[index:]...)...)
-		return MakeArrayValue(arr)
+		arr.Values = slices.Insert(arr.Values, i, args[1])
+		return arr, nil
 	}, "insertInto")
 }
 
-func jamlangArrayPushAll(arr []RuntimeValue) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+// jamlangArrayPushAll appends the elements of another array to arr in place
+// and returns arr.
+func jamlangArrayPushAll(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: pushAll takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "pushAll takes 1 argument")
 		}
 
 		if args[0].Type() != Array {
-			fmt.Fprintln(os.Stderr, "Error: pushAll takes an array as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "pushAll takes an array as an argument")
 		}
 
-		arr = append(arr, args[0].(ArrayValue).Values...)
-		return MakeArrayValue(arr)
+		arr.Values = append(arr.Values, args[0].(*ArrayValue).Values...)
+		return arr, nil
 	}, "pushAll")
 }
+
+// jamlangClone copies value, an array or object, with the arrays and objects
+// in it, so that changing the copy leaves value as it was.
+func jamlangClone(value RuntimeValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "clone takes 0 arguments")
+		}
+
+		return value.Clone(), nil
+	}, "clone")
+}
+
+// callback calls fn, passed to an array method, with as many of args as it
+// takes, so that both fn(x) and fn(x, i, arr) can be given to map.
+// Callbacks get the array itself and may change it, so the methods read its
+// elements as they go and stop at the length it had when they were called.
+func callback(fn RuntimeValue, env Environment, args ...RuntimeValue) (RuntimeValue, error) {
+	if function, ok := fn.(FunctionValue); ok && len(args) > len(function.Parameters) {
+		params := function.Parameters
+		if len(params) == 0 || !params[len(params)-1].IsRest {
+			args = args[:len(params)]
+		}
+	}
+	return callValue(fn, args, &env, ast.Position{})
+}
+
+func isCallable(value RuntimeValue) bool {
+	return value.Type() == Function || value.Type() == NativeFunction
+}
+
+// predicate calls fn with an element of arr, its index and arr, and requires
+// it to return a bool.
+func predicate(name string, fn RuntimeValue, env Environment, arr *ArrayValue, i int) (bool, error) {
+	result, err := callback(fn, env, arr.Values[i], MakeInt32Value(int32(i)), arr)
+	if err != nil {
+		return false, err
+	}
+	boolean, ok := result.(BoolValue)
+	if !ok {
+		return false, NewJamErrorf(TypeError, "%s callback must return a bool, got %s", name, result.Type())
+	}
+	return boolean.Value, nil
+}
+
+func jamlangArrayMap(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "map takes a function as an argument")
+		}
+
+		mapped := make([]RuntimeValue, 0, len(arr.Values))
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			result, err := callback(args[0], env, arr.Values[i], MakeInt32Value(int32(i)), arr)
+			if err != nil {
+				return nil, err
+			}
+			mapped = append(mapped, result)
+		}
+		return MakeArrayValue(mapped), nil
+	}, "map")
+}
+
+func jamlangArrayFilter(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "filter takes a function as an argument")
+		}
+
+		filtered := []RuntimeValue{}
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			value := arr.Values[i]
+			keep, err := predicate("filter", args[0], env, arr, i)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				filtered = append(filtered, value)
+			}
+		}
+		return MakeArrayValue(filtered), nil
+	}, "filter")
+}
+
+func jamlangArrayReduce(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if (len(args) != 1 && len(args) != 2) || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "reduce takes a function and an optional initial value")
+		}
+
+		start := 0
+		var accumulator RuntimeValue
+		if len(args) == 2 {
+			accumulator = args[1]
+		} else if len(arr.Values) == 0 {
+			return nil, NewJamError(TypeError, "reduce of empty array with no initial value")
+		} else {
+			accumulator = arr.Values[0]
+			start = 1
+		}
+
+		for i, length := start, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			result, err := callback(args[0], env, accumulator, arr.Values[i], MakeInt32Value(int32(i)), arr)
+			if err != nil {
+				return nil, err
+			}
+			accumulator = result
+		}
+		return accumulator, nil
+	}, "reduce")
+}
+
+func jamlangArrayFind(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "find takes a function as an argument")
+		}
+
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			value := arr.Values[i]
+			found, err := predicate("find", args[0], env, arr, i)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return value, nil
+			}
+		}
+		return MakeNullValue(), nil
+	}, "find")
+}
+
+func jamlangArrayFindIndex(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "findIndex takes a function as an argument")
+		}
+
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			found, err := predicate("findIndex", args[0], env, arr, i)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return MakeInt32Value(int32(i)), nil
+			}
+		}
+		return MakeInt32Value(-1), nil
+	}, "findIndex")
+}
+
+func jamlangArraySome(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "some takes a function as an argument")
+		}
+
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			found, err := predicate("some", args[0], env, arr, i)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return MakeBoolValue(true), nil
+			}
+		}
+		return MakeBoolValue(false), nil
+	}, "some")
+}
+
+func jamlangArrayEvery(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "every takes a function as an argument")
+		}
+
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			ok, err := predicate("every", args[0], env, arr, i)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return MakeBoolValue(false), nil
+			}
+		}
+		return MakeBoolValue(true), nil
+	}, "every")
+}
+
+func jamlangArrayForEach(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 || !isCallable(args[0]) {
+			return nil, NewJamError(ArgumentError, "forEach takes a function as an argument")
+		}
+
+		for i, length := 0, len(arr.Values); i < min(length, len(arr.Values)); i++ {
+			if _, err := callback(args[0], env, arr.Values[i], MakeInt32Value(int32(i)), arr); err != nil {
+				return nil, err
+			}
+		}
+		return MakeNullValue(), nil
+	}, "forEach")
+}
+
+// sliceIndex turns index, which counts from the end when negative, into an
+// offset clamped to [0, length].
+func sliceIndex(index, length int) int {
+	if index < 0 {
+		index += length
+	}
+	return max(0, min(index, length))
+}
+
+func jamlangArraySlice(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, NewJamError(ArgumentError, "slice takes 1 or 2 arguments")
+		}
+
+		start, ok := args[0].(IntValue)
+		if !ok {
+			return nil, NewJamError(TypeError, "slice takes integers as arguments")
+		}
+		from, to := sliceIndex(start.GetInt(), len(arr.Values)), len(arr.Values)
+		if len(args) == 2 {
+			end, ok := args[1].(IntValue)
+			if !ok {
+				return nil, NewJamError(TypeError, "slice takes integers as arguments")
+			}
+			to = sliceIndex(end.GetInt(), len(arr.Values))
+		}
+
+		sliced := []RuntimeValue{}
+		if from < to {
+			sliced = append(sliced, arr.Values[from:to]...)
+		}
+		return MakeArrayValue(sliced), nil
+	}, "slice")
+}
+
+func jamlangArrayConcat(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		concatenated := append([]RuntimeValue{}, arr.Values...)
+		for _, arg := range args {
+			if array, ok := arg.(*ArrayValue); ok {
+				concatenated = append(concatenated, array.Values...)
+			} else {
+				concatenated = append(concatenated, arg)
+			}
+		}
+		return MakeArrayValue(concatenated), nil
+	}, "concat")
+}
+
+func jamlangArrayReverse(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "reverse takes 0 arguments")
+		}
+
+		slices.Reverse(arr.Values)
+		return arr, nil
+	}, "reverse")
+}
+
+func jamlangArrayJoin(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		separator := ","
+		if len(args) > 1 {
+			return nil, NewJamError(ArgumentError, "join takes 0 or 1 arguments")
+		}
+		if len(args) == 1 {
+			if args[0].Type() != String {
+				return nil, NewJamError(TypeError, "join takes a string as an argument")
+			}
+			separator = args[0].(StringValue).Value
+		}
+
+		var sb strings.Builder
+		for i, value := range arr.Values {
+			if i > 0 {
+				sb.WriteString(separator)
+			}
+			fmt.Fprint(&sb, value.Get())
+		}
+		return MakeStringValue(sb.String()), nil
+	}, "join")
+}
+
+func jamlangArrayIndexOf(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "indexOf takes 1 argument")
+		}
+
+		for i, value := range arr.Values {
+			if equalValues(value, args[0], map[[2]any]bool{}) {
+				return MakeInt32Value(int32(i)), nil
+			}
+		}
+		return MakeInt32Value(-1), nil
+	}, "indexOf")
+}
+
+// jamlangArraySort sorts arr in place. The comparator, if given, returns a
+// negative number when its first argument goes first, a positive number when
+// it goes last and 0 when they are equal; otherwise numbers and strings are
+// sorted in ascending order.
+func jamlangArraySort(arr *ArrayValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) > 1 || (len(args) == 1 && !isCallable(args[0])) {
+			return nil, NewJamError(ArgumentError, "sort takes an optional comparator function")
+		}
+
+		compare := compareValues
+		if len(args) == 1 {
+			compare = func(a, b RuntimeValue) (int, error) {
+				result, err := callback(args[0], env, a, b)
+				if err != nil {
+					return 0, err
+				}
+				order, ok := numberValue(result)
+				if !ok {
+					return 0, NewJamErrorf(TypeError, "sort comparator must return a number, got %s", result.Type())
+				}
+				return cmp.Compare(order, 0), nil
+			}
+		}
+
+		var sortErr error
+		slices.SortStableFunc(arr.Values, func(a, b RuntimeValue) int {
+			if sortErr != nil {
+				return 0
+			}
+			order, err := compare(a, b)
+			sortErr = err
+			return order
+		})
+		if sortErr != nil {
+			return nil, sortErr
+		}
+		return arr, nil
+	}, "sort")
+}
+
+// compareValues orders two numbers or two strings.
+func compareValues(a, b RuntimeValue) (int, error) {
+	if x, ok := numberValue(a); ok {
+		if y, ok := numberValue(b); ok {
+			return cmp.Compare(x, y), nil
+		}
+	}
+	if x, ok := a.(StringValue); ok {
+		if y, ok := b.(StringValue); ok {
+			return strings.Compare(x.Value, y.Value), nil
+		}
+	}
+	return 0, NewJamErrorf(TypeError, "Cannot sort %s and %s without a comparator", a.Type(), b.Type())
+}
+
+func numberValue(value RuntimeValue) (float64, bool) {
+	switch value := value.(type) {
+	case IntValue:
+		return float64(value.GetInt()), true
+	case FloatValue:
+		return value.GetFloat(), true
+	}
+	return 0, false
+}
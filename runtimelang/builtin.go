@@ -2,11 +2,12 @@ package runtimelang
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,75 +15,80 @@ import (
 	"github.com/Jamlie/Jamlang/parser"
 )
 
-func jamlangPrintln(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangPrintln(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	stdout := environment.engine.stdout()
 	for _, arg := range args {
-		fmt.Print(arg.Get())
+		fmt.Fprint(stdout, arg.Get())
 	}
-	fmt.Println()
-	return MakeNullValue()
+	fmt.Fprintln(stdout)
+	return MakeNullValue(), nil
 }
 
-func jamlangPrint(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangPrint(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	stdout := environment.engine.stdout()
 	for _, arg := range args {
-		fmt.Print(arg.Get())
+		fmt.Fprint(stdout, arg.Get())
 	}
-	return MakeNullValue()
+	return MakeNullValue(), nil
 }
 
-func jamlangSleep(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangSleep(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: sleep takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "sleep takes 1 argument")
 	}
 
 	if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 {
-		fmt.Fprintln(os.Stderr, "Error: sleep takes a number - time in milliseconds")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "sleep takes a number - time in milliseconds")
 	}
 
-	time.Sleep(time.Duration(args[0].(IntValue).GetInt()) * time.Millisecond)
-	return MakeNullValue()
+	if err := environment.engine.wait(time.Duration(args[0].(IntValue).GetInt()) * time.Millisecond); err != nil {
+		return nil, err
+	}
+	return MakeNullValue(), nil
 }
 
-func jamlangTypeof(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangTypeof(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: typeof takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "typeof takes 1 argument")
+	}
+
+	if object, ok := args[0].(ObjectValue); ok && object.Class != nil {
+		return MakeStringValue(object.Class.Name), nil
 	}
 
-	return MakeStringValue(string(args[0].Type()))
+	return MakeStringValue(string(args[0].Type())), nil
 }
 
-func jamlangExit(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangExit(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: exit takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "exit takes 1 argument")
 	}
 
 	if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 {
-		fmt.Fprintln(os.Stderr, "Error: exit takes a number - exit code")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "exit takes a number - exit code")
 	}
 
-	os.Exit(args[0].(IntValue).GetInt())
-	return MakeNullValue()
+	if err := environment.engine.require(ProcessExit, "exit"); err != nil {
+		return nil, err
+	}
+	return nil, &ExitError{Code: args[0].(IntValue).GetInt()}
 }
 
-func jamlangInput(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangInput(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: input takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "input takes 1 argument")
 	}
 
-	fmt.Print(args[0].Get())
-	scanner := bufio.NewReader(os.Stdin)
-	input, err := scanner.ReadString('\n')
+	fmt.Fprint(environment.engine.stdout(), args[0].Get())
+	input, err := environment.engine.readLine()
 	input = strings.Trim(input, "\n")
+	if stopsRun(err) {
+		return nil, err
+	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: reading input")
-		os.Exit(0)
+		return nil, NewJamError(IOError, "reading input")
 	}
-	return MakeStringValue(input)
+	return MakeStringValue(input), nil
 }
 
 // func jamlangLen(args []RuntimeValue, environment Environment) RuntimeValue {
@@ -143,43 +149,38 @@ func jamlangInput(args []RuntimeValue, environment Environment) RuntimeValue {
 //     return MakeArrayValue(goArray[:len(goArray)-1])
 // }
 
-func jamlangCopy(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangCopy(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: copy takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "copy takes 1 argument")
 	}
 
 	if args[0].Type() == Array {
-		goArray := ToGoArrayValue(args[0].(ArrayValue))
+		goArray := ToGoArrayValue(args[0].(*ArrayValue))
 		goArrayCopy := make([]RuntimeValue, len(goArray))
 		copy(goArrayCopy, goArray)
-		return MakeArrayValue(goArrayCopy)
+		return MakeArrayValue(goArrayCopy), nil
 	} else if args[0].Type() == Tuple {
 		goTuple := ToGoTupleValue(args[0].(TupleValue))
 		goTupleCopy := make([]RuntimeValue, len(goTuple))
 		copy(goTupleCopy, goTuple)
-		return MakeTupleValue(goTupleCopy)
+		return MakeTupleValue(goTupleCopy), nil
 	} else {
-		fmt.Fprintln(os.Stderr, "Error: copy takes an array or tuple")
-		os.Exit(0)
-		return MakeNullValue()
+		return nil, NewJamError(TypeError, "copy takes an array or tuple")
 	}
 }
 
-func jamlangArray(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangArray(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: array takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "array takes 1 argument")
 	}
 
 	if args[0].Type() == Tuple {
 		goTuple := ToGoTupleValue(args[0].(TupleValue))
-		return MakeArrayValue(goTuple)
+		return MakeArrayValue(slices.Clone(goTuple)), nil
 	}
 
 	if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 {
-		fmt.Fprintln(os.Stderr, "Error: array takes a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "array takes a number")
 	}
 
 	var size int
@@ -191,33 +192,32 @@ func jamlangArray(args []RuntimeValue, environment Environment) RuntimeValue {
 	case I32:
 		size = int(ToGoNumberValue(args[0].(Int32Value)))
 	default:
-		fmt.Fprintln(os.Stderr, "Error: array takes a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "array takes a number")
 	}
 
 	if size < 0 {
-		fmt.Fprintln(os.Stderr, "Error: array takes a positive number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "array takes a positive number")
+	}
+	if err := environment.engine.checkSize(size); err != nil {
+		return nil, err
 	}
 
 	goArray := make([]RuntimeValue, size)
-	return MakeArrayValue(goArray)
+	return MakeArrayValue(goArray), nil
 }
 
-func jamlangTuple(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangTuple(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: tuple takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "tuple takes 1 argument")
 	}
 
 	if args[0].Type() == Array {
-		goArray := ToGoArrayValue(args[0].(ArrayValue))
-		return MakeTupleValue(goArray)
+		goArray := ToGoArrayValue(args[0].(*ArrayValue))
+		return MakeTupleValue(slices.Clone(goArray)), nil
 	}
 
 	if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 {
-		fmt.Fprintln(os.Stderr, "Error: tuple takes a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "tuple takes a number")
 	}
 
 	var size int
@@ -229,153 +229,155 @@ func jamlangTuple(args []RuntimeValue, environment Environment) RuntimeValue {
 	case I32:
 		size = int(ToGoNumberValue(args[0].(Int32Value)))
 	default:
-		fmt.Fprintln(os.Stderr, "Error: tuple takes a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "tuple takes a number")
 	}
 
 	if size < 0 {
-		fmt.Fprintln(os.Stderr, "Error: tuple takes a positive number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "tuple takes a positive number")
+	}
+	if err := environment.engine.checkSize(size); err != nil {
+		return nil, err
 	}
 
 	goArray := make([]RuntimeValue, size)
-	return MakeTupleValue(goArray)
+	return MakeTupleValue(goArray), nil
 }
 
-func jamlangToString(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToString(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: string takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "string takes 1 argument")
 	}
 
-	return MakeStringValue(args[0].ToString())
+	return MakeStringValue(args[0].ToString()), nil
+}
+
+// parseError reports that text, given to the conversion to typeName, is not
+// a number of that type.
+func parseError(text, typeName string, err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	return NewJamErrorf(TypeError, "cannot parse %q as %s: %v", text, typeName, err)
 }
 
-func jamlangToUint32(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToUint32(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: uint32 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "uint32 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int8Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int8Value))))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int16Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int16Value))))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int32Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int32Value))))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int64Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Int64Value))))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Float32Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Float32Value))))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Float64Value)))))
+		return MakeInt64Value(int64(uint32(ToGoNumberValue(args[0].(Float64Value))))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: uint32 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "uint32 takes a string or a number")
 	}
 
 	uintString := args[0].ToString()
 
 	uintUint, err := strconv.ParseUint(uintString, 10, 32)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: uint32 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(uintString, "uint32", err)
 	}
 
-	return MakeInt64Value(int64(uintUint))
+	return MakeInt64Value(int64(uintUint)), nil
 }
 
-func jamlangToUint64(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToUint64(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: uint64 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "uint64 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int8Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int8Value))))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int16Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int16Value))))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int32Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int32Value))))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int64Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Int64Value))))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Float32Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Float32Value))))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Float64Value)))))
+		return MakeFloat32Value(float32(uint64(ToGoNumberValue(args[0].(Float64Value))))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: uint64 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "uint64 takes a string or a number")
 	}
 
 	uintString := args[0].ToString()
 
 	uintUint, err := strconv.ParseUint(uintString, 10, 64)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: uint64 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(uintString, "uint64", err)
 	}
 
-	return MakeFloat32Value(float32(uintUint))
+	return MakeFloat32Value(float32(uintUint)), nil
 }
 
-func jamlangToInt8(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToInt8(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: int8 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "int8 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeInt8Value(int8(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: int8 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "int8 takes a string or a number")
 	}
 
 	getInt := args[0].Get()
@@ -384,46 +386,43 @@ func jamlangToInt8(args []RuntimeValue, environment Environment) RuntimeValue {
 
 	intInt, err := strconv.ParseInt(intString, 10, 8)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: int8 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(intString, "int8", err)
 	}
 
-	return MakeInt8Value(int8(intInt))
+	return MakeInt8Value(int8(intInt)), nil
 }
 
-func jamlangToInt16(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToInt16(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: int16 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "int16 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeInt16Value(int16(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: int16 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "int16 takes a string or a number")
 	}
 
 	getInt := args[0].Get()
@@ -432,46 +431,43 @@ func jamlangToInt16(args []RuntimeValue, environment Environment) RuntimeValue {
 
 	intInt, err := strconv.ParseInt(intString, 10, 16)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: int16 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(intString, "int16", err)
 	}
 
-	return MakeInt16Value(int16(intInt))
+	return MakeInt16Value(int16(intInt)), nil
 }
 
-func jamlangToInt32(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToInt32(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: int32 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "int32 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeInt32Value(int32(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: int32 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "int32 takes a string or a number")
 	}
 
 	getInt := args[0].Get()
@@ -480,46 +476,43 @@ func jamlangToInt32(args []RuntimeValue, environment Environment) RuntimeValue {
 
 	intInt, err := strconv.ParseInt(intString, 10, 32)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: int32 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(intString, "int32", err)
 	}
 
-	return MakeInt32Value(int32(intInt))
+	return MakeInt32Value(int32(intInt)), nil
 }
 
-func jamlangToInt64(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToInt64(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: int64 takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "int64 takes 1 argument")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeInt64Value(int64(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: int64 takes a string or a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "int64 takes a string or a number")
 	}
 
 	getInt := args[0].Get()
@@ -528,46 +521,43 @@ func jamlangToInt64(args []RuntimeValue, environment Environment) RuntimeValue {
 
 	intInt, err := strconv.ParseInt(intString, 10, 64)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: int64 takes a string or a number")
-		os.Exit(0)
+		return nil, parseError(intString, "int64", err)
 	}
 
-	return MakeInt64Value(int64(intInt))
+	return MakeInt64Value(int64(intInt)), nil
 }
 
-func jamlangToFloat32(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToFloat32(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: float32 takes a string or a numebr")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "float32 takes a string or a number")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeFloat32Value(float32(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: float32 takes a string or a numebr")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "float32 takes a string or a number")
 	}
 
 	getFloat := args[0].Get()
@@ -576,46 +566,43 @@ func jamlangToFloat32(args []RuntimeValue, environment Environment) RuntimeValue
 
 	floatFloat, err := strconv.ParseFloat(floatString, 32)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: float32 takes a string or a numebr")
-		os.Exit(0)
+		return nil, parseError(floatString, "float32", err)
 	}
 
-	return MakeFloat32Value(float32(floatFloat))
+	return MakeFloat32Value(float32(floatFloat)), nil
 }
 
-func jamlangToFloat64(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangToFloat64(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: float64 takes a string or a numebr")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "float64 takes a string or a number")
 	}
 
 	if args[0].Type() == I8 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int8Value)))), nil
 	}
 
 	if args[0].Type() == I16 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int16Value)))), nil
 	}
 
 	if args[0].Type() == I32 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int32Value)))), nil
 	}
 
 	if args[0].Type() == I64 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Int64Value)))), nil
 	}
 
 	if args[0].Type() == F32 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Float32Value)))), nil
 	}
 
 	if args[0].Type() == F64 {
-		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeFloat64Value(float64(ToGoNumberValue(args[0].(Float64Value)))), nil
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: float64 takes a string or a numebr")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "float64 takes a string or a number")
 	}
 
 	getFloat := args[0].Get()
@@ -624,504 +611,478 @@ func jamlangToFloat64(args []RuntimeValue, environment Environment) RuntimeValue
 
 	floatFloat, err := strconv.ParseFloat(floatString, 64)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: float64 takes a string or a numebr")
-		os.Exit(0)
+		return nil, parseError(floatString, "float64", err)
 	}
 
-	return MakeFloat64Value(float64(floatFloat))
+	return MakeFloat64Value(float64(floatFloat)), nil
 }
 
-func jamlangHex(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangHex(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: hex takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "hex takes 1 argument")
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: hex takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "hex takes a string")
 	}
 
 	hexString := args[0].ToString()
 
 	hexInt, err := strconv.ParseInt(hexString, 16, 64)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: hex takes a string")
-		os.Exit(0)
+		return nil, parseError(hexString, "hexadecimal i64", err)
 	}
 
-	return MakeInt64Value(int64(hexInt))
+	return MakeInt64Value(int64(hexInt)), nil
 }
 
-func jamlangCurrentTime(args []RuntimeValue, environment Environment) RuntimeValue {
-	return MakeInt64Value(time.Now().UnixMicro())
+func jamlangCurrentTime(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	return MakeInt64Value(time.Now().UnixMicro()), nil
 }
 
-func jamlangBitwiseNot(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangBitwiseNot(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.NOT takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Bitwise.NOT takes 1 argument")
 	}
 
 	if args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 && args[0].Type() != F32 && args[0].Type() != F64 {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.NOT takes a number")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Bitwise.NOT takes a number")
 	}
 
 	switch args[0].Type() {
 	case I8:
-		return MakeInt8Value(^int8(ToGoNumberValue(args[0].(Int8Value))))
+		return MakeInt8Value(^int8(ToGoNumberValue(args[0].(Int8Value)))), nil
 	case I16:
-		return MakeInt16Value(^int16(ToGoNumberValue(args[0].(Int16Value))))
+		return MakeInt16Value(^int16(ToGoNumberValue(args[0].(Int16Value)))), nil
 	case I32:
-		return MakeInt32Value(^int32(ToGoNumberValue(args[0].(Int32Value))))
+		return MakeInt32Value(^int32(ToGoNumberValue(args[0].(Int32Value)))), nil
 	case I64:
-		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Int64Value))))
+		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Int64Value)))), nil
 	case F32:
-		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Float32Value))))
+		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Float32Value)))), nil
 	case F64:
-		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Float64Value))))
+		return MakeInt64Value(^int64(ToGoNumberValue(args[0].(Float64Value)))), nil
 	default:
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.NOT takes a number")
-		os.Exit(0)
-		return nil
+		return nil, NewJamError(TypeError, "Bitwise.NOT takes a number")
 	}
 }
 
-func jamlangBitwiseAnd(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangBitwiseAnd(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 arguments")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Bitwise.AND takes 2 arguments")
 	}
 
 	if (args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 && args[0].Type() != F32 && args[0].Type() != F64) || (args[1].Type() != I8 && args[1].Type() != I16 && args[1].Type() != I32 && args[1].Type() != I64 && args[1].Type() != F32 && args[1].Type() != F64) {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.AND and takes 2 numbers")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Bitwise.AND and takes 2 numbers")
 	}
 
 	switch args[0].Type() {
 	case I8:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) & ToGoNumberValue(args[1].(Int8Value))))
+			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) & ToGoNumberValue(args[1].(Int8Value)))), nil
 		case I16:
-			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) & ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) & int8(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) & int8(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) & int8(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) & int8(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	case I16:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) & ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) & ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) & ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) & ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) & ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) & ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) & int16(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	case I32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) & ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) & ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) & ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) & int32(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	case I64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) & int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	case F32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) & int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) & int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) & int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) & int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) & int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) & int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) & int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	case F64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) & int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) & int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) & int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) & int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) & int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) & int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) & int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) & int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) & int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) & int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) & int64(ToGoNumberValue(args[1].(Float64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) & int64(ToGoNumberValue(args[1].(Float64Value)))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 		}
 	default:
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.AND takes 2 numbers")
-		os.Exit(0)
-		return nil
+		return nil, NewJamError(TypeError, "Bitwise.AND takes 2 numbers")
 	}
 }
 
-func jamlangBitwiseOr(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangBitwiseOr(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 arguments")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Bitwise.OR takes 2 arguments")
 	}
 
 	if (args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 && args[0].Type() != F32 && args[0].Type() != F64) || (args[1].Type() != I8 && args[1].Type() != I16 && args[1].Type() != I32 && args[1].Type() != I64 && args[1].Type() != F32 && args[1].Type() != F64) {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 	}
 
 	switch args[0].Type() {
 	case I8:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) | ToGoNumberValue(args[1].(Int8Value))))
+			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) | ToGoNumberValue(args[1].(Int8Value)))), nil
 		case I16:
-			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) | ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) | int8(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) | int8(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) | int8(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) | int8(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	case I16:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) | ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) | ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) | ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) | ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) | ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) | ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) | int16(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	case I32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) | ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) | ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) | ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) | int32(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	case I64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) | int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	case F32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) | int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) | int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) | int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) | int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) | int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) | int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) | int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	case F64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) | int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) | int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) | int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) | int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) | int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) | int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) | int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) | int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) | int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) | int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) | int64(ToGoNumberValue(args[1].(Float64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) | int64(ToGoNumberValue(args[1].(Float64Value)))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 		}
 	default:
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.OR takes 2 numbers")
-		os.Exit(0)
-		return nil
+		return nil, NewJamError(TypeError, "Bitwise.OR takes 2 numbers")
 	}
 }
 
-func jamlangBitwiseXor(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangBitwiseXor(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 arguments")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Bitwise.XOR takes 2 arguments")
 	}
 
 	if (args[0].Type() != I8 && args[0].Type() != I16 && args[0].Type() != I32 && args[0].Type() != I64 && args[0].Type() != F32 && args[0].Type() != F64) || (args[1].Type() != I8 && args[1].Type() != I16 && args[1].Type() != I32 && args[1].Type() != I64 && args[1].Type() != F32 && args[1].Type() != F64) {
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 	}
 
 	switch args[0].Type() {
 	case I8:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) ^ ToGoNumberValue(args[1].(Int8Value))))
+			return MakeInt8Value(int8(ToGoNumberValue(args[0].(Int8Value)) ^ ToGoNumberValue(args[1].(Int8Value)))), nil
 		case I16:
-			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(int16(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int8Value))) ^ ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) ^ int8(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) ^ int8(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) ^ int8(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int8Value)) ^ int8(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	case I16:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) ^ ToGoNumberValue(args[1].(Int16Value))))
+			return MakeInt16Value(int16(ToGoNumberValue(args[0].(Int16Value)) ^ ToGoNumberValue(args[1].(Int16Value)))), nil
 		case I32:
-			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) ^ ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(int32(ToGoNumberValue(args[0].(Int16Value))) ^ ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) ^ ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int16Value))) ^ ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int16Value)) ^ int16(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	case I32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ ToGoNumberValue(args[1].(Int32Value))))
+			return MakeInt32Value(int32(ToGoNumberValue(args[0].(Int32Value)) ^ ToGoNumberValue(args[1].(Int32Value)))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) ^ ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Int32Value))) ^ ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int32Value)) ^ int32(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	case I64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ ToGoNumberValue(args[1].(Int64Value))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ ToGoNumberValue(args[1].(Int64Value)))), nil
 		case F32:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(ToGoNumberValue(args[0].(Int64Value)) ^ int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	case F32:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) ^ int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float32Value))) ^ int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) ^ int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float32Value))) ^ int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) ^ int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float32Value))) ^ int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Float64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float32Value))) ^ int64(ToGoNumberValue(args[1].(Float64Value))))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	case F64:
 		switch args[1].Type() {
 		case I8:
-			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) ^ int8(ToGoNumberValue(args[1].(Int8Value)))))
+			return MakeInt64Value(int64(int8(ToGoNumberValue(args[0].(Float64Value))) ^ int8(ToGoNumberValue(args[1].(Int8Value))))), nil
 		case I16:
-			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) ^ int16(ToGoNumberValue(args[1].(Int16Value)))))
+			return MakeInt64Value(int64(int16(ToGoNumberValue(args[0].(Float64Value))) ^ int16(ToGoNumberValue(args[1].(Int16Value))))), nil
 		case I32:
-			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) ^ int32(ToGoNumberValue(args[1].(Int32Value)))))
+			return MakeInt64Value(int64(int32(ToGoNumberValue(args[0].(Float64Value))) ^ int32(ToGoNumberValue(args[1].(Int32Value))))), nil
 		case I64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) ^ int64(ToGoNumberValue(args[1].(Int64Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) ^ int64(ToGoNumberValue(args[1].(Int64Value))))), nil
 		case F32:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) ^ int64(ToGoNumberValue(args[1].(Float32Value)))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value))) ^ int64(ToGoNumberValue(args[1].(Float32Value))))), nil
 		case F64:
-			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) ^ int64(ToGoNumberValue(args[1].(Float64Value))))
+			return MakeInt64Value(int64(int64(ToGoNumberValue(args[0].(Float64Value)))) ^ int64(ToGoNumberValue(args[1].(Float64Value)))), nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 		}
 	default:
-		fmt.Fprintln(os.Stderr, "Error: Bitwise.XOR takes 2 numbers")
-		os.Exit(0)
-		return nil
+		return nil, NewJamError(TypeError, "Bitwise.XOR takes 2 numbers")
 	}
 }
 
-func jamlangEval(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangEval(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: eval takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "eval takes 1 argument")
+	}
+
+	if err := environment.engine.require(Eval, "eval"); err != nil {
+		return nil, err
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "eval takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "eval takes a string")
 	}
 
 	code := args[0].ToString()
-	program := parser.NewParser().ProduceAST(code)
-	newEnvironment := CreateGlobalEnvironment()
-	Evaluate(&program, *newEnvironment)
-	return MakeNullValue()
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics("<eval>", code)
+	if len(diagnostics) > 0 {
+		return nil, syntaxErrorFrom(diagnostics)
+	}
+	newEnvironment := newGlobalEnvironment(environment.engine)
+	if _, err := Evaluate(&program, newEnvironment); err != nil {
+		return nil, err
+	}
+	return MakeNullValue(), nil
 }
 
-func jamlangOpen(args []RuntimeValue, environment Environment) RuntimeValue {
-	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: open takes 1 argument")
-		os.Exit(0)
+// openModes maps the modes open takes to the flags to open files with and the
+// capability that needs.
+var openModes = map[string]struct {
+	flag       int
+	capability Capability
+}{
+	"a": {os.O_APPEND | os.O_CREATE | os.O_WRONLY, FSWrite},
+	"w": {os.O_TRUNC | os.O_CREATE | os.O_WRONLY, FSWrite},
+	"r": {os.O_RDONLY, FSRead},
+}
+
+func jamlangOpen(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, NewJamError(ArgumentError, "open takes 1 or 2 arguments")
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: open takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "open takes a string")
+	}
+
+	mode := "a"
+	if len(args) == 2 {
+		if args[1].Type() != String {
+			return nil, NewJamError(TypeError, "open takes a string as the mode")
+		}
+		mode = args[1].(StringValue).Value
+	}
+	openMode, ok := openModes[mode]
+	if !ok {
+		return nil, NewJamErrorf(ArgumentError, "open mode must be \"a\", \"w\" or \"r\", got %q", mode)
 	}
 
 	filename := args[0].(StringValue).Value
+	if err := environment.engine.requirePath(openMode.capability, "open", filename); err != nil {
+		return nil, err
+	}
 
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(filename, openMode.flag, 0644)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't open file")
-		os.Exit(0)
+		return nil, NewJamErrorf(IOError, "couldn't open file: %s", err)
 	}
 
 	var properties = make(map[string]RuntimeValue)
@@ -1129,275 +1090,258 @@ func jamlangOpen(args []RuntimeValue, environment Environment) RuntimeValue {
 	properties["name"] = MakeStringValue(file.Name())
 	properties["file"] = MakeFileValue(file.Name(), file)
 	properties["append"] = jamlangAppend(file)
-	properties["read"] = jamlangRead(&file)
+	properties["read"] = jamlangRead(bufio.NewReader(file))
 
-	return MakeObjectValue(properties)
+	return MakeObjectValue(properties), nil
 }
 
 func jamlangClose(file *os.File) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: close doesn't take any argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "close doesn't take any argument")
 		}
 
 		err := file.Close()
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error: couldn't close file")
-			os.Exit(0)
+			return nil, NewJamError(IOError, "couldn't close file")
 		}
-		return MakeNullValue()
+		return MakeNullValue(), nil
 	}, "close")
 }
 
 func jamlangAppend(file *os.File) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: write takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "write takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: write takes a string")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "write takes a string")
 		}
 
 		_, err := file.WriteString(args[0].(StringValue).Get().(string))
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error: couldn't write to file")
-			os.Exit(0)
+			return nil, NewJamError(IOError, "couldn't write to file")
 		}
-		return MakeNullValue()
+		return MakeNullValue(), nil
 	}, "append")
 }
 
-func jamlangRead(file **os.File) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangRead(reader *bufio.Reader) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: read doesn't take any argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "read doesn't take any argument")
 		}
 
-		reader := bufio.NewReader(*file)
 		line, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error: couldn't read from file")
-			os.Exit(0)
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, NewJamError(IOError, "couldn't read from file")
 		}
-		return MakeStringValue(line)
+		return MakeStringValue(line), nil
 	}, "read")
 }
 
-func jamlangObjectKeys(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangObjectKeys(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Object.keys takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Object.keys takes 1 argument")
 	}
 
 	if args[0].Type() != "object" {
-		fmt.Fprintln(os.Stderr, "Object.keys takes an object")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Object.keys takes an object")
 	}
 
 	keys := make([]RuntimeValue, 0)
-	for key := range args[0].(ObjectValue).Properties {
+	for _, key := range args[0].(ObjectValue).Keys() {
 		keys = append(keys, MakeStringValue(key))
 	}
 
-	return MakeArrayValue(keys)
+	return MakeArrayValue(keys), nil
 }
 
-func jamlangObjectValues(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangObjectValues(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Object.values takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Object.values takes 1 argument")
 	}
 
 	if args[0].Type() != "object" {
-		fmt.Fprintln(os.Stderr, "Object.values takes an object")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Object.values takes an object")
 	}
 
 	values := make([]RuntimeValue, 0)
-	for _, value := range args[0].(ObjectValue).Properties {
-		values = append(values, value)
+	object := args[0].(ObjectValue)
+	for _, key := range object.Keys() {
+		values = append(values, object.Properties[key])
 	}
 
-	return MakeArrayValue(values)
+	return MakeArrayValue(values), nil
 }
 
-func jamlangObjectHas(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangObjectHas(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Object.has takes 2 arguments")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Object.has takes 2 arguments")
 	}
 
 	if args[0].Type() != "object" {
-		fmt.Fprintln(os.Stderr, "Object.has takes an object")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Object.has takes an object")
 	}
 
 	if args[1].Type() != String {
-		fmt.Fprintln(os.Stderr, "Object.has takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Object.has takes a string")
 	}
 
 	_, ok := args[0].(ObjectValue).Properties[args[1].(StringValue).Value]
-	return MakeBoolValue(ok)
+	return MakeBoolValue(ok), nil
 }
 
-func jamlangHttpGet(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangObjectClassOf(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: http.get takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "Object.classOf takes 1 argument")
 	}
 
-	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: http.get takes a string")
-		os.Exit(0)
+	object, ok := args[0].(ObjectValue)
+	if !ok || object.Class == nil {
+		return MakeNullValue(), nil
 	}
 
-	resp, err := http.Get(args[0].(StringValue).Value)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't get url")
-		os.Exit(0)
+	return object.Class, nil
+}
+
+func jamlangObjectInstanceOf(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 2 {
+		return nil, NewJamError(ArgumentError, "Object.instanceOf takes 2 arguments")
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't read response")
-		os.Exit(0)
+	class, ok := args[1].(*ClassValue)
+	if !ok {
+		return nil, NewJamError(TypeError, "Object.instanceOf takes a class as its second argument")
 	}
 
-	return MakeStringValue(string(body))
+	object, ok := args[0].(ObjectValue)
+	if !ok || object.Class == nil {
+		return MakeBoolValue(false), nil
+	}
+
+	return MakeBoolValue(object.Class.IsSubclassOf(class)), nil
 }
 
-func jamlangHttpPost(args []RuntimeValue, environment Environment) RuntimeValue {
-	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Error: http.post takes 2 arguments")
-		os.Exit(0)
+func jamlangHttpGet(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 {
+		return nil, NewJamError(ArgumentError, "http.get takes 1 argument")
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: http.post takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "http.get takes a string")
 	}
 
-	if args[1].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: http.post takes a string")
-		os.Exit(0)
+	if err := environment.engine.require(NetClient, "http.get"); err != nil {
+		return nil, err
 	}
 
-	resp, err := http.Post(args[0].(StringValue).Value, "application/json", strings.NewReader(args[1].(StringValue).Get().(string)))
+	ctx, cancel := environment.engine.waitContext()
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, args[0].(StringValue).Value, nil)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't post url")
-		os.Exit(0)
+		return nil, NewJamErrorf(IOError, "couldn't get url: %s", err)
 	}
+	resp, err := http.DefaultClient.Do(request)
+	if ctx.Err() != nil {
+		return nil, environment.engine.waitError(ctx)
+	}
+	if err != nil {
+		return nil, NewJamErrorf(IOError, "couldn't get url: %s", err)
+	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't read response")
-		os.Exit(0)
+		return nil, NewJamErrorf(IOError, "couldn't read response: %s", err)
 	}
 
-	return MakeStringValue(string(body))
+	return MakeStringValue(string(body)), nil
 }
 
-func jamlangHttpListen(args []RuntimeValue, environment Environment) RuntimeValue {
-	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: http.listen takes 1 argument")
-		os.Exit(0)
+func jamlangHttpPost(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 2 {
+		return nil, NewJamError(ArgumentError, "http.post takes 2 arguments")
 	}
 
 	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: http.listen takes a string")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "http.post takes a string")
 	}
 
-	err := http.ListenAndServe(args[0].(StringValue).Value, nil)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't listen on port")
-		os.Exit(0)
+	if args[1].Type() != String {
+		return nil, NewJamError(TypeError, "http.post takes a string")
 	}
 
-	return MakeNullValue()
-}
+	if err := environment.engine.require(NetClient, "http.post"); err != nil {
+		return nil, err
+	}
 
-func jamlangHttpNew(args []RuntimeValue, environment Environment) RuntimeValue {
-	if len(args) != 0 {
-		fmt.Fprintln(os.Stderr, "Error: http.new takes 0 arguments")
-		os.Exit(0)
+	ctx, cancel := environment.engine.waitContext()
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, args[0].(StringValue).Value, strings.NewReader(args[1].(StringValue).Get().(string)))
+	if err != nil {
+		return nil, NewJamErrorf(IOError, "couldn't post url: %s", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(request)
+	if ctx.Err() != nil {
+		return nil, environment.engine.waitError(ctx)
+	}
+	if err != nil {
+		return nil, NewJamErrorf(IOError, "couldn't post url: %s", err)
 	}
+	defer resp.Body.Close()
 
-	httpObject := make(map[string]RuntimeValue)
-	httpObject["listen"] = MakeNativeFunction(jamlangHttpListen, "listen")
-	httpObject["get"] = MakeNativeFunction(jamlangHttpGet, "get")
-	httpObject["post"] = MakeNativeFunction(jamlangHttpPost, "post")
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewJamErrorf(IOError, "couldn't read response: %s", err)
+	}
 
-	return MakeObjectValue(httpObject)
+	return MakeStringValue(string(body)), nil
 }
 
-func jamlangJsonParse(args []RuntimeValue, environment Environment) RuntimeValue {
+func jamlangHttpListen(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: json.parse takes 1 argument")
-		os.Exit(0)
+		return nil, NewJamError(ArgumentError, "http.listen takes 1 argument")
 	}
 
-	if args[0].Type() != String {
-		fmt.Fprintln(os.Stderr, "Error: json.parse takes a string")
-		os.Exit(0)
+	if err := environment.engine.require(NetServer, "http.listen"); err != nil {
+		return nil, err
 	}
 
-	var data any
-	err := json.Unmarshal([]byte(args[0].(StringValue).Value), &data)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't parse json")
-		os.Exit(0)
+	if args[0].Type() != String {
+		return nil, NewJamError(TypeError, "http.listen takes a string")
 	}
 
-	return MakeJSONValue(data)
-}
+	ctx, cancel := environment.engine.waitContext()
+	defer cancel()
+	server := &http.Server{Addr: args[0].(StringValue).Value, Handler: http.NewServeMux()}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
 
-func jamlangJsonStringify(args []RuntimeValue, environment Environment) RuntimeValue {
-	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "Error: json.stringify takes 1 argument")
-		os.Exit(0)
+	select {
+	case <-served:
+		return nil, NewJamError(IOError, "couldn't listen on port")
+	case <-ctx.Done():
+		server.Close()
+		return nil, environment.engine.waitError(ctx)
 	}
+}
 
-	var data []byte
-	var err error
-	switch args[0].Type() {
-	case String:
-		data, err = json.Marshal(args[0].(StringValue).Value)
-	case I8:
-		data, err = json.Marshal(args[0].(Int8Value).Value)
-	case I16:
-		data, err = json.Marshal(args[0].(Int16Value).Value)
-	case I32:
-		data, err = json.Marshal(args[0].(Int32Value).Value)
-	case I64:
-		data, err = json.Marshal(args[0].(Int64Value).Value)
-	case F32:
- 		data, err = json.Marshal(args[0].(Float32Value).Value)
-	case F64:
-		data, err = json.Marshal(args[0].(Float64Value).Value)
-	case Bool:
-		data, err = json.Marshal(args[0].(BoolValue).Value)
-	case Null:
-		data, err = json.Marshal(args[0].(NullValue).Value)
-	case Array:
-		data, err = json.Marshal(args[0].(ArrayValue).Values)
-	case Object:
-		data, err = json.Marshal(args[0].(ObjectValue).Properties)
-	default:
-		fmt.Fprintln(os.Stderr, "Error: json.stringify takes a string, number, boolean, null, array or object")
-		os.Exit(0)
+func jamlangHttpNew(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 0 {
+		return nil, NewJamError(ArgumentError, "http.new takes 0 arguments")
 	}
 
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error: couldn't stringify json")
-		os.Exit(0)
-	}
+	httpObject := make(map[string]RuntimeValue)
+	httpObject["listen"] = MakeNativeFunction(jamlangHttpListen, "listen")
+	httpObject["get"] = MakeNativeFunction(jamlangHttpGet, "get")
+	httpObject["post"] = MakeNativeFunction(jamlangHttpPost, "post")
+	httpObject["tryGet"] = MakeNativeFunction(jamlangTry(jamlangHttpGet), "tryGet")
+	httpObject["tryPost"] = MakeNativeFunction(jamlangTry(jamlangHttpPost), "tryPost")
 
-	return MakeStringValue(string(data))
+	return MakeObjectValue(httpObject), nil
 }
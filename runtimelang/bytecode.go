@@ -0,0 +1,201 @@
+package runtimelang
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/Jamlie/Jamlang/ast"
+)
+
+// Opcode is a single VM instruction. Operands follow the opcode as big-endian
+// uint32 values; the comment on each opcode lists them.
+type Opcode byte
+
+// operandSize is the size in bytes of an operand.
+const operandSize = 4
+
+const (
+	OpConstant Opcode = iota // constant
+	OpNull
+	OpPop // pops the value of a statement and keeps it as the last value
+	OpDiscard
+	OpGetVar      // name
+	OpSetVar      // name
+	OpDeclare     // node of the variable declaration
+	OpGetProperty // name
+	OpGetIndex
+	OpSetMember // name of the variable the member belongs to
+	OpBinary    // name of the operator
+	OpUnary     // name of the operator
+	OpStep      // name of the operator, ++ or --
+	OpNot
+	OpCheckBool       // name of the error message
+	OpJump            // address
+	OpJumpIfFalse     // address, name of the error message
+	OpJumpIfFalseKeep // address, name of the error message
+	OpJumpIfTrueKeep  // address, name of the error message
+	OpPushScope
+	OpPopScope
+	OpNextIteration
+	OpCall      // argument count
+	OpCallNamed // node of the call, which has named arguments
+	OpClosure   // function
+	OpClass     // class
+	OpEval      // node evaluated by the tree-walking evaluator
+	OpImport    // node of the import statement
+	OpIterInit
+	OpIterNext    // address to jump to when the iteration ends
+	OpBindForEach // node of the foreach statement
+	OpIterEnd
+	OpArray    // element count
+	OpTuple    // element count
+	OpObject   // node of the object literal
+	OpTemplate // node of the template literal
+	OpReturn
+	OpThrow
+	OpSetupTry // address of the catch block, address of the finally block
+	OpPopTry
+	OpDeclareCatch // name
+	OpFinally
+	OpEndFinally
+)
+
+var opcodeNames = [...]string{
+	OpConstant:        "CONSTANT",
+	OpNull:            "NULL",
+	OpPop:             "POP",
+	OpDiscard:         "DISCARD",
+	OpGetVar:          "GET_VAR",
+	OpSetVar:          "SET_VAR",
+	OpDeclare:         "DECLARE",
+	OpGetProperty:     "GET_PROPERTY",
+	OpGetIndex:        "GET_INDEX",
+	OpSetMember:       "SET_MEMBER",
+	OpBinary:          "BINARY",
+	OpUnary:           "UNARY",
+	OpStep:            "STEP",
+	OpNot:             "NOT",
+	OpCheckBool:       "CHECK_BOOL",
+	OpJump:            "JUMP",
+	OpJumpIfFalse:     "JUMP_IF_FALSE",
+	OpJumpIfFalseKeep: "JUMP_IF_FALSE_KEEP",
+	OpJumpIfTrueKeep:  "JUMP_IF_TRUE_KEEP",
+	OpPushScope:       "PUSH_SCOPE",
+	OpPopScope:        "POP_SCOPE",
+	OpNextIteration:   "NEXT_ITERATION",
+	OpCall:            "CALL",
+	OpCallNamed:       "CALL_NAMED",
+	OpClosure:         "CLOSURE",
+	OpClass:           "CLASS",
+	OpEval:            "EVAL",
+	OpImport:          "IMPORT",
+	OpIterInit:        "ITER_INIT",
+	OpIterNext:        "ITER_NEXT",
+	OpBindForEach:     "BIND_FOREACH",
+	OpIterEnd:         "ITER_END",
+	OpArray:           "ARRAY",
+	OpTuple:           "TUPLE",
+	OpObject:          "OBJECT",
+	OpTemplate:        "TEMPLATE",
+	OpReturn:          "RETURN",
+	OpThrow:           "THROW",
+	OpSetupTry:        "SETUP_TRY",
+	OpPopTry:          "POP_TRY",
+	OpDeclareCatch:    "DECLARE_CATCH",
+	OpFinally:         "FINALLY",
+	OpEndFinally:      "END_FINALLY",
+}
+
+// operandCounts holds how many operands follow each opcode.
+var operandCounts = [...]int{
+	OpConstant:        1,
+	OpGetVar:          1,
+	OpSetVar:          1,
+	OpDeclare:         1,
+	OpGetProperty:     1,
+	OpSetMember:       1,
+	OpBinary:          1,
+	OpUnary:           1,
+	OpStep:            1,
+	OpCheckBool:       1,
+	OpJump:            1,
+	OpJumpIfFalse:     2,
+	OpJumpIfFalseKeep: 2,
+	OpJumpIfTrueKeep:  2,
+	OpCall:            1,
+	OpCallNamed:       1,
+	OpClosure:         1,
+	OpClass:           1,
+	OpEval:            1,
+	OpImport:          1,
+	OpIterNext:        1,
+	OpBindForEach:     1,
+	OpArray:           1,
+	OpTuple:           1,
+	OpObject:          1,
+	OpTemplate:        1,
+	OpSetupTry:        2,
+	OpDeclareCatch:    1,
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opcodeNames) {
+		return opcodeNames[op]
+	}
+	return fmt.Sprintf("OP_%d", op)
+}
+
+// Chunk is the compiled form of a program or of a function body.
+type Chunk struct {
+	Code []byte
+	// Positions holds the source position of the instruction starting at
+	// each offset of Code.
+	Positions []ast.Position
+	Constants []RuntimeValue
+	Names     []string
+	// Nodes are the AST nodes instructions refer to, for declarations and
+	// for the few constructs the VM leaves to the tree-walking evaluator.
+	Nodes     []ast.Statement
+	Functions []CompiledFunction
+	Classes   []CompiledClass
+}
+
+// CompiledFunction is a function declaration together with its compiled
+// body.
+type CompiledFunction struct {
+	Declaration *ast.FunctionDeclaration
+	Chunk       *Chunk
+}
+
+// CompiledClass is a class declaration together with the compiled bodies of
+// its methods, keyed by method name.
+type CompiledClass struct {
+	Declaration *ast.ClassDeclaration
+	Methods     map[string]*Chunk
+}
+
+func (c *Chunk) operand(offset int) int {
+	return int(binary.BigEndian.Uint32(c.Code[offset:]))
+}
+
+// Disassemble returns a human readable listing of the instructions in c.
+func (c *Chunk) Disassemble() string {
+	var sb strings.Builder
+	for offset := 0; offset < len(c.Code); {
+		op := Opcode(c.Code[offset])
+		fmt.Fprintf(&sb, "%04d %-18s", offset, op)
+		for i := 0; i < operandCounts[op]; i++ {
+			fmt.Fprintf(&sb, " %d", c.operand(offset+1+operandSize*i))
+		}
+		switch op {
+		case OpConstant:
+			fmt.Fprintf(&sb, " (%v)", c.Constants[c.operand(offset+1)].Get())
+		case OpGetVar, OpSetVar, OpGetProperty, OpSetMember, OpBinary, OpUnary, OpStep, OpDeclareCatch:
+			fmt.Fprintf(&sb, " (%s)", c.Names[c.operand(offset+1)])
+		}
+		sb.WriteString("\n")
+		offset += 1 + operandSize*operandCounts[op]
+	}
+	return sb.String()
+}
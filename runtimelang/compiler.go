@@ -0,0 +1,655 @@
+package runtimelang
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/Jamlie/Jamlang/ast"
+)
+
+// compiler turns the statements of a program or of a function body into a
+// Chunk. Every statement leaves its value on the stack, which OpPop then
+// records, so that a chunk ends with the value of its last statement like
+// EvaluateProgram does.
+type compiler struct {
+	chunk    *Chunk
+	position ast.Position
+	// scopes counts the scopes pushed by the code compiled so far, so that
+	// break and continue know how many to pop.
+	scopes int
+	loops  []*loopContext
+	tries  []*tryContext
+	err    error
+}
+
+type loopContext struct {
+	// breakScopes and continueScopes are the scope counts to return to when
+	// leaving the loop or starting its next iteration.
+	breakScopes    int
+	continueScopes int
+	// tries is how many try statements enclosed the loop.
+	tries     int
+	breaks    []int
+	continues []int
+}
+
+// tryContext is a try statement whose try or catch block is being compiled.
+// Code leaving it with return, break or continue removes its handler and
+// runs its finally block first.
+type tryContext struct {
+	scopes  int
+	handler bool
+	finally []ast.Statement
+}
+
+// Compile compiles program to bytecode for Execute.
+func Compile(program ast.Program) (*Chunk, error) {
+	return compileStatements(program.Body)
+}
+
+func compileStatements(body []ast.Statement) (*Chunk, error) {
+	c := &compiler{chunk: &Chunk{}}
+	for _, statement := range body {
+		c.statement(statement)
+		c.emit(OpPop)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.chunk, nil
+}
+
+func (c *compiler) fail(err *JamError) {
+	if c.err == nil {
+		c.err = withPosition(err, c.position)
+	}
+}
+
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	offset := len(c.chunk.Code)
+	c.chunk.Code = append(c.chunk.Code, byte(op))
+	c.chunk.Positions = append(c.chunk.Positions, c.position)
+	for _, operand := range operands {
+		if operand > math.MaxUint32 {
+			c.fail(NewJamError(RuntimeError, "Program is too large to compile"))
+		}
+		c.chunk.Code = binary.BigEndian.AppendUint32(c.chunk.Code, uint32(operand))
+		for i := 0; i < operandSize; i++ {
+			c.chunk.Positions = append(c.chunk.Positions, c.position)
+		}
+	}
+	return offset
+}
+
+// emitJump emits a jump whose address is filled in later by patchJump.
+func (c *compiler) emitJump(op Opcode, operands ...int) int {
+	return c.emit(op, append([]int{0}, operands...)...)
+}
+
+func (c *compiler) patchJump(offset int) {
+	c.patchJumpTo(offset, len(c.chunk.Code))
+}
+
+func (c *compiler) patchJumpTo(offset, target int) {
+	c.patchOperand(offset, 0, target)
+}
+
+// patchOperand sets operand i of the instruction at offset.
+func (c *compiler) patchOperand(offset, i, value int) {
+	if value > math.MaxUint32 {
+		c.fail(NewJamError(RuntimeError, "Program is too large to compile"))
+	}
+	binary.BigEndian.PutUint32(c.chunk.Code[offset+1+operandSize*i:], uint32(value))
+}
+
+func (c *compiler) constant(value RuntimeValue) int {
+	c.chunk.Constants = append(c.chunk.Constants, value)
+	return len(c.chunk.Constants) - 1
+}
+
+func (c *compiler) name(name string) int {
+	for i, existing := range c.chunk.Names {
+		if existing == name {
+			return i
+		}
+	}
+	c.chunk.Names = append(c.chunk.Names, name)
+	return len(c.chunk.Names) - 1
+}
+
+func (c *compiler) node(node ast.Statement) int {
+	c.chunk.Nodes = append(c.chunk.Nodes, node)
+	return len(c.chunk.Nodes) - 1
+}
+
+// at sets the position recorded for the instructions emitted next and
+// returns a function restoring the previous one.
+func (c *compiler) at(node ast.Statement) func() {
+	previous := c.position
+	c.position = node.Pos()
+	return func() { c.position = previous }
+}
+
+// statement compiles statement so that it leaves exactly one value on the
+// stack.
+func (c *compiler) statement(statement ast.Statement) {
+	defer c.at(statement)()
+
+	switch statement := statement.(type) {
+	case *ast.Comment:
+		c.emit(OpNull)
+	case *ast.VariableDeclaration:
+		c.expression(statement.Value)
+		c.emit(OpDeclare, c.node(statement))
+	case *ast.FunctionDeclaration:
+		c.function(statement)
+	case *ast.ClassDeclaration:
+		c.class(statement)
+	case *ast.TypeDeclaration:
+		c.emit(OpEval, c.node(statement))
+	case *ast.ImportStatement:
+		c.emit(OpImport, c.node(statement))
+	case *ast.ReturnStatement:
+		c.expression(statement.Value)
+		c.leaveTries(0)
+		c.emit(OpReturn)
+	case *ast.ThrowStatement:
+		c.expression(statement.Value)
+		c.emit(OpThrow)
+	case *ast.TryStatement:
+		c.tryStatement(statement)
+	case *ast.BreakStatement:
+		c.jumpOutOfLoop("break")
+	case *ast.ContinueStatement:
+		c.jumpOutOfLoop("continue")
+	case *ast.ConditionalStatement:
+		c.conditional(statement)
+	case *ast.WhileStatement:
+		c.while(statement)
+	case *ast.LoopStatement:
+		c.loop(statement)
+	case *ast.ForStatement:
+		c.forStatement(statement)
+	case *ast.ForEachStatement:
+		c.forEach(statement)
+	default:
+		c.expression(statement)
+	}
+}
+
+func (c *compiler) expression(expr ast.Statement) {
+	defer c.at(expr)()
+
+	switch expr := expr.(type) {
+	case *ast.NumericIntegerLiteral, *ast.NumericFloatLiteral, *ast.StringLiteral:
+		// Literals do not look anything up, so the evaluator can turn
+		// them into values once, at compile time.
+		value, err := evaluate(expr, &Environment{})
+		if err != nil {
+			c.fail(AsJamError(err))
+			return
+		}
+		c.emit(OpConstant, c.constant(value))
+	case *ast.NullLiteral:
+		c.emit(OpNull)
+	case *ast.Identifier:
+		c.emit(OpGetVar, c.name(expr.Symbol))
+	case *ast.BinaryExpression:
+		c.expression(expr.Left)
+		c.expression(expr.Right)
+		c.emit(OpBinary, c.name(expr.Operator))
+	case *ast.UnaryExpression:
+		c.unary(expr)
+	case *ast.LogicalExpression:
+		c.logical(expr)
+	case *ast.AssignmentExpression:
+		c.assignment(expr)
+	case *ast.MemberExpression:
+		c.expression(expr.Object)
+		if expr.Computed {
+			c.expression(expr.Property)
+			c.emit(OpGetIndex)
+		} else {
+			c.emit(OpGetProperty, c.name(expr.Property.(*ast.Identifier).Symbol))
+		}
+	case *ast.CallExpression:
+		for _, arg := range expr.Args {
+			c.expression(arg)
+		}
+		for _, arg := range expr.NamedArgs {
+			c.expression(arg.Value)
+		}
+		c.expression(expr.Caller)
+		if len(expr.NamedArgs) > 0 {
+			c.emit(OpCallNamed, c.node(expr))
+		} else {
+			c.emit(OpCall, len(expr.Args))
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range expr.Elements {
+			c.expression(element)
+		}
+		c.emit(OpArray, len(expr.Elements))
+	case *ast.TupleLiteral:
+		for _, element := range expr.Elements {
+			c.expression(element)
+		}
+		c.emit(OpTuple, len(expr.Elements))
+	case *ast.TemplateLiteral:
+		for _, expression := range expr.Expressions {
+			c.expression(expression)
+		}
+		c.emit(OpTemplate, c.node(expr))
+	case *ast.ObjectLiteral:
+		for _, property := range expr.Properties {
+			if property.Value != nil {
+				c.expression(property.Value)
+			} else {
+				c.emit(OpGetVar, c.name(property.Key))
+			}
+		}
+		c.emit(OpObject, c.node(expr))
+	case *ast.FunctionDeclaration:
+		c.function(expr)
+	default:
+		c.emit(OpEval, c.node(expr))
+	}
+}
+
+func (c *compiler) unary(expr *ast.UnaryExpression) {
+	switch expr.Operator {
+	case "++", "--":
+		identifier, ok := expr.Value.(*ast.Identifier)
+		if !ok {
+			c.emit(OpEval, c.node(expr))
+			return
+		}
+		c.emit(OpGetVar, c.name(identifier.Symbol))
+		c.emit(OpStep, c.name(expr.Operator))
+		c.emit(OpSetVar, c.name(identifier.Symbol))
+	default:
+		c.expression(expr.Value)
+		c.emit(OpUnary, c.name(expr.Operator))
+	}
+}
+
+func (c *compiler) logical(expr *ast.LogicalExpression) {
+	switch expr.Operator {
+	case "and", "or":
+		message := c.name(expr.Operator + " operator can only be applied to boolean values")
+		jump := OpJumpIfFalseKeep
+		if expr.Operator == "or" {
+			jump = OpJumpIfTrueKeep
+		}
+
+		c.expression(expr.Left)
+		end := c.emitJump(jump, message)
+		c.emit(OpDiscard)
+		c.expression(expr.Right)
+		c.emit(OpCheckBool, message)
+		c.patchJump(end)
+	case "not":
+		c.expression(expr.Right)
+		c.emit(OpNot)
+	default:
+		c.emit(OpEval, c.node(expr))
+	}
+}
+
+func (c *compiler) assignment(expr *ast.AssignmentExpression) {
+	switch assignee := expr.Assignee.(type) {
+	case *ast.Identifier:
+		c.expression(expr.Value)
+		c.emit(OpSetVar, c.name(assignee.Symbol))
+	case *ast.MemberExpression:
+		c.expression(assignee.Object)
+		if assignee.Computed {
+			c.expression(assignee.Property)
+		} else if property, ok := assignee.Property.(*ast.Identifier); ok {
+			c.emit(OpConstant, c.constant(MakeStringValue(property.Symbol)))
+		} else {
+			c.emit(OpEval, c.node(expr))
+			return
+		}
+		c.expression(expr.Value)
+		c.emit(OpSetMember, c.name(memberRoot(assignee)))
+	default:
+		c.emit(OpEval, c.node(expr))
+	}
+}
+
+func (c *compiler) function(declaration *ast.FunctionDeclaration) {
+	chunk, err := compileStatements(declaration.Body)
+	if err != nil {
+		c.fail(AsJamError(err))
+		return
+	}
+	c.chunk.Functions = append(c.chunk.Functions, CompiledFunction{
+		Declaration: declaration,
+		Chunk:       chunk,
+	})
+	c.emit(OpClosure, len(c.chunk.Functions)-1)
+}
+
+func (c *compiler) class(declaration *ast.ClassDeclaration) {
+	class := CompiledClass{
+		Declaration: declaration,
+		Methods:     make(map[string]*Chunk),
+	}
+	for _, member := range declaration.Body {
+		method, ok := member.(*ast.FunctionDeclaration)
+		if !ok {
+			continue
+		}
+		chunk, err := compileStatements(method.Body)
+		if err != nil {
+			c.fail(AsJamError(err))
+			return
+		}
+		class.Methods[method.Name] = chunk
+	}
+	c.chunk.Classes = append(c.chunk.Classes, class)
+	c.emit(OpClass, len(c.chunk.Classes)-1)
+}
+
+// declaresNames reports whether running body directly declares a name,
+// which is when it needs a scope of its own.
+func declaresNames(body []ast.Statement) bool {
+	for _, statement := range body {
+		switch statement.(type) {
+		case *ast.VariableDeclaration, *ast.FunctionDeclaration, *ast.ClassDeclaration,
+			*ast.TypeDeclaration, *ast.ForStatement:
+			return true
+		}
+	}
+	return false
+}
+
+// block compiles body in a scope of its own, leaving no value on the stack.
+func (c *compiler) block(body []ast.Statement) {
+	scoped := declaresNames(body)
+	if scoped {
+		c.pushScope()
+	}
+	for _, statement := range body {
+		c.statement(statement)
+		c.emit(OpPop)
+	}
+	if scoped {
+		c.popScope()
+	}
+}
+
+func (c *compiler) pushScope() {
+	c.emit(OpPushScope)
+	c.scopes++
+}
+
+func (c *compiler) popScope() {
+	c.emit(OpPopScope)
+	c.scopes--
+}
+
+func (c *compiler) conditional(statement *ast.ConditionalStatement) {
+	var ends []int
+
+	c.expression(statement.Condition)
+	next := c.emitJump(OpJumpIfFalse, c.name("if statement condition must be a boolean"))
+	c.block(statement.Body)
+	ends = append(ends, c.emitJump(OpJump))
+
+	for i, condition := range statement.ElseIfConditions {
+		c.patchJump(next)
+		c.expression(condition)
+		next = c.emitJump(OpJumpIfFalse, c.name("elseif statement condition must be a boolean"))
+		c.block(statement.ElseIfBodies[i])
+		ends = append(ends, c.emitJump(OpJump))
+	}
+
+	c.patchJump(next)
+	c.block(statement.Alternate)
+	for _, end := range ends {
+		c.patchJump(end)
+	}
+	c.emit(OpNull)
+}
+
+func (c *compiler) beginLoop(breakScopes, continueScopes int) *loopContext {
+	loop := &loopContext{
+		breakScopes:    breakScopes,
+		continueScopes: continueScopes,
+		tries:          len(c.tries),
+	}
+	c.loops = append(c.loops, loop)
+	return loop
+}
+
+// endLoop points the break and continue jumps of the innermost loop at
+// breakTarget and continueTarget.
+func (c *compiler) endLoop(breakTarget, continueTarget int) {
+	loop := c.loops[len(c.loops)-1]
+	c.loops = c.loops[:len(c.loops)-1]
+	for _, jump := range loop.breaks {
+		c.patchJumpTo(jump, breakTarget)
+	}
+	for _, jump := range loop.continues {
+		c.patchJumpTo(jump, continueTarget)
+	}
+}
+
+func (c *compiler) jumpOutOfLoop(keyword string) {
+	if len(c.loops) == 0 {
+		c.fail(NewJamErrorf(SyntaxError, "%s statement outside of loop", keyword))
+		return
+	}
+
+	loop := c.loops[len(c.loops)-1]
+	scopes := loop.breakScopes
+	if keyword == "continue" {
+		scopes = loop.continueScopes
+	}
+	for i := c.leaveTries(loop.tries); i > scopes; i-- {
+		c.emit(OpPopScope)
+	}
+
+	jump := c.emitJump(OpJump)
+	if keyword == "continue" {
+		loop.continues = append(loop.continues, jump)
+	} else {
+		loop.breaks = append(loop.breaks, jump)
+	}
+	// The jump leaves the statement, but the code after it still expects
+	// the statement to have pushed a value.
+	c.emit(OpNull)
+}
+
+func (c *compiler) while(statement *ast.WhileStatement) {
+	start := len(c.chunk.Code)
+	c.expression(statement.Condition)
+	exit := c.emitJump(OpJumpIfFalse, c.name("while statement condition must be a boolean"))
+
+	c.beginLoop(c.scopes, c.scopes)
+	c.block(statement.Body)
+	c.patchJumpTo(c.emitJump(OpJump), start)
+
+	c.patchJump(exit)
+	c.endLoop(len(c.chunk.Code), start)
+	c.emit(OpNull)
+}
+
+func (c *compiler) loop(statement *ast.LoopStatement) {
+	start := len(c.chunk.Code)
+
+	c.beginLoop(c.scopes, c.scopes)
+	c.block(statement.Body)
+	c.patchJumpTo(c.emitJump(OpJump), start)
+
+	c.endLoop(len(c.chunk.Code), start)
+	c.emit(OpNull)
+}
+
+func (c *compiler) forStatement(statement *ast.ForStatement) {
+	init, ok := statement.Init.(*ast.VariableDeclaration)
+	if !ok {
+		c.fail(NewJamError(SyntaxError, "for loop must start with a variable declaration"))
+		return
+	}
+
+	// Like EvaluateForStatement, the loop variable lives in a scope of its
+	// own, which is copied for every iteration of a body that makes closures.
+	outer := c.scopes
+	c.pushScope()
+	c.statement(init)
+	c.emit(OpDiscard)
+
+	scoped := declaresNames(statement.Body)
+	loop := c.scopes
+	start := len(c.chunk.Code)
+	if scoped {
+		c.pushScope()
+	}
+
+	exit := -1
+	if statement.Condition != nil {
+		c.expression(statement.Condition)
+		exit = c.emitJump(OpJumpIfFalse, c.name("for loop condition must be a boolean value"))
+	}
+
+	c.beginLoop(outer, loop)
+	for _, body := range statement.Body {
+		c.statement(body)
+		c.emit(OpPop)
+	}
+	if scoped {
+		c.popScope()
+	}
+
+	update := len(c.chunk.Code)
+	if makesClosures(statement.Body) {
+		c.emit(OpNextIteration)
+	}
+	if statement.Update != nil {
+		c.expression(statement.Update)
+		c.emit(OpDiscard)
+	}
+	c.patchJumpTo(c.emitJump(OpJump), start)
+
+	if exit >= 0 {
+		c.patchJump(exit)
+		if scoped {
+			c.emit(OpPopScope)
+		}
+	}
+	c.popScope()
+	end := len(c.chunk.Code)
+	c.endLoop(end, update)
+	c.emit(OpNull)
+}
+
+func (c *compiler) forEach(statement *ast.ForEachStatement) {
+	c.expression(statement.Collection)
+	c.emit(OpIterInit)
+
+	start := len(c.chunk.Code)
+	exit := c.emitJump(OpIterNext)
+
+	outer := c.scopes
+	c.pushScope()
+	c.emit(OpBindForEach, c.node(statement))
+
+	c.beginLoop(outer, outer)
+	for _, body := range statement.Body {
+		c.statement(body)
+		c.emit(OpPop)
+	}
+	c.popScope()
+	c.patchJumpTo(c.emitJump(OpJump), start)
+
+	c.patchJump(exit)
+	c.endLoop(len(c.chunk.Code), start)
+	c.emit(OpIterEnd)
+	c.emit(OpNull)
+}
+
+// leaveTries emits the code that leaves the try statements entered after the
+// first depth ones, innermost first, and returns the scope count it leaves
+// the VM at.
+func (c *compiler) leaveTries(depth int) int {
+	scopes := c.scopes
+	for i := len(c.tries) - 1; i >= depth; i-- {
+		try := c.tries[i]
+		for ; scopes > try.scopes; scopes-- {
+			c.emit(OpPopScope)
+		}
+		if try.handler {
+			c.emit(OpPopTry)
+		}
+		if try.finally == nil {
+			continue
+		}
+
+		// The finally block runs outside of this try statement and the
+		// ones inside it.
+		tries, current := c.tries, c.scopes
+		c.tries = append([]*tryContext(nil), c.tries[:i]...)
+		c.scopes = scopes
+		c.block(try.finally)
+		c.tries, c.scopes = tries, current
+	}
+	return scopes
+}
+
+// tryStatement compiles a try statement. The VM jumps to the catch block with
+// the error value pushed, or, without a catch block, to the finally block
+// with the error pending; OpEndFinally raises a pending error again.
+func (c *compiler) tryStatement(statement *ast.TryStatement) {
+	try := &tryContext{scopes: c.scopes, handler: true}
+	if statement.HasFinally {
+		try.finally = statement.Finally
+	}
+	c.tries = append(c.tries, try)
+
+	setup := c.emit(OpSetupTry, 0, 0)
+	c.block(statement.Body)
+	c.emit(OpPopTry)
+	try.handler = false
+	end := c.emitJump(OpJump)
+
+	catchSetup := -1
+	if statement.HasCatch {
+		c.patchOperand(setup, 0, len(c.chunk.Code))
+		if statement.HasFinally {
+			catchSetup = c.emit(OpSetupTry, 0, 0)
+			try.handler = true
+		}
+		if statement.CatchName != "" {
+			c.pushScope()
+			c.emit(OpDeclareCatch, c.name(statement.CatchName))
+			for _, body := range statement.Catch {
+				c.statement(body)
+				c.emit(OpPop)
+			}
+			c.popScope()
+		} else {
+			c.emit(OpDiscard)
+			c.block(statement.Catch)
+		}
+		if statement.HasFinally {
+			c.emit(OpPopTry)
+			try.handler = false
+		}
+	}
+
+	c.tries = c.tries[:len(c.tries)-1]
+	c.patchJump(end)
+	if statement.HasFinally {
+		c.emit(OpFinally)
+		c.patchOperand(setup, 1, len(c.chunk.Code))
+		if catchSetup >= 0 {
+			c.patchOperand(catchSetup, 1, len(c.chunk.Code))
+		}
+		c.block(statement.Finally)
+		c.emit(OpEndFinally)
+	}
+	c.emit(OpNull)
+}
@@ -0,0 +1,597 @@
+package runtimelang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Jamlie/Jamlang/ast"
+)
+
+var (
+	runtimeValueType = reflect.TypeOf((*RuntimeValue)(nil)).Elem()
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ToValue converts a Go value to a Jamlang value. Booleans, numbers and
+// strings become the matching Jamlang values, integers becoming i32 when they
+// fit like integer literals do; slices and arrays become arrays; maps with
+// string or integer keys and structs become objects; funcs become native
+// functions (see MakeGoFunction); nil and nil pointers become null.
+// RuntimeValues are returned as they are.
+//
+// Struct fields are named by their `jam` tag, or else by the field name.
+// A tag of "-" skips the field, and the omitempty option skips it when it
+// has its zero value. Only exported fields are converted.
+func ToValue(value any) (RuntimeValue, error) {
+	return toValue(reflect.ValueOf(value), map[uintptr]bool{})
+}
+
+func toValue(rv reflect.Value, seen map[uintptr]bool) (RuntimeValue, error) {
+	if !rv.IsValid() {
+		return MakeNullValue(), nil
+	}
+	if rv.Type().Implements(runtimeValueType) && (rv.Kind() != reflect.Interface || !rv.IsNil()) {
+		if value, ok := rv.Interface().(RuntimeValue); ok && value != nil {
+			return value, nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return MakeBoolValue(rv.Bool()), nil
+	case reflect.Int8:
+		return MakeInt8Value(int8(rv.Int())), nil
+	case reflect.Int16:
+		return MakeInt16Value(int16(rv.Int())), nil
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return intValue(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return MakeFloat64Value(float64(rv.Uint())), nil
+		}
+		return intValue(int64(rv.Uint())), nil
+	case reflect.Float32:
+		return MakeFloat32Value(float32(rv.Float())), nil
+	case reflect.Float64:
+		return MakeFloat64Value(rv.Float()), nil
+	case reflect.String:
+		return MakeStringValue(rv.String()), nil
+	case reflect.Interface:
+		return toValue(rv.Elem(), seen)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return MakeNullValue(), nil
+		}
+		if seen[rv.Pointer()] {
+			return nil, NewJamErrorf(TypeError, "Cannot convert %s: it contains a cycle", rv.Type())
+		}
+		seen[rv.Pointer()] = true
+		defer delete(seen, rv.Pointer())
+		return toValue(rv.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Len() > 0 {
+			if seen[rv.Pointer()] {
+				return nil, NewJamErrorf(TypeError, "Cannot convert %s: it contains a cycle", rv.Type())
+			}
+			seen[rv.Pointer()] = true
+			defer delete(seen, rv.Pointer())
+		}
+		values := make([]RuntimeValue, rv.Len())
+		for i := range values {
+			value, err := toValue(rv.Index(i), seen)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return MakeArrayValue(values), nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return MakeNullValue(), nil
+		}
+		if seen[rv.Pointer()] {
+			return nil, NewJamErrorf(TypeError, "Cannot convert %s: it contains a cycle", rv.Type())
+		}
+		seen[rv.Pointer()] = true
+		defer delete(seen, rv.Pointer())
+
+		properties := make(map[string]RuntimeValue, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			value, err := toValue(iter.Value(), seen)
+			if err != nil {
+				return nil, err
+			}
+			properties[key] = value
+		}
+		return MakeObjectValue(properties), nil
+	case reflect.Struct:
+		object := newObjectValue(nil, rv.NumField())
+		for _, field := range structFields(rv.Type()) {
+			fieldValue := rv.FieldByIndex(field.index)
+			if field.omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			value, err := toValue(fieldValue, seen)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(field.name, value)
+		}
+		return object, nil
+	case reflect.Func:
+		if rv.IsNil() {
+			return MakeNullValue(), nil
+		}
+		return MakeGoFunction("func", rv.Interface())
+	}
+
+	return nil, NewJamErrorf(TypeError, "Cannot convert Go %s to a Jamlang value", rv.Type())
+}
+
+// intValue makes an i32 when n fits, like integer literals.
+func intValue(n int64) RuntimeValue {
+	if isInt32(float64(n)) {
+		return MakeInt32Value(int32(n))
+	}
+	return MakeInt64Value(n)
+}
+
+func mapKey(key reflect.Value) (string, error) {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", NewJamErrorf(TypeError, "Cannot convert Go map key of type %s to an object key", key.Type())
+}
+
+// fromMapKey stores the object key in rv, a Go map key, the opposite way to
+// mapKey.
+func fromMapKey(key string, rv reflect.Value, path string) error {
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(key)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, rv.Type().Bits())
+		if err != nil {
+			return NewJamErrorf(TypeError, "Cannot convert key %q to Go %s for %s", key, rv.Type(), path)
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(key, 10, rv.Type().Bits())
+		if err != nil {
+			return NewJamErrorf(TypeError, "Cannot convert key %q to Go %s for %s", key, rv.Type(), path)
+		}
+		rv.SetUint(n)
+		return nil
+	}
+	return NewJamErrorf(TypeError, "Cannot convert object keys to Go %s for %s", rv.Type(), path)
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the exported fields of t by their Jamlang names.
+// Untagged embedded structs contribute their own fields.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("jam")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for _, inner := range structFields(field.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     field.Index,
+			omitEmpty: options == "omitempty",
+		})
+	}
+	return fields
+}
+
+// FromValue stores value in the Go value target points to, converting it the
+// opposite way to ToValue. Numbers are converted to any Go number type they
+// fit in, objects fill structs field by field, matching names exactly or
+// else ignoring case, and maps with string or integer keys, and Jamlang
+// functions can be stored in Go func
+// variables, which call them with CallFunction. When target is *any, value
+// is stored as plain Go values: nil, bool, the numbers' own types, string,
+// []any and map[string]any.
+func FromValue(value RuntimeValue, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return NewJamErrorf(TypeError, "FromValue needs a non-nil pointer, got %T", target)
+	}
+	return fromValue(value, rv.Elem(), "value", nil)
+}
+
+// fromValue is FromValue for the Go value rv. Go funcs made for Jamlang
+// functions call them from env, or with CallFunction if env is nil.
+func fromValue(value RuntimeValue, rv reflect.Value, path string, env *Environment) error {
+	t := rv.Type()
+	if t == runtimeValueType {
+		rv.Set(reflect.ValueOf(&value).Elem())
+		return nil
+	}
+
+	_, isNull := value.(NullValue)
+	mismatch := func() error {
+		return NewJamErrorf(TypeError, "Cannot convert %s to Go %s for %s", value.Type(), t, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if isNull {
+			rv.Set(reflect.Zero(t))
+			return nil
+		}
+		plain := plainValue(value)
+		if plain == nil || !reflect.TypeOf(plain).AssignableTo(t) {
+			if !reflect.TypeOf(value).AssignableTo(t) {
+				return mismatch()
+			}
+			plain = value
+		}
+		rv.Set(reflect.ValueOf(plain))
+	case reflect.Pointer:
+		if isNull {
+			rv.Set(reflect.Zero(t))
+			return nil
+		}
+		elem := reflect.New(t.Elem())
+		if err := fromValue(value, elem.Elem(), path, env); err != nil {
+			return err
+		}
+		rv.Set(elem)
+	case reflect.Bool:
+		boolean, ok := value.(BoolValue)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetBool(boolean.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(IntValue)
+		if !ok {
+			return mismatch()
+		}
+		if rv.OverflowInt(int64(n.GetInt())) {
+			return NewJamErrorf(TypeError, "%d does not fit in Go %s for %s", n.GetInt(), t, path)
+		}
+		rv.SetInt(int64(n.GetInt()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := value.(IntValue)
+		if !ok {
+			return mismatch()
+		}
+		if n.GetInt() < 0 || rv.OverflowUint(uint64(n.GetInt())) {
+			return NewJamErrorf(TypeError, "%d does not fit in Go %s for %s", n.GetInt(), t, path)
+		}
+		rv.SetUint(uint64(n.GetInt()))
+	case reflect.Float32, reflect.Float64:
+		n, ok := numberValue(value)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetFloat(n)
+	case reflect.String:
+		str, ok := value.(StringValue)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetString(str.Value)
+	case reflect.Slice:
+		if isNull {
+			rv.Set(reflect.Zero(t))
+			return nil
+		}
+		values, ok := sequenceValues(value)
+		if !ok {
+			return mismatch()
+		}
+		slice := reflect.MakeSlice(t, len(values), len(values))
+		for i, element := range values {
+			if err := fromValue(element, slice.Index(i), fmt.Sprintf("%s[%d]", path, i), env); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+	case reflect.Array:
+		values, ok := sequenceValues(value)
+		if !ok {
+			return mismatch()
+		}
+		if len(values) != t.Len() {
+			return NewJamErrorf(TypeError, "Cannot convert %s of length %d to Go %s for %s", value.Type(), len(values), t, path)
+		}
+		for i, element := range values {
+			if err := fromValue(element, rv.Index(i), fmt.Sprintf("%s[%d]", path, i), env); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if isNull {
+			rv.Set(reflect.Zero(t))
+			return nil
+		}
+		object, ok := value.(ObjectValue)
+		if !ok {
+			return mismatch()
+		}
+		m := reflect.MakeMapWithSize(t, len(object.Properties))
+		for key, property := range object.Properties {
+			mapKey := reflect.New(t.Key()).Elem()
+			if err := fromMapKey(key, mapKey, path); err != nil {
+				return err
+			}
+			element := reflect.New(t.Elem()).Elem()
+			if err := fromValue(property, element, path+"."+key, env); err != nil {
+				return err
+			}
+			m.SetMapIndex(mapKey, element)
+		}
+		rv.Set(m)
+	case reflect.Struct:
+		object, ok := value.(ObjectValue)
+		if !ok {
+			return mismatch()
+		}
+		for _, field := range structFields(t) {
+			property, ok := object.Properties[field.name]
+			if !ok {
+				property, ok = propertyIgnoringCase(object, field.name)
+			}
+			if !ok {
+				continue
+			}
+			if err := fromValue(property, rv.FieldByIndex(field.index), path+"."+field.name, env); err != nil {
+				return err
+			}
+		}
+	case reflect.Func:
+		if isNull {
+			rv.Set(reflect.Zero(t))
+			return nil
+		}
+		if !isCallable(value) && value.Type() != Class {
+			return mismatch()
+		}
+		rv.Set(goFunction(value, t, env))
+	default:
+		return mismatch()
+	}
+
+	return nil
+}
+
+func sequenceValues(value RuntimeValue) ([]RuntimeValue, bool) {
+	switch value := value.(type) {
+	case *ArrayValue:
+		return value.Values, true
+	case TupleValue:
+		return value.Values, true
+	}
+	return nil, false
+}
+
+func propertyIgnoringCase(object ObjectValue, name string) (RuntimeValue, bool) {
+	for _, key := range object.Keys() {
+		if strings.EqualFold(key, name) {
+			return object.Properties[key], true
+		}
+	}
+	return nil, false
+}
+
+// plainValue converts value to the Go value FromValue stores in an any, or
+// nil when there is none.
+func plainValue(value RuntimeValue) any {
+	switch value := value.(type) {
+	case NullValue:
+		return nil
+	case BoolValue:
+		return value.Value
+	case Int8Value, Int16Value, Int32Value, Int64Value, Float32Value, Float64Value:
+		return value.Get()
+	case StringValue:
+		return value.Value
+	case *ArrayValue, TupleValue:
+		values, _ := sequenceValues(value)
+		plain := make([]any, len(values))
+		for i, element := range values {
+			plain[i] = plainValue(element)
+			if plain[i] == nil {
+				if _, ok := element.(NullValue); !ok {
+					plain[i] = element
+				}
+			}
+		}
+		return plain
+	case ObjectValue:
+		plain := make(map[string]any, len(value.Properties))
+		for key, property := range value.Properties {
+			plain[key] = plainValue(property)
+			if plain[key] == nil {
+				if _, ok := property.(NullValue); !ok {
+					plain[key] = property
+				}
+			}
+		}
+		return plain
+	}
+	return nil
+}
+
+// goFunction makes a Go func of type t that calls fn from env, whose engine's
+// permissions and limits apply, or with CallFunction if env is nil. If the
+// last result of t is an error, errors raised by fn are returned there;
+// otherwise they panic.
+func goFunction(fn RuntimeValue, t reflect.Type, env *Environment) reflect.Value {
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		results := make([]reflect.Value, t.NumOut())
+		for i := range results {
+			results[i] = reflect.Zero(t.Out(i))
+		}
+		returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+
+		fail := func(err error) []reflect.Value {
+			if !returnsError {
+				panic(err)
+			}
+			results[len(results)-1] = reflect.ValueOf(&err).Elem()
+			return results
+		}
+
+		args := make([]RuntimeValue, len(in))
+		for i, arg := range in {
+			value, err := toValue(arg, map[uintptr]bool{})
+			if err != nil {
+				return fail(err)
+			}
+			args[i] = value
+		}
+
+		var result RuntimeValue
+		var err error
+		if env != nil {
+			result, err = callFrom(fn, args, env)
+		} else {
+			result, err = CallFunction(fn, args)
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		values := t.NumOut()
+		if returnsError {
+			values--
+		}
+		if values == 1 {
+			results[0] = reflect.New(t.Out(0)).Elem()
+			if err := fromValue(result, results[0], "result", env); err != nil {
+				return fail(err)
+			}
+		} else if values > 1 {
+			tuple, ok := sequenceValues(result)
+			if !ok || len(tuple) != values {
+				return fail(NewJamErrorf(TypeError, "Expected %d results, got %s", values, result.Type()))
+			}
+			for i := 0; i < values; i++ {
+				results[i] = reflect.New(t.Out(i)).Elem()
+				if err := fromValue(tuple[i], results[i], fmt.Sprintf("result %d", i), env); err != nil {
+					return fail(err)
+				}
+			}
+		}
+		return results
+	})
+}
+
+// MakeGoFunction wraps fn, any Go func, as a native function called name.
+// Arguments are converted with FromValue to fn's parameter types, a wrong
+// number of them or an argument that does not convert being reported as an
+// ArgumentError or TypeError. fn's results are converted with ToValue: none
+// is null, one is its value and several are a tuple. If the last result is
+// an error and not nil, it is raised instead, as a RuntimeError unless it is
+// already a *JamError.
+func MakeGoFunction(name string, fn any) (NativeFunctionValue, error) {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		return NativeFunctionValue{}, NewJamErrorf(TypeError, "MakeGoFunction needs a func, got %T", fn)
+	}
+	t := rv.Type()
+
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (result RuntimeValue, err error) {
+		defer recoverError(&err)
+
+		params := t.NumIn()
+		switch {
+		case t.IsVariadic() && len(args) < params-1:
+			return nil, NewJamErrorf(ArgumentError, "%s takes at least %d arguments, got %d", name, params-1, len(args))
+		case !t.IsVariadic() && len(args) != params:
+			return nil, NewJamErrorf(ArgumentError, "%s takes %d arguments, got %d", name, params, len(args))
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			paramType := t.In(min(i, params-1))
+			if t.IsVariadic() && i >= params-1 {
+				paramType = paramType.Elem()
+			}
+			in[i] = reflect.New(paramType).Elem()
+			if err := fromValue(arg, in[i], fmt.Sprintf("argument %d of %s", i+1, name), &env); err != nil {
+				return nil, err
+			}
+		}
+
+		out := rv.Call(in)
+		if len(out) > 0 && t.Out(len(out)-1) == errorType {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				var jamErr *JamError
+				if errors.As(err, &jamErr) {
+					return nil, jamErr
+				}
+				return nil, NewJamError(RuntimeError, err.Error())
+			}
+			out = out[:len(out)-1]
+		}
+
+		switch len(out) {
+		case 0:
+			return MakeNullValue(), nil
+		case 1:
+			return ToValue(out[0].Interface())
+		}
+		values := make([]RuntimeValue, len(out))
+		for i, value := range out {
+			if values[i], err = ToValue(value.Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return MakeTupleValue(values), nil
+	}, name), nil
+}
+
+// DeclareGoFunction declares fn, any Go func, as a constant native function
+// called name. See MakeGoFunction.
+func (e *Environment) DeclareGoFunction(name string, fn any) error {
+	native, err := MakeGoFunction(name, fn)
+	if err != nil {
+		return err
+	}
+	_, err = e.DeclareVariable(name, native, true, ast.FunctionType)
+	return err
+}
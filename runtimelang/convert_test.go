@@ -0,0 +1,143 @@
+package runtimelang_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Jamlie/Jamlang/runtimelang"
+)
+
+type point struct {
+	X      int
+	Y      int    `jam:"y"`
+	Label  string `jam:"label,omitempty"`
+	hidden bool
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	values := []any{
+		true,
+		int8(-3),
+		42,
+		int64(1) << 40,
+		uint16(7),
+		float32(1.5),
+		2.25,
+		"héllo",
+		[]int{1, 2, 3},
+		[2]string{"a", "b"},
+		map[string]float64{"a": 1.5},
+		map[int]string{1: "one"},
+		point{X: 1, Y: 2, Label: "p"},
+		&point{X: 3},
+	}
+	for _, value := range values {
+		converted, err := runtimelang.ToValue(value)
+		if err != nil {
+			t.Errorf("ToValue(%#v): %v", value, err)
+			continue
+		}
+		target := reflect.New(reflect.TypeOf(value))
+		if err := runtimelang.FromValue(converted, target.Interface()); err != nil {
+			t.Errorf("FromValue(%s) to %T: %v", converted.ToString(), value, err)
+			continue
+		}
+		if got := target.Elem().Interface(); !reflect.DeepEqual(got, value) {
+			t.Errorf("round trip of %#v gave %#v", value, got)
+		}
+	}
+
+	converted, err := runtimelang.ToValue(map[string]any{"list": []any{1, "a", nil}, "n": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain any
+	if err := runtimelang.FromValue(converted, &plain); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"list": []any{int32(1), "a", nil}, "n": int32(2)}
+	if !reflect.DeepEqual(plain, want) {
+		t.Errorf("FromValue to any = %#v, want %#v", plain, want)
+	}
+}
+
+func TestConvertCycles(t *testing.T) {
+	slice := []any{nil}
+	slice[0] = slice
+	m := map[string]any{}
+	m["self"] = m
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+
+	for _, value := range []any{slice, m, n} {
+		_, err := runtimelang.ToValue(value)
+		wantKind(t, err, runtimelang.TypeError)
+	}
+
+	shared := []int{1}
+	if _, err := runtimelang.ToValue([][]int{shared, shared}); err != nil {
+		t.Errorf("a slice held twice is not a cycle, got %v", err)
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	for _, value := range []any{make(chan int), complex(1, 2), map[float64]int{1.5: 1}} {
+		_, err := runtimelang.ToValue(value)
+		wantKind(t, err, runtimelang.TypeError)
+	}
+
+	var n int8
+	number, _ := runtimelang.ToValue(300)
+	wantKind(t, runtimelang.FromValue(number, &n), runtimelang.TypeError)
+	var u uint
+	negative, _ := runtimelang.ToValue(-1)
+	wantKind(t, runtimelang.FromValue(negative, &u), runtimelang.TypeError)
+	var s string
+	wantKind(t, runtimelang.FromValue(number, &s), runtimelang.TypeError)
+	wantKind(t, runtimelang.FromValue(number, n), runtimelang.TypeError)
+}
+
+func TestMakeGoFunction(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		env := engine.Environment()
+		funcs := map[string]any{
+			"scale": func(p point, by float64) point { return point{X: int(float64(p.X) * by), Y: p.Y} },
+			"sum": func(first int, rest ...int) int {
+				for _, n := range rest {
+					first += n
+				}
+				return first
+			},
+			"split": func(s string) (string, string) { return s[:1], s[1:] },
+			"fail":  func() error { return errors.New("went wrong") },
+		}
+		for name, fn := range funcs {
+			if err := env.DeclareGoFunction(name, fn); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		result, err := engine.RunString("test.jam", `scale({ X: 2, y: 5 }, 1.5).X + sum(1, 2, 3) + split("ab")[1].length`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Get() != int32(3+6+1) {
+			t.Errorf("result = %v, want 10", result.Get())
+		}
+
+		_, err = engine.RunString("test.jam", `sum()`)
+		wantKind(t, err, runtimelang.ArgumentError)
+		_, err = engine.RunString("test.jam", `split("a", "b")`)
+		wantKind(t, err, runtimelang.ArgumentError)
+		_, err = engine.RunString("test.jam", `sum("x")`)
+		wantKind(t, err, runtimelang.TypeError)
+		_, err = engine.RunString("test.jam", `fail()`)
+		wantKind(t, err, runtimelang.RuntimeError)
+	})
+
+	if _, err := runtimelang.MakeGoFunction("f", 1); err == nil {
+		t.Error("MakeGoFunction accepted an int")
+	}
+}
@@ -0,0 +1,425 @@
+package runtimelang
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+// ModuleLoader returns the source code of the file an import statement
+// names.
+type ModuleLoader func(path string) (string, error)
+
+// LoadFile is the default ModuleLoader, which reads path from disk.
+func LoadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Limits bound what a script may do. Zero values mean no limit.
+type Limits struct {
+	// MaxSteps is how many AST nodes the evaluator may evaluate, or how
+	// many instructions the VM may execute, in one run.
+	MaxSteps int
+	// MaxCallDepth is how many Jamlang function calls may be nested.
+	MaxCallDepth int
+	// MaxDuration is how long one run may take.
+	MaxDuration time.Duration
+	// MaxCollectionSize is how many elements an array, tuple or object, or
+	// how many bytes a string, a script makes may have.
+	MaxCollectionSize int
+}
+
+// DefaultMaxCallDepth is the MaxCallDepth of new engines: deep enough for
+// ordinary recursion, and far short of overflowing the Go stack, which
+// would crash the process.
+const DefaultMaxCallDepth = 10000
+
+// cancelCheckInterval is how many steps pass between checks of whether the
+// context of a run is done.
+const cancelCheckInterval = 1024
+
+// Engine runs Jamlang programs in a global environment of its own, with its
+// own input, output, module loader and limits, so that several engines can
+// run scripts side by side in one process. The exported fields may be
+// changed between runs; nil ones fall back to os.Stdin, os.Stdout, os.Stderr
+// and LoadFile.
+//
+// An Engine runs one thing at a time: its methods may be called from several
+// goroutines, but not from a native function the engine is running. A run
+// that goes past one of the Limits, or whose context is done, fails with a
+// LimitError, which scripts cannot catch; when the context was done, the
+// error wraps the context's error. A script that calls exit, which needs the
+// process.exit permission, ends its run with an *ExitError.
+type Engine struct {
+	Stdin       io.Reader
+	Stdout      io.Writer
+	Stderr      io.Writer
+	Loader      ModuleLoader
+	Limits      Limits
+	Permissions Permissions
+	// UseVM runs programs with the bytecode VM instead of the tree-walking
+	// evaluator.
+	UseVM bool
+
+	mu    sync.Mutex
+	env   *Environment
+	input *bufio.Reader
+	// inputSource is the reader input was made for.
+	inputSource io.Reader
+	// pendingInput is the line a cancelled readLine was still reading from
+	// pendingReader, for the next readLine to finish.
+	pendingInput  chan lineRead
+	pendingReader *bufio.Reader
+	ctx           context.Context
+	started       time.Time
+	steps         int
+	depth         int
+}
+
+func NewEngine() *Engine {
+	engine := &Engine{
+		Limits:      Limits{MaxCallDepth: DefaultMaxCallDepth},
+		Permissions: DefaultPermissions(),
+	}
+	engine.env = newGlobalEnvironment(engine)
+	return engine
+}
+
+// Environment returns the global environment of e, to declare native
+// functions and variables in.
+func (e *Engine) Environment() *Environment {
+	return e.env
+}
+
+// RunString runs source as a program. name is the file name errors are
+// reported with.
+func (e *Engine) RunString(name, source string) (RuntimeValue, error) {
+	return e.RunStringContext(context.Background(), name, source)
+}
+
+// RunStringContext is RunString, stopping when ctx is done.
+func (e *Engine) RunStringContext(ctx context.Context, name, source string) (RuntimeValue, error) {
+	diagnostics, program := parser.NewParser().ProduceFileASTWithDiagnostics(name, source)
+	if len(diagnostics) > 0 {
+		return nil, syntaxErrorFrom(diagnostics)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.begin(ctx); err != nil {
+		return nil, err
+	}
+	defer e.end()
+
+	if !e.UseVM {
+		return Evaluate(&program, e.env)
+	}
+	chunk, err := Compile(program)
+	if err != nil {
+		return nil, err
+	}
+	return Execute(chunk, e.env)
+}
+
+// RunFile loads path with the engine's module loader and runs it.
+func (e *Engine) RunFile(path string) (RuntimeValue, error) {
+	return e.RunFileContext(context.Background(), path)
+}
+
+// RunFileContext is RunFile, stopping when ctx is done.
+func (e *Engine) RunFileContext(ctx context.Context, path string) (RuntimeValue, error) {
+	source, err := e.load(path)
+	if err != nil {
+		return nil, NewJamError(IOError, err.Error())
+	}
+	return e.RunStringContext(ctx, path, source)
+}
+
+// Call calls the global function name with args, converted with ToValue.
+func (e *Engine) Call(name string, args ...any) (RuntimeValue, error) {
+	return e.CallContext(context.Background(), name, args...)
+}
+
+// CallContext is Call, stopping when ctx is done.
+func (e *Engine) CallContext(ctx context.Context, name string, args ...any) (RuntimeValue, error) {
+	return e.call(ctx, args, func() (RuntimeValue, error) {
+		return e.env.LookupVariable(name)
+	})
+}
+
+// CallValue calls fn, a function from one of e's scripts, such as a callback
+// a native function kept, with args, converted with ToValue. Unlike
+// CallFunction, it may be called from any goroutine, for example an HTTP
+// handler: it waits until e is not running anything else, and the call
+// counts toward e's limits.
+func (e *Engine) CallValue(fn RuntimeValue, args ...any) (RuntimeValue, error) {
+	return e.CallValueContext(context.Background(), fn, args...)
+}
+
+// CallValueContext is CallValue, stopping when ctx is done.
+func (e *Engine) CallValueContext(ctx context.Context, fn RuntimeValue, args ...any) (RuntimeValue, error) {
+	return e.call(ctx, args, func() (RuntimeValue, error) {
+		return fn, nil
+	})
+}
+
+// call runs the function lookup returns with args as one run of e.
+func (e *Engine) call(ctx context.Context, args []any, lookup func() (RuntimeValue, error)) (result RuntimeValue, err error) {
+	values := make([]RuntimeValue, len(args))
+	for i, arg := range args {
+		if values[i], err = ToValue(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.begin(ctx); err != nil {
+		return nil, err
+	}
+	defer e.end()
+	defer recoverError(&err)
+
+	fn, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	return callValue(fn, values, e.env, ast.Position{})
+}
+
+// Get returns the value of the global variable name.
+func (e *Engine) Get(name string) (RuntimeValue, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.env.LookupVariable(name)
+}
+
+// Set declares the global variable name with value, converted with ToValue,
+// replacing any variable of that name.
+func (e *Engine) Set(name string, value any) error {
+	runtimeValue, err := ToValue(value)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.env.RemoveVariable(name)
+	delete(e.env.constants, name)
+	_, err = e.env.DeclareVariable(name, runtimeValue, false, ast.AnyType)
+	return err
+}
+
+func (e *Engine) stdout() io.Writer {
+	if e == nil || e.Stdout == nil {
+		return os.Stdout
+	}
+	return e.Stdout
+}
+
+func (e *Engine) stdin() *bufio.Reader {
+	source := io.Reader(os.Stdin)
+	if e != nil && e.Stdin != nil {
+		source = e.Stdin
+	}
+	if e == nil {
+		return bufio.NewReader(source)
+	}
+
+	if e.input == nil || e.inputSource != source {
+		e.input = bufio.NewReader(source)
+		e.inputSource = source
+	}
+	return e.input
+}
+
+type lineRead struct {
+	line string
+	err  error
+}
+
+// readLine reads a line from stdin, failing early if the run is cancelled or
+// goes past MaxDuration. A read that is given up on keeps going, and the next
+// readLine returns its line so that no input is lost.
+func (e *Engine) readLine() (string, error) {
+	reader := e.stdin()
+	if e == nil {
+		return reader.ReadString('\n')
+	}
+
+	if e.pendingInput == nil || e.pendingReader != reader {
+		read := make(chan lineRead, 1)
+		go func() {
+			line, err := reader.ReadString('\n')
+			read <- lineRead{line, err}
+		}()
+		e.pendingInput, e.pendingReader = read, reader
+	}
+
+	ctx, cancel := e.waitContext()
+	defer cancel()
+	select {
+	case read := <-e.pendingInput:
+		e.pendingInput, e.pendingReader = nil, nil
+		return read.line, read.err
+	case <-ctx.Done():
+		return "", e.waitError(ctx)
+	}
+}
+
+func (e *Engine) load(path string) (string, error) {
+	if e == nil || e.Loader == nil {
+		return LoadFile(path)
+	}
+	return e.Loader(path)
+}
+
+// begin starts counting the limits of a run that stops when ctx is done.
+// Every successful begin must be followed by end.
+func (e *Engine) begin(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return cancelledError(err)
+	}
+
+	e.steps, e.depth = 0, 0
+	e.started = time.Now()
+	e.ctx = ctx
+	return nil
+}
+
+func (e *Engine) end() {
+	e.ctx = nil
+	e.started = time.Time{}
+}
+
+// step counts one evaluation step, failing if it goes past MaxSteps or
+// MaxDuration or the run was cancelled.
+func (e *Engine) step() error {
+	if e == nil {
+		return nil
+	}
+
+	e.steps++
+	if e.Limits.MaxSteps > 0 && e.steps > e.Limits.MaxSteps {
+		return newLimitError("Maximum of %d steps exceeded", e.Limits.MaxSteps)
+	}
+	if e.steps%cancelCheckInterval != 0 {
+		return nil
+	}
+
+	if e.Limits.MaxDuration > 0 && !e.started.IsZero() && time.Since(e.started) > e.Limits.MaxDuration {
+		return newLimitError("Maximum run time of %s exceeded", e.Limits.MaxDuration)
+	}
+	if e.ctx != nil {
+		if err := e.ctx.Err(); err != nil {
+			return cancelledError(err)
+		}
+	}
+	return nil
+}
+
+// waitContext returns a context that is done when the run should stop
+// waiting for something, such as a timer or a network request.
+func (e *Engine) waitContext() (context.Context, context.CancelFunc) {
+	if e == nil || e.ctx == nil {
+		return context.WithCancel(context.Background())
+	}
+	if e.Limits.MaxDuration <= 0 {
+		return context.WithCancel(e.ctx)
+	}
+	return context.WithDeadline(e.ctx, e.started.Add(e.Limits.MaxDuration))
+}
+
+// waitError returns the error for a wait that stopped because ctx, from
+// waitContext, was done.
+func (e *Engine) waitError(ctx context.Context) error {
+	if e != nil && e.Limits.MaxDuration > 0 && time.Since(e.started) >= e.Limits.MaxDuration {
+		return newLimitError("Maximum run time of %s exceeded", e.Limits.MaxDuration)
+	}
+	return cancelledError(ctx.Err())
+}
+
+// wait sleeps for d, failing early if the run is cancelled or goes past
+// MaxDuration.
+func (e *Engine) wait(d time.Duration) error {
+	ctx, cancel := e.waitContext()
+	defer cancel()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return e.waitError(ctx)
+	}
+}
+
+func cancelledError(err error) *JamError {
+	jamErr := newLimitError("Execution cancelled: %v", err)
+	jamErr.cause = err
+	return jamErr
+}
+
+// enterCall records a nested function call, failing if it would go past
+// MaxCallDepth. Every successful call must be followed by leaveCall.
+func (e *Engine) enterCall() error {
+	if e == nil {
+		return nil
+	}
+	if e.Limits.MaxCallDepth > 0 && e.depth >= e.Limits.MaxCallDepth {
+		return newLimitError("Maximum call depth of %d exceeded", e.Limits.MaxCallDepth)
+	}
+	e.depth++
+	return nil
+}
+
+func (e *Engine) leaveCall() {
+	if e != nil {
+		e.depth--
+	}
+}
+
+// checkSize fails if a collection of size elements would go past
+// MaxCollectionSize.
+func (e *Engine) checkSize(size int) error {
+	if e == nil || e.Limits.MaxCollectionSize <= 0 || size <= e.Limits.MaxCollectionSize {
+		return nil
+	}
+	return newLimitError("Collection size %d exceeds the limit of %d", size, e.Limits.MaxCollectionSize)
+}
+
+// checkValue is checkSize for the size of value, if it is a collection.
+func (e *Engine) checkValue(value RuntimeValue) error {
+	if e == nil || e.Limits.MaxCollectionSize <= 0 {
+		return nil
+	}
+
+	switch value := value.(type) {
+	case *ArrayValue:
+		return e.checkSize(len(value.Values))
+	case TupleValue:
+		return e.checkSize(len(value.Values))
+	case ObjectValue:
+		return e.checkSize(len(value.Properties))
+	case *MapValue:
+		return e.checkSize(value.entries.len())
+	case *SetValue:
+		return e.checkSize(value.entries.len())
+	case StringValue:
+		return e.checkSize(len(value.Value))
+	}
+	return nil
+}
@@ -0,0 +1,668 @@
+package runtimelang_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/runtimelang"
+)
+
+// forEachEngine runs test with a new engine using the tree-walking evaluator
+// and with one using the VM.
+func forEachEngine(t *testing.T, test func(t *testing.T, engine *runtimelang.Engine)) {
+	for _, useVM := range []bool{false, true} {
+		name := "evaluator"
+		if useVM {
+			name = "vm"
+		}
+		t.Run(name, func(t *testing.T) {
+			engine := runtimelang.NewEngine()
+			engine.UseVM = useVM
+			engine.Stdout = &bytes.Buffer{}
+			test(t, engine)
+		})
+	}
+}
+
+func TestClosures(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunFile("../tests/closures.jam"); err != nil {
+			t.Fatal(err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); !strings.HasPrefix(output, "closures: ") {
+			t.Errorf("output = %q, want the checks passed", output)
+		}
+	})
+}
+
+func TestThrownLimitErrorIsCaught(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		script := `
+			try { throw { kind: "LimitError", message: "x" } } catch (e) { println("caught") } finally { println("finally") }
+			throw { kind: "LimitError", message: "x" }`
+		_, err := engine.RunString("test.jam", script)
+		wantKind(t, err, runtimelang.ThrownError)
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != "caught\nfinally\n" {
+			t.Errorf("output = %q, want catch and finally to run", output)
+		}
+	})
+}
+
+func TestBlockingBuiltinsStop(t *testing.T) {
+	scripts := []string{`input("> ")`, `HTTP.new().listen("127.0.0.1:0")`}
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		stdin, _ := io.Pipe()
+		engine.Stdin = stdin
+		engine.Permissions = runtimelang.AllPermissions()
+		for _, script := range scripts {
+			engine.Limits.MaxDuration = 200 * time.Millisecond
+			started := time.Now()
+			_, err := engine.RunString("test.jam", script)
+			wantKind(t, err, runtimelang.LimitError)
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Errorf("%s stopped after %s, want about 200ms", script, elapsed)
+			}
+
+			engine.Limits.MaxDuration = 0
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(100*time.Millisecond, cancel)
+			_, err = engine.RunStringContext(ctx, "test.jam", script)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: err = %v, want it cancelled", script, err)
+			}
+		}
+	})
+}
+
+func TestCyclicValues(t *testing.T) {
+	script := `
+		let a = []
+		a.push(a)
+		let b = []
+		b.push(b)
+		let o = { name: "o" }
+		o.self = o
+		let p = { name: "o" }
+		p.self = p
+		let m = Map()
+		m.set(1, m)
+		println(a, " ", o, " ", m)
+		println(b.contains(a), " ", o == p, " ", [m].contains(m))`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "[ [Circular] ] { name: o, self: [Circular] } Map { 1: [Circular] }\ntrue true true\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestMapKeysAndPrinting(t *testing.T) {
+	script := `
+		let half: f32 = 1.5
+		let m = Map([(1.5, "half"), (2, "two")])
+		println(m.has(half), " ", m.get(2.0), " ", m.has(2.5))
+		println({ m: Map([(1, 2)]), s: Set([1]) })`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "true two false\n{ m: Map { 1: 2 }, s: Set { 1 } }\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestKeysMatchEquals(t *testing.T) {
+	script := `
+		println(Set([1]).has(1.0), " ", [1].contains(1.0), " ", [float32(2)].indexOf(2), " ", [1].contains(1.5))
+		println(Map([(1, "a")]) == Map([(1.0, "a")]), " ", Set([1]) != Set([2]), " ", Map() == Set())`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "true true 0 false\ntrue true false\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+
+		_, err := engine.RunString("test.jam", `Map().set(float64("NaN"), 1)`)
+		wantKind(t, err, runtimelang.TypeError)
+		if err == nil || !strings.Contains(err.Error(), "cannot be NaN") {
+			t.Errorf("err = %v, want it to say NaN cannot be a key", err)
+		}
+	})
+}
+
+func TestNamedArguments(t *testing.T) {
+	script := `
+		let q = 0
+		fn f(a, b = 2) { return a * 10 + b }
+		println(q = 5)
+		println(f(q = 7), " ", q)
+		println(f(b: 3, a: 1))`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "5\n72 7\n13\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+
+		_, err := engine.RunString("test.jam", "f(c: 1)")
+		wantKind(t, err, runtimelang.ArgumentError)
+		_, err = engine.RunString("test.jam", "println(q: 1)")
+		wantKind(t, err, runtimelang.ArgumentError)
+	})
+}
+
+func TestTryResults(t *testing.T) {
+	script := `
+		let err = tryInt8("x").unwrapErr()
+		println(err.kind, " ", err.position.line, ":", err.position.column)
+		println(tryInt8(300).isErr(), " ", tryInt8(-128), " ", tryInt64(1.5), " ", int8(300))`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "TypeError 2:13\ntrue Ok(-128) Ok(1) 44\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestFailedTypeCheckChangesNothing(t *testing.T) {
+	script := `
+		type Pair = { a: i8, b: i8 }
+		let o = { a: 3, b: 300 }
+		try { let p: Pair = o } catch (e) {}
+		println(typeof(o.a))
+		let q: Pair = { a: 1, b: 2 }
+		try { q.c = 3 } catch (e) {}
+		let r = q
+		r.d = 4
+		r.c = 5
+		println(Object.keys(q))`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "i32\n[ a, b, d, c ]\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestNarrowing(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		script := `
+			fn g(a: i8) { return typeof(a) }
+			let x: i8 = 3
+			println(g(3), " ", typeof(x))`
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != "i8 i8\n" {
+			t.Errorf("output = %q, want both narrowed to i8", output)
+		}
+
+		for _, script := range []string{"let y: i8 = 300", "g(300)"} {
+			_, err := engine.RunString("test.jam", script)
+			wantKind(t, err, runtimelang.TypeError)
+		}
+	})
+}
+
+// parityCorpus holds scripts that must print the same output and fail with
+// the same error on the tree-walking evaluator and on the VM.
+var parityCorpus = map[string]string{
+	"arithmetic": `
+		let a: i8 = 100
+		let b: i16 = 1000
+		println(a + b, " ", typeof(a + b), " ", 7 / 2, " ", 7 % 3, " ", 2.5 * 4.0, " ", -a)
+		println(1 < 2 and 2 < 3, " ", not true or false, " ", "ab" + "cd")
+		let i = 0
+		println(++i, " ", --i, " ", i)`,
+	"control flow": `
+		let total = 0
+		for let i = 0; i < 10; ++i {
+			if i == 7 { break } elseif i % 2 == 0 { continue } else { total = total + i }
+		}
+		let n = 0
+		while n < 5 { n = n + 1 }
+		loop { n = n - 1 if n == 0 { break } }
+		foreach i, v in [10, 20] { total = total + i * v }
+		println(total, " ", n)`,
+	"functions": `
+		fn fib(n) { if n < 2 { return n } return fib(n - 1) + fib(n - 2) }
+		fn greet(name, greeting = "hi") { return greeting + " " + name }
+		println(fib(15), " ", greet("a"), " ", greet(greeting: "yo", name: "b"))
+		let counters = []
+		for let i = 0; i < 3; ++i { counters.push(fn() { return i }) }
+		println(counters[0](), counters[1](), counters[2]())`,
+	"classes": `
+		class Animal {
+			let name = ""
+			fn constructor(name) { this.name = name }
+			fn speak() { return this.name + " makes a sound" }
+		}
+		class Dog extends Animal {
+			fn speak() { return this.name + " barks" }
+		}
+		let d = Dog("rex")
+		println(d.speak(), " ", Animal("cat").speak(), " ", d)`,
+	"collections": `
+		let arr = [3, 1, 2]
+		arr.push(5)
+		println(arr.length, " ", arr.sort(), " ", (1, "a"))
+		let m = Map([(1, "one")])
+		m.set("k", [1, 2])
+		let s = Set([1, 2, 2])
+		println(m, " ", m.get(1.0), " ", s.size, " ", Object.keys({ b: 1, a: 2 }))`,
+	"errors": `
+		try { throw { kind: "Custom", message: "boom" } } catch (e) { println(e.message) } finally { println("done") }
+		try { let x: i8 = 300 } catch (e) { println(e.kind) }
+		println(tryInt8(1000).isErr())
+		undefinedName`,
+	"types": `
+		type Point = { x: i8, y: i8 }
+		let p: Point = { x: 1, y: 2 }
+		println(typeof(p.x), " ", p)
+		p.x = 1000`,
+}
+
+func TestParity(t *testing.T) {
+	corpus := map[string]string{
+		"closures": "closures.jam",
+	}
+	for name, script := range parityCorpus {
+		corpus[name] = script
+	}
+	// Programs large enough that their operands and jumps do not fit in 16
+	// bits.
+	var elements strings.Builder
+	for i := 0; i < 70000; i++ {
+		fmt.Fprintf(&elements, "%d, ", i)
+	}
+	corpus["large array"] = "let big = [" + elements.String() + "]\nprintln(big.length, \" \", big[69999])"
+	corpus["long jump"] = "let n = 0\nif n == 1 {\n" + strings.Repeat("n = n + 1\n", 70000) + "}\nprintln(n)"
+
+	for name, script := range corpus {
+		t.Run(name, func(t *testing.T) {
+			var results [2]string
+			for i, useVM := range []bool{false, true} {
+				engine := runtimelang.NewEngine()
+				engine.UseVM = useVM
+				stdout := &bytes.Buffer{}
+				engine.Stdout = stdout
+				var err error
+				if name == "closures" {
+					_, err = engine.RunFile("../tests/" + script)
+				} else {
+					_, err = engine.RunString("test.jam", script)
+				}
+				results[i] = stdout.String()
+				if err != nil {
+					results[i] += "error: " + err.Error()
+				}
+			}
+			if results[0] != results[1] {
+				t.Errorf("evaluator printed\n%s\nVM printed\n%s", results[0], results[1])
+			}
+		})
+	}
+}
+
+func TestArraySearchMixesFloatSizes(t *testing.T) {
+	script := `
+		let arr = [1.5, float32(2.5)]
+		println(arr.indexOf(float32(1.5)), " ", arr.indexOf(2.5), " ", arr.indexOf(float32(0.1)))
+		println(arr.contains(float32(1.5)), " ", arr.contains(2.5), " ", arr.contains(3.5))`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "0 1 -1\ntrue true false\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestRuntimeErrorPosition(t *testing.T) {
+	script := `fn outer() { return inner() }
+fn inner() {
+	let x = 1
+	return x + boom()
+}
+outer()`
+	boom := runtimelang.MakeNativeFunction(func(args []runtimelang.RuntimeValue, env runtimelang.Environment) (runtimelang.RuntimeValue, error) {
+		panic("boom")
+	}, "boom")
+
+	for _, test := range []struct {
+		script string
+		kind   runtimelang.ErrorKind
+	}{
+		{script, runtimelang.RuntimeError},
+		{strings.Replace(script, "boom()", "missing", 1), runtimelang.ReferenceError},
+	} {
+		forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+			if _, err := engine.Environment().DeclareVariable("boom", boom, true, ast.FunctionType); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := engine.RunString("test.jam", test.script)
+			var jamErr *runtimelang.JamError
+			if !errors.As(err, &jamErr) {
+				t.Fatalf("err = %v, want a JamError", err)
+			}
+			if jamErr.Kind != test.kind {
+				t.Errorf("kind = %s, want %s", jamErr.Kind, test.kind)
+			}
+			if want := (ast.Position{File: "test.jam", Line: 4, Column: 13}); jamErr.Position != want {
+				t.Errorf("position = %s, want %s", jamErr.Position, want)
+			}
+			want := []string{"inner (called from test.jam:1:21)", "outer (called from test.jam:6:1)"}
+			if !slices.Equal(jamErr.CallStack, want) {
+				t.Errorf("call stack = %q, want %q", jamErr.CallStack, want)
+			}
+		})
+	}
+}
+
+// TestTypedAliases covers a known limitation: an object's type is only
+// checked through the typed variable holding it, not through aliases.
+func TestTypedAliases(t *testing.T) {
+	script := `
+		type Person = { name: string, age: i32 }
+		fn greet(p: Person) { return p.name }
+		let p: Person = { name: "Ann", age: 30 }
+		let alias = p
+		alias.age = "bad"
+		println(p.age)`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != "bad\n" {
+			t.Errorf("output = %q, want the unchecked change through the alias", output)
+		}
+
+		for _, script := range []string{`greet(p)`, `p.name = "Bob"`} {
+			_, err := engine.RunString("test.jam", script)
+			wantKind(t, err, runtimelang.TypeError)
+		}
+	})
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	script := `
+		const città = "Köln 😀"
+		println(città.length, " ", città[1], " ", città[5], " ", città.substring(0, 4), " ", città.substring(5, 6))
+		println(città.indexOf("😀"), " ", città.lastIndexOf("ö"), " ", città.toUpper(), " ", "é".leftPad(3, "*"))
+		let letters = []
+		foreach c in "aé😀" { letters.push(c) }
+		println(letters, " ", "é".bytes(), " ", "é😀".runes())
+		let s = "né"
+		println(s.pop(), " ", s)`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		want := "6 ö 😀 Köln 😀\n5 1 KÖLN 😀 **é\n[ a, é, 😀 ] [ 195, 169 ] [ 233, 128512 ]\nn né\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+	})
+}
+
+func TestCallFunction(t *testing.T) {
+	script := `
+		fn add(a, b) { return a + b }
+		fn fail() { throw { kind: "Custom", message: "no" } }
+		println(apply(add, 1, 2), " ", apply(typeof, "abc"), " ", apply(fn(x) { return x * 2 }, 21))`
+	apply := runtimelang.MakeNativeFunction(func(args []runtimelang.RuntimeValue, env runtimelang.Environment) (runtimelang.RuntimeValue, error) {
+		return runtimelang.CallFunction(args[0], args[1:])
+	}, "apply")
+
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.Environment().DeclareVariable("apply", apply, true, ast.FunctionType); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != "3 string 42\n" {
+			t.Errorf("output = %q, want %q", output, "3 string 42\n")
+		}
+
+		_, err := engine.RunString("test.jam", "apply(add, 1)")
+		wantKind(t, err, runtimelang.ArgumentError)
+		_, err = engine.RunString("test.jam", "apply(fail)")
+		wantKind(t, err, "Custom")
+		if _, err := engine.RunString("test.jam", `let caught = ""
+			try { apply(fail) } catch (e) { caught = e.message }`); err != nil {
+			t.Fatal(err)
+		}
+		if caught, _ := engine.Get("caught"); caught.ToString() != "no" {
+			t.Errorf("caught %q, want the thrown message", caught.ToString())
+		}
+	})
+}
+
+func TestEngineCallValue(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		_, err := engine.RunString("test.jam", `
+			let calls = 0
+			fn add(a, b) { calls = calls + 1 return a + b }
+			fn fail() { throw { kind: "Custom", message: "no" } }`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		add, err := engine.Get("add")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := make(chan runtimelang.RuntimeValue, 10)
+		for i := 0; i < cap(results); i++ {
+			go func(i int) {
+				result, err := engine.CallValue(add, i, 1)
+				if err != nil {
+					t.Error(err)
+				}
+				results <- result
+			}(i)
+		}
+		sum := 0
+		for i := 0; i < cap(results); i++ {
+			if result := <-results; result != nil {
+				sum += int(result.Get().(int32))
+			}
+		}
+		if calls, _ := engine.Get("calls"); sum != 55 || calls.Get() != int32(10) {
+			t.Errorf("sum = %d, calls = %v, want 55 and 10", sum, calls.Get())
+		}
+
+		if result, err := engine.Call("add", "a", "b"); err != nil || result.ToString() != "ab" {
+			t.Errorf("Call = %v, %v, want ab", result, err)
+		}
+		_, err = engine.CallValue(add, 1)
+		wantKind(t, err, runtimelang.ArgumentError)
+		_, err = engine.Call("fail")
+		wantKind(t, err, "Custom")
+		_, err = engine.Call("missing")
+		wantKind(t, err, runtimelang.ReferenceError)
+		_, err = engine.Call("add", make(chan int), 1)
+		wantKind(t, err, runtimelang.TypeError)
+	})
+}
+
+func TestEnginesAreIsolated(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		other := runtimelang.NewEngine()
+		other.UseVM = engine.UseVM
+		other.Stdout = &bytes.Buffer{}
+		engine.Stdin = strings.NewReader("Ann\n")
+		other.Stdin = strings.NewReader("Bob\n")
+		if err := engine.Set("greeting", "hello"); err != nil {
+			t.Fatal(err)
+		}
+		if err := other.Set("greeting", "hi"); err != nil {
+			t.Fatal(err)
+		}
+
+		script := `
+			let name = input("name? ")
+			let count = 0
+			for let i = 0; i < 1000; ++i { count = count + 1 }
+			println(greeting, " ", name, " ", count)`
+		errs := make(chan error, 2)
+		for _, e := range []*runtimelang.Engine{engine, other} {
+			go func(e *runtimelang.Engine) {
+				_, err := e.RunString("test.jam", script)
+				errs <- err
+			}(e)
+		}
+		for i := 0; i < 2; i++ {
+			if err := <-errs; err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for e, want := range map[*runtimelang.Engine]string{
+			engine: "name? hello Ann 1000\n",
+			other:  "name? hi Bob 1000\n",
+		} {
+			if output := e.Stdout.(*bytes.Buffer).String(); output != want {
+				t.Errorf("output = %q, want %q", output, want)
+			}
+		}
+		if name, err := other.Get("name"); err != nil || name.ToString() != "Bob" {
+			t.Errorf("other's name = %v, %v, want Bob", name, err)
+		}
+
+		if _, err := engine.RunString("test.jam", "let only = 1"); err != nil {
+			t.Fatal(err)
+		}
+		_, err := other.Get("only")
+		wantKind(t, err, runtimelang.ReferenceError)
+	})
+}
+
+func TestJSON(t *testing.T) {
+	script := `
+		let text = '{"b":1,"a":[1,2.5,"x\\né",true,null,{"c":{}}],"big":12345678901}'
+		let v = JSON.parse(text)
+		println(JSON.stringify(v) == text, " ", v.a[1], " ", v.a[2], " ", typeof(v.b), " ", Object.keys(v))
+		println(JSON.stringify({ a: [1, "s"], b: {} }, 2))
+		println(JSON.stringify([1, (2, 3)], "\t"))`
+	want := `true 2.5 x
+é i64 [ b, a, big ]
+{
+  "a": [
+    1,
+    "s"
+  ],
+  "b": {}
+}
+[
+	1,
+	[
+		2,
+		3
+	]
+]
+`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		if _, err := engine.RunString("test.jam", script); err != nil {
+			t.Fatal(err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+
+		for script, message := range map[string]string{
+			`JSON.parse('{"a": 1,\n "b": }')`: "at line 2, column 8",
+			`JSON.parse('[1] 2')`:             "unexpected data after the JSON value",
+			`JSON.parse('[1, 2')`:             "unexpected end of JSON input",
+		} {
+			_, err := engine.RunString("test.jam", script)
+			wantKind(t, err, runtimelang.SyntaxError)
+			if !strings.Contains(err.Error(), message) {
+				t.Errorf("%s: err = %v, want it to say %q", script, err, message)
+			}
+		}
+
+		for script, message := range map[string]string{
+			`let o = { name: "o" }
+			o.self = o
+			JSON.stringify(o)`: "cannot serialize a cycle: $.self",
+			`JSON.stringify({ f: fn() {} })`: "cannot serialize the function at $.f",
+			`JSON.stringify([1], 11)`:        "indent must be between 0 and 10 spaces",
+		} {
+			_, err := engine.RunString("test.jam", script)
+			if err == nil || !strings.Contains(err.Error(), message) {
+				t.Errorf("%s: err = %v, want it to say %q", script, err, message)
+			}
+		}
+	})
+}
+
+func TestInsertionOrder(t *testing.T) {
+	script := `
+		class Base { let z = 1 }
+		class Child extends Base { let y = 2 let x = 3 }
+		let o = { c: 1, a: 2 }
+		o.b = 3
+		o.c = 4
+		let values = []
+		foreach key, value in o { values.push(key + "=" + value) }
+		println(o, " ", Object.keys(o), " ", Object.values(o), " ", values)
+		println(JSON.stringify(o), " ", JSON.parse('{"q":1,"p":2}'), " ", Child())
+		let m = Map([("c", 1), ("a", 2), ("b", 3)])
+		m.delete("c")
+		m.set("c", 4)
+		m.set("a", 5)
+		println(m.keys(), " ", m.values())
+		o`
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		result, err := engine.RunString("test.jam", script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "{ c: 4, a: 2, b: 3 } [ c, a, b ] [ 4, 2, 3 ] [ c=4, a=2, b=3 ]\n" +
+			`{"c":4,"a":2,"b":3} { q: 1, p: 2 } Child { z: 1, y: 2, x: 3 }` + "\n" +
+			"[ a, b, c ] [ 5, 3, 4 ]\n"
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != want {
+			t.Errorf("output = %q, want %q", output, want)
+		}
+
+		object := result.(runtimelang.ObjectValue)
+		object.Delete("c")
+		object.Set("d", runtimelang.MakeInt32Value(5))
+		object.Set("c", runtimelang.MakeInt32Value(6))
+		object.Set("a", runtimelang.MakeInt32Value(7))
+		if keys := object.Keys(); !slices.Equal(keys, []string{"a", "b", "d", "c"}) {
+			t.Errorf("keys after delete and re-add = %v, want [a b d c]", keys)
+		}
+		if printed := object.ToString(); printed != "{ a: 7, b: 3, d: 5, c: 6 }" {
+			t.Errorf("printed %s, want the keys in the same order", printed)
+		}
+	})
+}
@@ -1,8 +1,8 @@
 package runtimelang
 
 import (
-	"fmt"
-	"os"
+	"maps"
+	"sort"
 
 	"github.com/Jamlie/Jamlang/ast"
 )
@@ -12,10 +12,21 @@ type Environment struct {
 	variables map[string]RuntimeValue
 	constants map[string]bool
 	types     map[string]ast.VariableType
+	userTypes map[string]ast.Expression
+	engine    *Engine
+	// callPosition is where the native function given this environment was
+	// called from.
+	callPosition ast.Position
 }
 
+// CreateGlobalEnvironment returns the global environment of a new Engine.
 func CreateGlobalEnvironment() *Environment {
+	return NewEngine().Environment()
+}
+
+func newGlobalEnvironment(engine *Engine) *Environment {
 	env := NewEnvironment(nil)
+	env.engine = engine
 	env.DeclareVariable("true", MakeBoolValue(true), true, ast.BoolType)
 	env.DeclareVariable("false", MakeBoolValue(false), true, ast.BoolType)
 	env.DeclareVariable("null", MakeNullValue(), true, ast.NullType)
@@ -36,11 +47,14 @@ func CreateGlobalEnvironment() *Environment {
 	objectObject["keys"] = MakeNativeFunction(jamlangObjectKeys, "keys")
 	objectObject["values"] = MakeNativeFunction(jamlangObjectValues, "values")
 	objectObject["has"] = MakeNativeFunction(jamlangObjectHas, "has")
+	objectObject["classOf"] = MakeNativeFunction(jamlangObjectClassOf, "classOf")
+	objectObject["instanceOf"] = MakeNativeFunction(jamlangObjectInstanceOf, "instanceOf")
 	env.DeclareVariable("Object", MakeObjectValue(objectObject), true, ast.ObjectType)
 
 	osObject := make(map[string]RuntimeValue)
 	osObject["exit"] = MakeNativeFunction(jamlangExit, "exit")
 	osObject["open"] = MakeNativeFunction(jamlangOpen, "open")
+	osObject["tryOpen"] = MakeNativeFunction(jamlangTry(jamlangOpen), "tryOpen")
 	env.DeclareVariable("OS", MakeObjectValue(osObject), true, ast.ObjectType)
 
 	httpObject := make(map[string]RuntimeValue)
@@ -51,6 +65,8 @@ func CreateGlobalEnvironment() *Environment {
 	jsonObject := make(map[string]RuntimeValue)
 	jsonObject["parse"] = MakeNativeFunction(jamlangJsonParse, "parse")
 	jsonObject["stringify"] = MakeNativeFunction(jamlangJsonStringify, "stringify")
+	jsonObject["tryParse"] = MakeNativeFunction(jamlangTry(jamlangJsonParse), "tryParse")
+	jsonObject["tryStringify"] = MakeNativeFunction(jamlangTry(jamlangJsonStringify), "tryStringify")
 	env.DeclareVariable("JSON", MakeObjectValue(jsonObject), true, ast.ObjectType)
 
 	env.DeclareVariable("println", MakeNativeFunction(jamlangPrintln, "println"), true, ast.FunctionType)
@@ -72,27 +88,61 @@ func CreateGlobalEnvironment() *Environment {
 	env.DeclareVariable("float64", MakeNativeFunction(jamlangToFloat64, "float64"), true, ast.Float64Type)
 	env.DeclareVariable("eval", MakeNativeFunction(jamlangEval, "eval"), true, ast.AnyType)
 
+	env.DeclareVariable("Map", MakeNativeFunction(jamlangMap, "Map"), true, ast.FunctionType)
+	env.DeclareVariable("Set", MakeNativeFunction(jamlangSet, "Set"), true, ast.FunctionType)
+
+	env.DeclareVariable("Ok", MakeNativeFunction(jamlangOk, "Ok"), true, ast.FunctionType)
+	env.DeclareVariable("Err", MakeNativeFunction(jamlangErr, "Err"), true, ast.FunctionType)
+	env.DeclareVariable("tryInput", MakeNativeFunction(jamlangTry(jamlangInput), "tryInput"), true, ast.FunctionType)
+	env.DeclareVariable("tryUint32", MakeNativeFunction(jamlangTry(jamlangToUint32), "tryUint32"), true, ast.FunctionType)
+	env.DeclareVariable("tryUint64", MakeNativeFunction(jamlangTry(jamlangToUint64), "tryUint64"), true, ast.FunctionType)
+	env.DeclareVariable("tryInt8", MakeNativeFunction(jamlangTryInt(jamlangToInt8, "int8", 8), "tryInt8"), true, ast.FunctionType)
+	env.DeclareVariable("tryInt16", MakeNativeFunction(jamlangTryInt(jamlangToInt16, "int16", 16), "tryInt16"), true, ast.FunctionType)
+	env.DeclareVariable("tryInt32", MakeNativeFunction(jamlangTryInt(jamlangToInt32, "int32", 32), "tryInt32"), true, ast.FunctionType)
+	env.DeclareVariable("tryInt64", MakeNativeFunction(jamlangTryInt(jamlangToInt64, "int64", 64), "tryInt64"), true, ast.FunctionType)
+	env.DeclareVariable("tryFloat32", MakeNativeFunction(jamlangTry(jamlangToFloat32), "tryFloat32"), true, ast.FunctionType)
+	env.DeclareVariable("tryFloat64", MakeNativeFunction(jamlangTry(jamlangToFloat64), "tryFloat64"), true, ast.FunctionType)
+
 	return env
 }
 
 func NewEnvironment(parent *Environment) *Environment {
-	return &Environment{
+	env := &Environment{
 		parent:    parent,
 		variables: make(map[string]RuntimeValue),
 		constants: make(map[string]bool),
 		types:     make(map[string]ast.VariableType),
+		userTypes: make(map[string]ast.Expression),
+	}
+	if parent != nil {
+		env.engine = parent.engine
 	}
+	return env
+}
+
+// nextIteration returns a copy of e, the scope of the variable of a for
+// loop, for the loop's next iteration. Closures made in an iteration keep
+// the scope of that iteration, and with it the value the variable had then.
+func (e *Environment) nextIteration() *Environment {
+	next := NewEnvironment(e.parent)
+	maps.Copy(next.variables, e.variables)
+	maps.Copy(next.constants, e.constants)
+	maps.Copy(next.types, e.types)
+	maps.Copy(next.userTypes, e.userTypes)
+	return next
 }
 
-func (e *Environment) DeclareVariable(name string, value RuntimeValue, constant bool, varType ast.VariableType) RuntimeValue {
+// Engine returns the Engine e belongs to, or nil if it was not made by one.
+func (e *Environment) Engine() *Engine {
+	return e.engine
+}
+
+func (e *Environment) DeclareVariable(name string, value RuntimeValue, constant bool, varType ast.VariableType) (RuntimeValue, error) {
 	if _, ok := e.variables[name]; ok {
 		if _, ok := e.variables[name].(FunctionValue); ok {
-			fmt.Fprintf(os.Stderr, "Function %s already declared\n", name)
-		} else {
-			fmt.Fprintf(os.Stderr, "Variable %s already declared\n", name)
+			return nil, NewJamErrorf(ReferenceError, "Function %s already declared", name)
 		}
-		os.Exit(0)
-		return nil
+		return nil, NewJamErrorf(ReferenceError, "Variable %s already declared", name)
 	}
 
 	e.variables[name] = value
@@ -103,69 +153,100 @@ func (e *Environment) DeclareVariable(name string, value RuntimeValue, constant
 
 	e.types[name] = varType
 
-	return value
+	return value, nil
+}
+
+// DeclareUserTypedVariable declares a variable whose type was written as a
+// type alias or object type. The value is checked now and on every
+// assignment.
+func (e *Environment) DeclareUserTypedVariable(name string, value RuntimeValue, constant bool, userType ast.Expression) (RuntimeValue, error) {
+	value, err := conformToType(value, userType, e, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := e.DeclareVariable(name, value, constant, ast.AnyType); err != nil {
+		return nil, err
+	}
+
+	e.userTypes[name] = userType
+
+	return value, nil
 }
 
-func (e *Environment) AssignVariable(name string, value RuntimeValue) RuntimeValue {
+func (e *Environment) AssignVariable(name string, value RuntimeValue) (RuntimeValue, error) {
 	env := e.Resolve(name)
 	if env == nil {
-		fmt.Fprintf(os.Stderr, "Error: Variable %s not declared\n", name)
-		os.Exit(0)
-		return nil
+		return nil, NewJamErrorf(ReferenceError, "Variable %s not declared", name)
 	}
 
 	if env.constants[name] {
-		fmt.Fprintf(os.Stderr, "Error: Variable %s is constant. Cannot reassign a constant.\n", name)
-		os.Exit(0)
-		return nil
+		return nil, NewJamErrorf(TypeError, "Variable %s is constant. Cannot reassign a constant.", name)
+	}
+
+	if userType, ok := env.userTypes[name]; ok {
+		value, err := conformToType(value, userType, env, name)
+		if err != nil {
+			return nil, err
+		}
+		env.variables[name] = value
+		return value, nil
+	}
+
+	if err := checkVariableType(env.types[name], value); err != nil {
+		return nil, err
 	}
 
 	env.variables[name] = value
 
-	if env.types[name] != value.VarType() && env.types[name] != ast.AnyType {
+	return value, nil
+}
+
+// checkVariableType fails with a TypeError unless value may be stored in a
+// variable of type varType.
+func checkVariableType(varType ast.VariableType, value RuntimeValue) error {
+	if varType != value.VarType() && varType != ast.AnyType {
 		if value.Type() == I8 || value.Type() == I16 || value.Type() == I32 || value.Type() == I64 || value.Type() == F32 || value.Type() == F64 {
-			if env.types[name] == ast.Float64Type {
-				return value
+			if varType == ast.Float64Type {
+				return nil
 			}
 
 			if i8Val, ok := value.(Int8Value); ok {
-				if env.types[name] == ast.Int8Type && isInt8(float64(i8Val.Value)) {
-					return value
+				if varType == ast.Int8Type && isInt8(float64(i8Val.Value)) {
+					return nil
 				}
 			}
 
 			if i16Val, ok := value.(Int16Value); ok {
-				if env.types[name] == ast.Int16Type && isInt16(float64(i16Val.Value)) {
-					return value
+				if varType == ast.Int16Type && isInt16(float64(i16Val.Value)) {
+					return nil
 				}
 			}
 
 			if i32Val, ok := value.(Int32Value); ok {
-				if env.types[name] == ast.Int32Type && isInt32(float64(i32Val.Value)) {
-					return value
+				if varType == ast.Int32Type && isInt32(float64(i32Val.Value)) {
+					return nil
 				}
 			}
 
 			if i64Val, ok := value.(Int64Value); ok {
-				if env.types[name] == ast.Int64Type && isInt64(float64(i64Val.Value)) {
-					return value
+				if varType == ast.Int64Type && isInt64(float64(i64Val.Value)) {
+					return nil
 				}
 			}
 
 			if f32Val, ok := value.(Float32Value); ok {
-				if env.types[name] == ast.Float32Type && isFloat32(float64(f32Val.Value)) {
-					return value
+				if varType == ast.Float32Type && isFloat32(float64(f32Val.Value)) {
+					return nil
 				}
 			}
 
-			fmt.Fprintf(os.Stderr, "Error: Type mismatch, expected %s got %s\n", env.types[name], value.VarType())
-			os.Exit(0)
+			return NewJamErrorf(TypeError, "Type mismatch, expected %s got %s", varType, value.VarType())
 		}
-		fmt.Fprintf(os.Stderr, "Error: Type mismatch, expected %s got %s\n", env.types[name], value.VarType())
-		os.Exit(0)
+		return NewJamErrorf(TypeError, "Type mismatch, expected %s got %s", varType, value.VarType())
 	}
 
-	return value
+	return nil
 }
 
 func isInt8(value float64) bool {
@@ -200,17 +281,26 @@ func (e *Environment) Resolve(name string) *Environment {
 	return e.parent.Resolve(name)
 }
 
-func (e *Environment) LookupVariable(name string) RuntimeValue {
+func (e *Environment) LookupVariable(name string) (RuntimeValue, error) {
 	env := e.Resolve(name)
 	if env == nil {
-		fmt.Fprintf(os.Stderr, "Error: %s not declared\n", name)
-		os.Exit(0)
-		return nil
+		return nil, NewJamErrorf(ReferenceError, "%s not declared", name)
 	}
 
-	return env.variables[name]
+	return env.variables[name], nil
+}
+
+// Names returns the names declared directly in e, sorted.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.variables))
+	for name := range e.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (e *Environment) RemoveVariable(name string) {
 	delete(e.variables, name)
+	delete(e.userTypes, name)
 }
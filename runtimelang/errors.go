@@ -0,0 +1,231 @@
+package runtimelang
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+type ErrorKind string
+
+const (
+	RuntimeError    ErrorKind = "RuntimeError"
+	SyntaxError     ErrorKind = "SyntaxError"
+	TypeError       ErrorKind = "TypeError"
+	ReferenceError  ErrorKind = "ReferenceError"
+	IndexError      ErrorKind = "IndexError"
+	ArgumentError   ErrorKind = "ArgumentError"
+	ArithmeticError ErrorKind = "ArithmeticError"
+	IOError         ErrorKind = "IOError"
+	PermissionError ErrorKind = "PermissionError"
+	// LimitError is the kind of errors raised when a script goes past one
+	// of its engine's Limits. Scripts cannot catch them.
+	LimitError ErrorKind = "LimitError"
+	// ThrownError is the kind of values thrown by a script that are not
+	// error objects.
+	ThrownError ErrorKind = "Error"
+)
+
+// JamError is the error every failing evaluation step returns. It carries the
+// kind of failure, where it happened and the Jamlang functions it went through,
+// so that an embedding program can inspect it instead of the process exiting.
+// Position is filled in by Evaluate with the innermost node that failed.
+type JamError struct {
+	Kind      ErrorKind
+	Message   string
+	Position  ast.Position
+	CallStack []string
+	// Value is the value passed to throw, or nil when the interpreter
+	// raised the error.
+	Value RuntimeValue
+	// cause is the Go error the error was raised for, if any.
+	cause error
+	// limit is set by the engine on the errors of its Limits, which end the
+	// run. Thrown values cannot set it.
+	limit bool
+}
+
+func NewJamError(kind ErrorKind, message string) *JamError {
+	return &JamError{
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+func NewJamErrorf(kind ErrorKind, format string, args ...any) *JamError {
+	return NewJamError(kind, fmt.Sprintf(format, args...))
+}
+
+// newLimitError returns the LimitError of a run that went past one of its
+// engine's Limits or was cancelled.
+func newLimitError(format string, args ...any) *JamError {
+	err := NewJamErrorf(LimitError, format, args...)
+	err.limit = true
+	return err
+}
+
+func (e *JamError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s at %s: %s", e.Kind, e.Position, e.Message)
+	for i := 0; i < len(e.CallStack); {
+		// Runs of the same frame, as in deep recursion, are printed once.
+		repeated := 1
+		for i+repeated < len(e.CallStack) && e.CallStack[i+repeated] == e.CallStack[i] {
+			repeated++
+		}
+		sb.WriteString("\n    at " + e.CallStack[i])
+		if repeated > 1 {
+			fmt.Fprintf(&sb, " (%d times)", repeated)
+		}
+		i += repeated
+	}
+	return sb.String()
+}
+
+// Unwrap returns the Go error e was raised for, such as the context error of
+// a cancelled run, or nil.
+func (e *JamError) Unwrap() error {
+	return e.cause
+}
+
+// AsJamError returns err as a *JamError, wrapping foreign errors as a
+// RuntimeError so callers always get the structured form.
+func AsJamError(err error) *JamError {
+	var jamErr *JamError
+	if errors.As(err, &jamErr) {
+		return jamErr
+	}
+	return NewJamError(RuntimeError, err.Error())
+}
+
+// syntaxErrorFrom turns the parser diagnostics for a piece of source code into
+// a single SyntaxError positioned at the first one.
+func syntaxErrorFrom(diagnostics []parser.Diagnostic) *JamError {
+	messages := []string{diagnostics[0].Message}
+	for _, diagnostic := range diagnostics[1:] {
+		messages = append(messages, fmt.Sprintf("%s: %s", diagnostic.Position, diagnostic.Message))
+	}
+	err := NewJamError(SyntaxError, strings.Join(messages, "\n"))
+	err.Position = diagnostics[0].Position
+	return err
+}
+
+// withPosition records pos on err unless a more specific position is known.
+func withPosition(err error, pos ast.Position) error {
+	var jamErr *JamError
+	if errors.As(err, &jamErr) && !jamErr.Position.IsValid() {
+		jamErr.Position = pos
+	}
+	return err
+}
+
+// withFrame records that err passed through the Jamlang function name, called
+// from pos.
+func withFrame(err error, name string, pos ast.Position) error {
+	var jamErr *JamError
+	if !errors.As(err, &jamErr) {
+		return err
+	}
+	if name == "" {
+		name = "<anonymous>"
+	}
+	if !pos.IsValid() {
+		jamErr.CallStack = append(jamErr.CallStack, name)
+		return err
+	}
+	jamErr.CallStack = append(jamErr.CallStack, fmt.Sprintf("%s (called from %s)", name, pos))
+	return err
+}
+
+// newThrownError wraps value, passed to throw, in a JamError. Error objects,
+// such as the ones catch binds, keep their kind and message, except that
+// LimitError is left to the engine.
+func newThrownError(value RuntimeValue) *JamError {
+	err := NewJamError(ThrownError, fmt.Sprint(value.Get()))
+	if object, ok := value.(ObjectValue); ok {
+		if message, ok := object.Properties["message"].(StringValue); ok {
+			err.Message = message.Value
+		}
+		if kind, ok := object.Properties["kind"].(StringValue); ok && ErrorKind(kind.Value) != LimitError {
+			err.Kind = ErrorKind(kind.Value)
+		}
+	}
+	err.Value = value
+	return err
+}
+
+// errorValue returns the value catch binds for err: the thrown value, or an
+// error object with the message, kind, position and call stack of an error
+// raised by the interpreter.
+func errorValue(err error) RuntimeValue {
+	jamErr := AsJamError(err)
+	if jamErr.Value != nil {
+		return jamErr.Value
+	}
+
+	stack := make([]RuntimeValue, len(jamErr.CallStack))
+	for i, frame := range jamErr.CallStack {
+		stack[i] = MakeStringValue(frame)
+	}
+
+	return MakeObjectValue(map[string]RuntimeValue{
+		"message": MakeStringValue(jamErr.Message),
+		"kind":    MakeStringValue(string(jamErr.Kind)),
+		"position": MakeObjectValue(map[string]RuntimeValue{
+			"file":   MakeStringValue(jamErr.Position.File),
+			"line":   MakeInt32Value(int32(jamErr.Position.Line)),
+			"column": MakeInt32Value(int32(jamErr.Position.Column)),
+		}),
+		"stack": MakeArrayValue(stack),
+	})
+}
+
+// ExitError is the error of a run that a script ended by calling exit. Only
+// the CLI exits the process, with Code; hosts of an Engine get it back from
+// RunString, Call and the like.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// isCatchable reports whether try may catch err. Return, break, continue,
+// limit and exit errors pass through try statements.
+func isCatchable(err error) bool {
+	if err == IsReturnError || err == IsBreakError || err == IsContinueError {
+		return false
+	}
+	return !stopsRun(err)
+}
+
+// stopsRun reports whether err ends the whole run: a LimitError, or an
+// ExitError from exit. Neither catch nor finally blocks run for them.
+func stopsRun(err error) bool {
+	var jamErr *JamError
+	var exitErr *ExitError
+	return errors.As(err, &jamErr) && jamErr.limit || errors.As(err, &exitErr)
+}
+
+// recoverError turns a Go panic in the interpreter into a RuntimeError in
+// *err, so that try can catch it like any other failure. Panics with a
+// *JamError, such as errors raised by a callback called through a Go func,
+// are kept as they are.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = panicError(r)
+	}
+}
+
+// panicError is the error recoverError makes of the panic value r.
+func panicError(r any) error {
+	var jamErr *JamError
+	if panicErr, ok := r.(error); ok && errors.As(panicErr, &jamErr) {
+		return panicErr
+	}
+	return NewJamErrorf(RuntimeError, "%v", r)
+}
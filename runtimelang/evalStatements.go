@@ -1,43 +1,60 @@
 package runtimelang
 
 import (
-	"fmt"
-	"io"
-	"os"
+	"errors"
+	"slices"
 
 	"github.com/Jamlie/Jamlang/ast"
-	"github.com/Jamlie/Jamlang/internal"
 	"github.com/Jamlie/Jamlang/parser"
 )
 
 var (
-	IsReturnError   = fmt.Errorf("Error on line %d: return statement error", internal.Line())
-	IsBreakError    = fmt.Errorf("Error on line %d: break statement error", internal.Line())
-	IsContinueError = fmt.Errorf("Error on line %d: continue statement error", internal.Line())
+	IsReturnError   = errors.New("return statement error")
+	IsBreakError    = errors.New("break statement error")
+	IsContinueError = errors.New("continue statement error")
 )
 
-func EvaluateProgram(program ast.Program, env Environment) RuntimeValue {
+func EvaluateProgram(program ast.Program, env *Environment) (result RuntimeValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, NewJamErrorf(RuntimeError, "%v", r)
+		}
+	}()
+
 	var lastEvaluated RuntimeValue = &InitialValue{}
 	for _, statement := range program.Body {
-		lastEvaluated, _ = Evaluate(statement, env)
+		value, err := Evaluate(statement, env)
+		if err == IsReturnError || err == IsBreakError || err == IsContinueError {
+			lastEvaluated = value
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		lastEvaluated = value
 	}
-	return lastEvaluated
+	return lastEvaluated, nil
 }
 
 func EvaluateImportStatement(expr ast.ImportStatement, env *Environment) (RuntimeValue, error) {
-	file, err := os.Open(expr.Path)
+	return importFile(expr, env, func(statement ast.Statement, env *Environment) (RuntimeValue, error) {
+		return Evaluate(statement, env)
+	})
+}
+
+// importFile runs the declarations of the imported file with run and copies
+// the capitalized ones into env.
+func importFile(expr ast.ImportStatement, env *Environment, run func(ast.Statement, *Environment) (RuntimeValue, error)) (RuntimeValue, error) {
+	if err := env.engine.requirePath(Import, "import", expr.Path); err != nil {
+		return nil, err
+	}
+
+	fileString, err := env.engine.load(expr.Path)
 	if err != nil {
-		return MakeNullValue(), err
+		return nil, NewJamError(IOError, err.Error())
 	}
-	defer file.Close()
 
 	parser := parser.NewParser()
-	var fileString string
-	if fileBytes, err := io.ReadAll(file); err != nil {
-		return MakeNullValue(), err
-	} else {
-		fileString = string(fileBytes)
-	}
 
 	path := expr.Path
 	if path[0] == '.' {
@@ -48,11 +65,17 @@ func EvaluateImportStatement(expr ast.ImportStatement, env *Environment) (Runtim
 		path = path[1:]
 	}
 
-	program := parser.ProduceAST(fileString)
+	diagnostics, program := parser.ProduceFileASTWithDiagnostics(expr.Path, fileString)
+	if len(diagnostics) > 0 {
+		return nil, syntaxErrorFrom(diagnostics)
+	}
 	for _, statement := range program.Body {
 		if statement.Kind() == ast.FunctionDeclarationType {
 			function := statement.(*ast.FunctionDeclaration)
-			fn, _ := Evaluate(function, *env)
+			fn, err := run(function, env)
+			if err != nil {
+				return nil, err
+			}
 			if _, ok := fn.(FunctionValue); !ok {
 				continue
 			}
@@ -60,9 +83,27 @@ func EvaluateImportStatement(expr ast.ImportStatement, env *Environment) (Runtim
 			if function.Name[0] >= 'A' && function.Name[0] <= 'Z' {
 				env.variables[function.Name] = fn
 			}
+		} else if statement.Kind() == ast.ClassDeclarationType {
+			class := statement.(*ast.ClassDeclaration)
+			value, err := run(class, env)
+			if err != nil {
+				return nil, err
+			}
+
+			if class.Name[0] >= 'A' && class.Name[0] <= 'Z' {
+				env.variables[class.Name] = value
+			}
+		} else if statement.Kind() == ast.TypeDeclarationType {
+			typeDeclaration := statement.(*ast.TypeDeclaration)
+			if typeDeclaration.Name[0] >= 'A' && typeDeclaration.Name[0] <= 'Z' {
+				env.variables[typeDeclaration.Name] = MakeTypeValue(typeDeclaration.Name, typeDeclaration.Type)
+			}
 		} else if statement.Kind() == ast.VariableDeclarationType {
 			variable := statement.(*ast.VariableDeclaration)
-			value, _ := Evaluate(variable.Value, *env)
+			value, err := run(variable.Value, env)
+			if err != nil {
+				return nil, err
+			}
 			if _, ok := value.(RuntimeValue); !ok {
 				continue
 			}
@@ -76,462 +117,516 @@ func EvaluateImportStatement(expr ast.ImportStatement, env *Environment) (Runtim
 	return MakeNullValue(), nil
 }
 
-func EvaluateForStatement(expr ast.ForStatement, env *Environment) (RuntimeValue, error) {
-	scope := NewEnvironment(env)
+func EvaluateTypeDeclaration(expr ast.TypeDeclaration, env *Environment) (RuntimeValue, error) {
+	return env.DeclareVariable(expr.Name, MakeTypeValue(expr.Name, expr.Type), true, ast.AnyType)
+}
 
-	initialVariable := expr.Init.(*ast.VariableDeclaration).Identifier
-	_, err := Evaluate(expr.Init, *env)
-	defer func() {
-		env.RemoveVariable(initialVariable)
-	}()
+func EvaluateClassDeclaration(expr ast.ClassDeclaration, env *Environment) (RuntimeValue, error) {
+	class := &ClassValue{
+		Name:    expr.Name,
+		Methods: make(map[string]*FunctionValue),
+		Fields:  make(map[string]RuntimeValue),
+	}
 
-	if err != nil {
-		return MakeNullValue(), err
+	if expr.Parent != "" {
+		parent, err := env.LookupVariable(expr.Parent)
+		if err != nil {
+			return nil, err
+		}
+		parentClass, ok := parent.(*ClassValue)
+		if !ok {
+			return nil, NewJamErrorf(TypeError, "Class %s cannot extend %s, which is not a class", expr.Name, expr.Parent)
+		}
+		class.Parent = parentClass
 	}
 
-	for {
-		if expr.Condition != nil {
-			condition, err := Evaluate(expr.Condition, *scope)
+	for _, member := range expr.Body {
+		switch member := member.(type) {
+		case *ast.FunctionDeclaration:
+			method := FunctionValue{
+				Name:                   member.Name,
+				Parameters:             member.CloneParameters(),
+				Body:                   member.CloneBody(),
+				DeclarationEnvironment: env,
+				ReturnType:             member.ReturnType,
+				UserDefinedReturnType:  member.UserDefinedReturnType,
+			}
+			if member.Name == "constructor" {
+				class.Constructor = &method
+				continue
+			}
+			if _, ok := class.Methods[member.Name]; ok {
+				return nil, NewJamErrorf(ReferenceError, "Method %s already declared in class %s", member.Name, expr.Name)
+			}
+			class.Methods[member.Name] = &method
+		case *ast.VariableDeclaration:
+			if _, ok := class.Fields[member.Identifier]; ok {
+				return nil, NewJamErrorf(ReferenceError, "Field %s already declared in class %s", member.Identifier, expr.Name)
+			}
+			value, err := Evaluate(member.Value, env)
 			if err != nil {
-				return MakeNullValue(), err
+				return nil, err
 			}
-			if condition.Type() == Bool {
-				if !condition.(BoolValue).Value {
-					break
-				}
+			if member.IsUserDefinedType {
+				value, err = conformToType(value, member.UserDefinedType, env, expr.Name+"."+member.Identifier)
 			} else {
-				return MakeNullValue(), fmt.Errorf("for loop condition must be a boolean value")
+				value, err = makeValueWithVarType(value, member.Type)
+			}
+			if err != nil {
+				return nil, err
 			}
+			class.Fields[member.Identifier] = value
+			class.fieldNames = append(class.fieldNames, member.Identifier)
+		default:
+			return nil, NewJamErrorf(TypeError, "Invalid member in class %s", expr.Name)
 		}
+	}
 
-		for _, statement := range expr.Body {
-			if statement.Kind() == ast.BreakStatementType {
-				return MakeNullValue(), nil
-			}
+	return env.DeclareVariable(expr.Name, class, true, ast.AnyType)
+}
 
-			_, err := Evaluate(statement, *scope)
+// EvaluateForStatement runs a for loop. Its variable lives in a scope of its
+// own, which every iteration gets a fresh copy of, as in JavaScript, so that
+// closures made in the body see the value of their iteration. Bodies that
+// cannot make closures share the one scope.
+func EvaluateForStatement(expr ast.ForStatement, env *Environment) (RuntimeValue, error) {
+	loop := NewEnvironment(env)
+	if _, err := Evaluate(expr.Init, loop); err != nil {
+		return MakeNullValue(), err
+	}
 
+	closures := makesClosures(expr.Body)
+	for {
+		scope := NewEnvironment(loop)
+		if expr.Condition != nil {
+			condition, err := Evaluate(expr.Condition, scope)
 			if err != nil {
 				return MakeNullValue(), err
 			}
+			if condition.Type() != Bool {
+				return MakeNullValue(), NewJamError(TypeError, "for loop condition must be a boolean value")
+			}
+			if !condition.(BoolValue).Value {
+				break
+			}
+		}
+
+		result, err := evaluateBody(expr.Body, scope)
+		if err == IsBreakError {
+			break
+		}
+		if err != nil && err != IsContinueError {
+			return result, err
+		}
+
+		if closures {
+			loop = loop.nextIteration()
 		}
 		if expr.Update != nil {
-			_, err := Evaluate(expr.Update, *scope)
+			_, err := Evaluate(expr.Update, loop)
 			if err != nil {
 				return MakeNullValue(), err
 			}
 		}
+	}
 
-		for k, v := range scope.variables {
-			if _, ok := env.variables[k]; ok {
-				env.variables[k] = v
-			}
+	return MakeNullValue(), nil
+}
+
+// makesClosures reports whether running body may make functions that keep
+// the scope it runs in: function and class declarations, and imports, which
+// declare the functions of the imported file.
+func makesClosures[Node ast.Statement](body []Node) bool {
+	return slices.ContainsFunc(body, func(node Node) bool {
+		return makesClosure(node)
+	})
+}
+
+// makesClosure reports whether running node may make functions that keep
+// the scope it runs in. node may be nil.
+func makesClosure(node ast.Statement) bool {
+	switch node := node.(type) {
+	case *ast.FunctionDeclaration, *ast.ClassDeclaration, *ast.ImportStatement:
+		return true
+	case *ast.VariableDeclaration:
+		return makesClosure(node.Value)
+	case *ast.ReturnStatement:
+		return makesClosure(node.Value)
+	case *ast.ThrowStatement:
+		return makesClosure(node.Value)
+	case *ast.TryStatement:
+		return makesClosures(node.Body) || makesClosures(node.Catch) || makesClosures(node.Finally)
+	case *ast.ConditionalStatement:
+		return makesClosure(node.Condition) || makesClosures(node.Body) ||
+			makesClosures(node.ElseIfConditions) || slices.ContainsFunc(node.ElseIfBodies, makesClosures) ||
+			makesClosures(node.Alternate)
+	case *ast.WhileStatement:
+		return makesClosure(node.Condition) || makesClosures(node.Body)
+	case *ast.LoopStatement:
+		return makesClosures(node.Body)
+	case *ast.ForEachStatement:
+		return makesClosure(node.Collection) || makesClosures(node.Body)
+	case *ast.ForStatement:
+		return makesClosure(node.Init) || makesClosure(node.Condition) ||
+			makesClosure(node.Update) || makesClosures(node.Body)
+	case *ast.AssignmentExpression:
+		return makesClosure(node.Assignee) || makesClosure(node.Value)
+	case *ast.BinaryExpression:
+		return makesClosure(node.Left) || makesClosure(node.Right)
+	case *ast.LogicalExpression:
+		return makesClosure(node.Left) || makesClosure(node.Right)
+	case *ast.UnaryExpression:
+		return makesClosure(node.Value)
+	case *ast.TemplateLiteral:
+		return makesClosures(node.Expressions)
+	case *ast.ObjectLiteral:
+		return slices.ContainsFunc(node.Properties, func(property ast.Property) bool {
+			return makesClosure(property.Value)
+		})
+	case *ast.ArrayLiteral:
+		return makesClosures(node.Elements)
+	case *ast.TupleLiteral:
+		return makesClosures(node.Elements)
+	case *ast.CallExpression:
+		return makesClosure(node.Caller) || makesClosures(node.Args) ||
+			slices.ContainsFunc(node.NamedArgs, func(arg ast.NamedArgument) bool {
+				return makesClosure(arg.Value)
+			})
+	case *ast.MemberExpression:
+		return makesClosure(node.Object) || makesClosure(node.Property)
+	}
+	return false
+}
+
+// forEachEntries returns the keys and values foreach visits in collection.
+// Arrays, tuples, sets and strings are keyed by index; strings yield one
+// string per character.
+func forEachEntries(collection RuntimeValue) ([]RuntimeValue, []RuntimeValue, error) {
+	var keys, values []RuntimeValue
+	switch collection := collection.(type) {
+	case *ArrayValue:
+		values = collection.Values
+	case TupleValue:
+		values = collection.Values
+	case StringValue:
+		for _, char := range collection.Value {
+			values = append(values, StringValue{Value: string(char)})
 		}
+	case *MapValue:
+		return slices.Clone(collection.entries.keys), slices.Clone(collection.entries.values), nil
+	case *SetValue:
+		values = slices.Clone(collection.entries.keys)
+	case ObjectValue:
+		for _, key := range collection.Keys() {
+			keys = append(keys, StringValue{Value: key})
+			values = append(values, collection.Properties[key])
+		}
+		return keys, values, nil
+	default:
+		return nil, nil, NewJamErrorf(TypeError, "Cannot iterate over non-iterable type %s", collection.Type())
+	}
 
-		scope = NewEnvironment(env)
+	keys = make([]RuntimeValue, len(values))
+	for i := range values {
+		keys[i] = MakeInt32Value(int32(i))
 	}
+	return keys, values, nil
+}
 
-	return MakeNullValue(), nil
+// declareForEachVariables binds the loop variables of expr in scope. With a
+// single variable, objects bind their keys and other collections their
+// values.
+func declareForEachVariables(expr ast.ForEachStatement, scope *Environment, collection, key, value RuntimeValue) error {
+	if expr.Variable == "" {
+		if _, err := scope.DeclareVariable(expr.Key, key, false, ast.AnyType); err != nil {
+			return err
+		}
+		_, err := scope.DeclareVariable(expr.Value, value, false, ast.AnyType)
+		return err
+	}
+
+	if collection.Type() == Object || collection.Type() == Map {
+		value = key
+	}
+	_, err := scope.DeclareVariable(expr.Variable, value, false, ast.AnyType)
+	return err
 }
 
 func EvaluateForEachStatement(expr ast.ForEachStatement, env *Environment) (RuntimeValue, error) {
-	scope := NewEnvironment(env)
-
-	collection, err := Evaluate(expr.Collection, *env)
+	collection, err := Evaluate(expr.Collection, env)
 	if err != nil {
 		return MakeNullValue(), err
 	}
 
-	if collection.Type() == Array {
-		scope.DeclareVariable(expr.Variable, MakeNullValue(), false, ast.AnyType)
-		for _, element := range collection.(ArrayValue).Values {
-			scope.AssignVariable(expr.Variable, element)
+	keys, values, err := forEachEntries(collection)
+	if err != nil {
+		return MakeNullValue(), err
+	}
 
-			for _, statement := range expr.Body {
-				if statement.Kind() == ast.ReturnStatementType {
-					return Evaluate(statement, *scope)
-				}
-				_, err := Evaluate(statement, *scope)
-				if err != nil {
-					return MakeNullValue(), err
-				}
-			}
+	for i, value := range values {
+		scope := NewEnvironment(env)
+		if err := declareForEachVariables(expr, scope, collection, keys[i], value); err != nil {
+			return nil, err
 		}
-	} else if collection.Type() == Tuple {
-		scope.DeclareVariable(expr.Variable, MakeNullValue(), false, ast.AnyType)
-		for _, element := range collection.(TupleValue).Values {
-			scope.AssignVariable(expr.Variable, element)
-
-			for _, statement := range expr.Body {
-				if statement.Kind() == ast.ReturnStatementType {
-					return Evaluate(statement, *scope)
-				}
-				_, err := Evaluate(statement, *scope)
-				if err != nil {
-					return MakeNullValue(), err
-				}
-			}
+
+		result, err := evaluateBody(expr.Body, scope)
+		if err == IsBreakError {
+			break
 		}
-	} else if collection.Type() == String {
-		scope.DeclareVariable(expr.Variable, MakeNullValue(), false, ast.StringType)
-		for _, element := range collection.(StringValue).Value {
-			scope.AssignVariable(expr.Variable, StringValue{Value: string(element)})
-
-			for _, statement := range expr.Body {
-				if statement.Kind() == ast.ReturnStatementType {
-					return Evaluate(statement, *scope)
-				}
-				_, err := Evaluate(statement, *scope)
-				if err != nil {
-					return MakeNullValue(), err
-				}
-			}
+		if err != nil && err != IsContinueError {
+			return result, err
 		}
-	} else if collection.Type() == Object {
-		scope.DeclareVariable(expr.Key, MakeNullValue(), false, ast.AnyType)
-		scope.DeclareVariable(expr.Value, MakeNullValue(), false, ast.AnyType)
-		for key, value := range collection.(ObjectValue).Properties {
-			scope.AssignVariable(expr.Key, StringValue{Value: key})
-			scope.AssignVariable(expr.Value, value)
-
-			for _, statement := range expr.Body {
-				if statement.Kind() == ast.ReturnStatementType {
-					return Evaluate(statement, *scope)
-				}
-				_, err := Evaluate(statement, *scope)
-				if err != nil {
-					return MakeNullValue(), err
-				}
-			}
-		}
-	} else {
-		return MakeNullValue(), fmt.Errorf("Cannot iterate over non-iterable type")
 	}
 
 	return MakeNullValue(), nil
 }
 
 func EvaluateLoopStatement(expr ast.LoopStatement, env *Environment) (RuntimeValue, error) {
-	scope := NewEnvironment(env)
-
 	for {
-		for _, statement := range expr.Body {
-			if statement.Kind() == ast.ReturnStatementType {
-				result, err := Evaluate(statement, *scope)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-					os.Exit(0)
-				}
-				return result, IsReturnError
-			}
-			if statement.Kind() == ast.BreakStatementType {
-				return MakeNullValue(), nil
-			}
-
-			_, err := Evaluate(statement, *scope)
-			if err == IsBreakError {
-				return MakeNullValue(), nil
-			}
-			if err == IsContinueError {
-				continue
-			}
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-				os.Exit(0)
-			}
+		result, err := evaluateBody(expr.Body, NewEnvironment(env))
+		if err == IsBreakError {
+			return MakeNullValue(), nil
+		}
+		if err != nil && err != IsContinueError {
+			return result, err
 		}
-
-		scope = NewEnvironment(env)
 	}
 }
 
 func EvaluateWhileStatement(expr ast.WhileStatement, env *Environment) (RuntimeValue, error) {
-	condition, err := Evaluate(expr.Condition, *env)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-		os.Exit(0)
-	}
-
-	scope := NewEnvironment(env)
-
-	if condition.Type() != Bool {
-		return MakeNullValue(), fmt.Errorf("while statement condition must be a boolean")
-	}
-
-	for condition.Get() == true {
-		for _, statement := range expr.Body {
-			if statement.Kind() == ast.ReturnStatementType {
-				result, err := Evaluate(statement, *scope)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-					os.Exit(0)
-				}
-				return result, IsReturnError
-			}
-			if statement.Kind() == ast.BreakStatementType {
-				return MakeNullValue(), IsBreakError
-			}
-
-			_, err := Evaluate(statement, *scope)
-			if err == IsBreakError {
-				return MakeNullValue(), nil
-			}
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-				os.Exit(0)
-			}
+	for {
+		condition, err := Evaluate(expr.Condition, env)
+		if err != nil {
+			return nil, err
+		}
+		if condition.Type() != Bool {
+			return MakeNullValue(), NewJamError(TypeError, "while statement condition must be a boolean")
+		}
+		if !condition.(BoolValue).Value {
+			return MakeNullValue(), nil
 		}
 
-		scope = NewEnvironment(env)
-		condition, err = Evaluate(expr.Condition, *env)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
+		result, err := evaluateBody(expr.Body, NewEnvironment(env))
+		if err == IsBreakError {
+			return MakeNullValue(), nil
+		}
+		if err != nil && err != IsContinueError {
+			return result, err
 		}
 	}
-
-	return MakeNullValue(), nil
 }
 
 func EvaluateConditionalStatement(expr ast.ConditionalStatement, env *Environment) (RuntimeValue, error) {
-	condition, err := Evaluate(expr.Condition, *env)
+	condition, err := Evaluate(expr.Condition, env)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-		os.Exit(0)
+		return nil, err
 	}
 
-	scope := NewEnvironment(env)
-
 	if condition.Type() != Bool {
-		fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), "if statement condition must be a boolean")
-		os.Exit(0)
-	}
-
-	if condition.Get() == true {
-		for _, statement := range expr.Body {
-			if statement.Kind() == ast.ReturnStatementType {
-				result, err := Evaluate(statement, *scope)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error on line %d: %s", internal.Line(), err.Error())
-					os.Exit(0)
-				}
-
-				// IsReturnError is a special error that is used to indicate that a return statement has been reached
-				return result, IsReturnError
-			} else if statement.Kind() == ast.BreakStatementType {
-				return MakeNullValue(), IsBreakError
-			} else if statement.Kind() == ast.ContinueStatementType {
-				return MakeNullValue(), IsContinueError
-			}
-			_, err := Evaluate(statement, *scope)
-			if err == IsBreakError {
-				return MakeNullValue(), nil
-			}
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-				os.Exit(0)
-			}
-		}
+		return nil, NewJamError(TypeError, "if statement condition must be a boolean")
+	}
 
-		scope = NewEnvironment(env)
+	body := expr.Alternate
+	if condition.(BoolValue).Value {
+		body = expr.Body
 	} else {
-		trueCondIdx := -1
 		for idx, elseifCond := range expr.ElseIfConditions {
-			cond, err := Evaluate(elseifCond, *env)
+			cond, err := Evaluate(elseifCond, env)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-				os.Exit(0)
+				return nil, err
 			}
 
 			if cond.Type() != Bool {
-				return MakeNullValue(), fmt.Errorf("elseif statement condition must be a boolean")
+				return MakeNullValue(), NewJamError(TypeError, "elseif statement condition must be a boolean")
 			}
 
-			if cond.Get() == true {
-				trueCondIdx = idx
+			if cond.(BoolValue).Value {
+				body = expr.ElseIfBodies[idx]
 				break
 			}
 		}
+	}
 
-		if trueCondIdx != -1 {
-			for _, statement := range expr.ElseIfBodies[trueCondIdx] {
-				if statement.Kind() == ast.ReturnStatementType {
-					result, err := Evaluate(statement, *scope)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-						os.Exit(0)
-					}
-
-					return result, IsReturnError
-				} else if statement.Kind() == ast.BreakStatementType {
-					return MakeNullValue(), IsBreakError
-				}
-				_, err := Evaluate(statement, *scope)
-				if err == IsBreakError {
-					return MakeNullValue(), nil
-				}
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-					os.Exit(0)
-				}
-			}
+	result, err := evaluateBody(body, NewEnvironment(env))
+	if err != nil {
+		return result, err
+	}
+	return MakeNullValue(), nil
+}
 
-			scope = NewEnvironment(env)
-		} else {
-			for _, statement := range expr.Alternate {
-				if statement.Kind() == ast.ReturnStatementType {
-					result, err := Evaluate(statement, *scope)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-						os.Exit(0)
-					}
-
-					return result, IsReturnError
-				} else if statement.Kind() == ast.BreakStatementType {
-					return MakeNullValue(), IsBreakError
-				} else if statement.Kind() == ast.ContinueStatementType {
-					return MakeNullValue(), IsContinueError
-				}
-				_, err := Evaluate(statement, *scope)
-				if err == IsBreakError {
-					return MakeNullValue(), nil
-				}
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-					os.Exit(0)
-				}
-			}
+// evaluateBody runs the statements of a block in scope. It stops at the first
+// error, which includes IsReturnError, IsBreakError and IsContinueError.
+func evaluateBody(body []ast.Statement, scope *Environment) (RuntimeValue, error) {
+	// Entering a block is a step of its own, so that empty loop bodies
+	// count towards the step limit too.
+	if err := scope.engine.step(); err != nil {
+		return nil, err
+	}
 
-			scope = NewEnvironment(env)
+	var result RuntimeValue = MakeNullValue()
+	for _, statement := range body {
+		value, err := Evaluate(statement, scope)
+		if err != nil {
+			return value, err
 		}
+		result = value
+	}
+	return result, nil
+}
+
+func EvaluateThrowStatement(statement ast.ThrowStatement, env *Environment) (RuntimeValue, error) {
+	value, err := Evaluate(statement.Value, env)
+	if err != nil {
+		return nil, err
 	}
+	return nil, newThrownError(value)
+}
 
+// EvaluateTryStatement runs the try block, then the catch block if the try
+// block failed, then the finally block. An error or control flow out of the
+// finally block replaces the one from the other blocks.
+func EvaluateTryStatement(statement ast.TryStatement, env *Environment) (RuntimeValue, error) {
+	result, err := evaluateProtected(statement.Body, NewEnvironment(env))
+	if stopsRun(err) {
+		return nil, err
+	}
+	if err != nil && statement.HasCatch && isCatchable(err) {
+		scope := NewEnvironment(env)
+		if statement.CatchName != "" {
+			scope.DeclareVariable(statement.CatchName, errorValue(err), false, ast.AnyType)
+		}
+		result, err = evaluateProtected(statement.Catch, scope)
+		if stopsRun(err) {
+			return nil, err
+		}
+	}
+
+	if statement.HasFinally {
+		finallyResult, finallyErr := evaluateBody(statement.Finally, NewEnvironment(env))
+		if finallyErr != nil {
+			return finallyResult, finallyErr
+		}
+	}
+
+	if err != nil {
+		return result, err
+	}
 	return MakeNullValue(), nil
 }
 
-func EvaluateBreakStatement(statement ast.BreakStatement, env Environment) RuntimeValue {
-	return &BreakType{}
+// evaluateProtected is evaluateBody, reporting panics as errors.
+func evaluateProtected(body []ast.Statement, scope *Environment) (result RuntimeValue, err error) {
+	defer recoverError(&err)
+	return evaluateBody(body, scope)
 }
 
-func EvaluateContinueStatement(statement ast.ContinueStatement, env Environment) RuntimeValue {
-	return &ContinueType{}
+func EvaluateBreakStatement(statement ast.BreakStatement, env *Environment) (RuntimeValue, error) {
+	return &BreakType{}, IsBreakError
 }
 
-func EvaluateReturnStatement(statement ast.ReturnStatement, env Environment) RuntimeValue {
-	returnValue, _ := Evaluate(statement.Value, env)
-	return returnValue
+func EvaluateContinueStatement(statement ast.ContinueStatement, env *Environment) (RuntimeValue, error) {
+	return &ContinueType{}, IsContinueError
+}
+
+// EvaluateReturnStatement evaluates the returned value and reports it with
+// IsReturnError, which the enclosing function call turns into its result.
+func EvaluateReturnStatement(statement ast.ReturnStatement, env *Environment) (RuntimeValue, error) {
+	value, err := Evaluate(statement.Value, env)
+	if err != nil {
+		return nil, err
+	}
+	return value, IsReturnError
 }
 
 func checkNumberTypes(value RuntimeValue, varType ast.VariableType) bool {
 	return !(value.Type() == Number && varType == ast.Float64Type) && !(value.Type() == Number && varType == ast.Float32Type) && !(value.Type() == Number && varType == ast.Int64Type) && !(value.Type() == Number && varType == ast.Int32Type) && !(value.Type() == Number && varType == ast.Int16Type) && !(value.Type() == Number && varType == ast.Int8Type)
 }
 
-func makeValueWithVarType(value RuntimeValue, varType ast.VariableType) RuntimeValue {
+func makeValueWithVarType(value RuntimeValue, varType ast.VariableType) (RuntimeValue, error) {
 	switch varType {
 	case ast.Int8Type:
 		if _, ok := value.(IntValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Int8Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Int8Type, value.VarType())
 		}
-		return MakeInt8Value(int8(value.(IntValue).GetInt()))
+		return MakeInt8Value(int8(value.(IntValue).GetInt())), nil
 	case ast.Int16Type:
 		if _, ok := value.(IntValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Int16Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Int16Type, value.VarType())
 		}
-		return MakeInt16Value(int16(value.(IntValue).GetInt()))
+		return MakeInt16Value(int16(value.(IntValue).GetInt())), nil
 	case ast.Int32Type:
 		if _, ok := value.(IntValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Int32Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Int32Type, value.VarType())
 		}
-		return MakeInt32Value(int32(value.(IntValue).GetInt()))
+		return MakeInt32Value(int32(value.(IntValue).GetInt())), nil
 	case ast.Int64Type:
 		if _, ok := value.(IntValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Int64Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Int64Type, value.VarType())
 		}
-		return MakeInt64Value(int64(value.(IntValue).GetInt()))
+		return MakeInt64Value(int64(value.(IntValue).GetInt())), nil
 	case ast.Float32Type:
 		if _, ok := value.(FloatValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Float32Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Float32Type, value.VarType())
 		}
-		return MakeFloat32Value(float32(value.(FloatValue).GetFloat()))
+		return MakeFloat32Value(float32(value.(FloatValue).GetFloat())), nil
 	case ast.Float64Type:
 		if _, ok := value.(FloatValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.Float64Type, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.Float64Type, value.VarType())
 		}
-		return MakeFloat64Value(float64(value.(FloatValue).GetFloat()))
+		return MakeFloat64Value(float64(value.(FloatValue).GetFloat())), nil
 	case ast.ObjectType:
 		if _, ok := value.(NullValue); ok {
-			return value
+			return value, nil
 		}
 		if _, ok := value.(ObjectValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), ast.ObjectType, value.VarType())
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Expected %s, got %s", ast.ObjectType, value.VarType())
 		}
-		return value
+		return value, nil
 	case ast.NullType:
-		return value
+		return value, nil
 	case ast.AnyType:
-		return value
+		return value, nil
 	default:
-		return value
+		return value, nil
 	}
 }
 
-func EvaluateVariableDeclaration(declaration ast.VariableDeclaration, env *Environment, varType ast.VariableType) RuntimeValue {
-	value, _ := Evaluate(declaration.Value, *env)
+func EvaluateVariableDeclaration(declaration ast.VariableDeclaration, env *Environment, varType ast.VariableType) (RuntimeValue, error) {
+	value, err := Evaluate(declaration.Value, env)
+	if err != nil {
+		return nil, err
+	}
 
-	actualValue := makeValueWithVarType(value, varType)
+	return declareVariable(declaration, value, env, varType)
+}
 
-	if value.VarType() != varType && varType != ast.AnyType && !isNumber(value) {
-		fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-		os.Exit(0)
+// declareVariable declares the variable of declaration in env with its
+// already evaluated value, checking it against the declared type with the
+// same narrowing as typed parameters.
+func declareVariable(declaration ast.VariableDeclaration, value RuntimeValue, env *Environment, varType ast.VariableType) (RuntimeValue, error) {
+	if declaration.IsUserDefinedType {
+		return env.DeclareUserTypedVariable(declaration.Identifier, value, declaration.Constant, declaration.UserDefinedType)
 	}
 
-	if isNumber(value) {
-		if declaration.Type == ast.Int8Type && value.VarType() != ast.Int8Type {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		} else if declaration.Type == ast.Int16Type && (value.VarType() != ast.Int8Type && value.VarType() != ast.Int16Type) {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		} else if declaration.Type == ast.Int32Type && (value.VarType() != ast.Int8Type && value.VarType() != ast.Int16Type && value.VarType() != ast.Int32Type) {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		} else if declaration.Type == ast.Int64Type && (value.VarType() != ast.Int8Type && value.VarType() != ast.Int16Type && value.VarType() != ast.Int32Type && value.VarType() != ast.Int64Type) {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		} else if declaration.Type == ast.Float32Type && value.VarType() != ast.Float32Type {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		} else if declaration.Type == ast.Float64Type && (value.VarType() != ast.Float32Type && value.VarType() != ast.Float64Type) {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected %s, got %s\n", internal.Line(), declaration.Type, value.VarType())
-			os.Exit(0)
-		}
+	value, err := conformToVarType(value, varType, declaration.Identifier)
+	if err != nil {
+		return nil, err
 	}
 
-	return env.DeclareVariable(declaration.Identifier, actualValue, declaration.Constant, varType)
+	return env.DeclareVariable(declaration.Identifier, value, declaration.Constant, varType)
 }
 
-func EvaluateVariableDeclarationDeprecated(declaration ast.VariableDeclaration, env *Environment) RuntimeValue {
-	value, _ := Evaluate(declaration.Value, *env)
+func EvaluateVariableDeclarationDeprecated(declaration ast.VariableDeclaration, env *Environment) (RuntimeValue, error) {
+	value, err := Evaluate(declaration.Value, env)
+	if err != nil {
+		return nil, err
+	}
 	return env.DeclareVariable(declaration.Identifier, value, declaration.Constant, ast.AnyType)
 }
 
-func EvaluateIdentifier(identifier *ast.Identifier, env *Environment) RuntimeValue {
+func EvaluateIdentifier(identifier *ast.Identifier, env *Environment) (RuntimeValue, error) {
 	if identifier == nil {
-		return MakeNullValue()
+		return MakeNullValue(), nil
 	}
-	value := env.LookupVariable(identifier.Symbol)
-	if value == nil {
-		fmt.Fprintf(os.Stderr, "Error on line %d:Undefined variable %s\n", internal.Line(), identifier.Symbol)
-		os.Exit(0)
-		return nil
-	}
-
-	return value
+	return env.LookupVariable(identifier.Symbol)
 }
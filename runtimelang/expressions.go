@@ -2,330 +2,783 @@ package runtimelang
 
 import (
 	"fmt"
-	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Jamlie/Jamlang/ast"
 )
 
 func EvaluateFunctionDeclaration(expr ast.FunctionDeclaration, env *Environment, returnType ast.VariableType) (RuntimeValue, error) {
-	var fn FunctionValue
+	expr.ReturnType = returnType
+	return declareFunction(newFunctionValue(expr, env), env)
+}
+
+func newFunctionValue(expr ast.FunctionDeclaration, env *Environment) FunctionValue {
 	if expr.Name == "" {
 		expr.IsAnonymous = true
 	}
 	if expr.IsAnonymous {
-		fn = FunctionValue{
+		return FunctionValue{
 			Body:                   expr.CloneBody(),
 			Parameters:             expr.CloneParameters(),
-			DeclarationEnvironment: *env,
+			DeclarationEnvironment: env,
 			IsAnonymous:            true,
-			ReturnType:             returnType,
-		}
-	} else {
-		fn = FunctionValue{
-			Name:                   expr.Name,
-			Body:                   expr.CloneBody(),
-			Parameters:             expr.CloneParameters(),
-			DeclarationEnvironment: *env,
-			IsAnonymous:            false,
-			ReturnType:             returnType,
+			ReturnType:             expr.ReturnType,
+			UserDefinedReturnType:  expr.UserDefinedReturnType,
 		}
+	}
 
-		env.DeclareVariable(expr.Name, fn, true, ast.FunctionType)
+	return FunctionValue{
+		Name:                   expr.Name,
+		Body:                   expr.CloneBody(),
+		Parameters:             expr.CloneParameters(),
+		DeclarationEnvironment: env,
+		IsAnonymous:            false,
+		ReturnType:             expr.ReturnType,
+		UserDefinedReturnType:  expr.UserDefinedReturnType,
+	}
+}
+
+// declareFunction declares fn in env unless it is anonymous.
+func declareFunction(fn FunctionValue, env *Environment) (RuntimeValue, error) {
+	if !fn.IsAnonymous {
+		if _, err := env.DeclareVariable(fn.Name, fn, true, ast.FunctionType); err != nil {
+			return nil, err
+		}
 	}
 
 	return fn, nil
 }
 
-func EvaluateCallExpression(expr ast.CallExpression, env Environment) RuntimeValue {
+func EvaluateCallExpression(expr ast.CallExpression, env *Environment) (RuntimeValue, error) {
 	var args []RuntimeValue
 	for _, arg := range expr.Args {
 		value, err := Evaluate(arg, env)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(0)
+			return nil, err
 		}
 
 		args = append(args, value)
 	}
 
+	named := make([]namedArgument, len(expr.NamedArgs))
+	for i, arg := range expr.NamedArgs {
+		value, err := Evaluate(arg.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		named[i] = namedArgument{name: arg.Name, value: value}
+	}
+
 	function, err := Evaluate(expr.Caller, env)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
 	}
 
+	return callNamed(function, args, named, env, expr.Pos())
+}
+
+// namedArgument is an argument passed by parameter name.
+type namedArgument struct {
+	name  string
+	value RuntimeValue
+}
+
+// CallFunction calls fn, a Jamlang function, native function, bound method
+// or class, with args, so that native functions can call back into scripts.
+// Errors raised by fn, including panics in native code, are returned as
+// *JamError values with fn in their call stack.
+//
+// CallFunction may only be used by a native function while it runs, on the
+// goroutine of the Engine running it. Callbacks called later or from other
+// goroutines, such as HTTP handlers, must go through Engine.CallValue, which
+// waits for the engine to be free.
+//
+// A native function passed as fn runs outside of any Engine, so builtins
+// that need a capability, such as OS.open, fail with a PermissionError.
+func CallFunction(fn RuntimeValue, args []RuntimeValue) (RuntimeValue, error) {
+	return callFrom(fn, args, NewEnvironment(nil))
+}
+
+// callFrom is CallFunction for a call made from env, whose engine's
+// permissions and limits apply to it.
+func callFrom(fn RuntimeValue, args []RuntimeValue, env *Environment) (result RuntimeValue, err error) {
+	defer recoverError(&err)
+	return callValue(fn, args, env, ast.Position{})
+}
+
+// callValue calls function with already evaluated args. pos is where the
+// call happens and is recorded in the call stack of errors.
+func callValue(function RuntimeValue, args []RuntimeValue, env *Environment, pos ast.Position) (RuntimeValue, error) {
+	return callNamed(function, args, nil, env, pos)
+}
+
+// callNamed is callValue with arguments passed by name after args. Only
+// Jamlang functions and classes take them.
+func callNamed(function RuntimeValue, args []RuntimeValue, named []namedArgument, env *Environment, pos ast.Position) (RuntimeValue, error) {
 	if function == nil {
-		fmt.Fprintln(os.Stderr, "Error: Function does not exist")
-		os.Exit(0)
+		return nil, NewJamError(ReferenceError, "Function does not exist")
 	}
 
+	if err := env.engine.enterCall(); err != nil {
+		return nil, err
+	}
+	defer env.engine.leaveCall()
+
 	if function.Type() == NativeFunction {
-		result := function.(NativeFunctionValue).Call(args, env)
-		return result
+		native := function.(NativeFunctionValue)
+		if len(named) > 0 {
+			return nil, withFrame(NewJamErrorf(ArgumentError, "%s does not take named arguments", native.Name), native.Name, pos)
+		}
+		callEnv := *env
+		callEnv.callPosition = pos
+		result, err := native.Call(args, callEnv)
+		if err == nil {
+			err = env.engine.checkValue(result)
+		}
+		if err != nil {
+			return nil, withFrame(err, native.Name, pos)
+		}
+		return result, nil
 	} else if function.Type() == Function {
 		fn := function.(FunctionValue)
-		scope := NewEnvironment(&fn.DeclarationEnvironment)
-
-		for i := 0; i < len(fn.Parameters); i++ {
-			if i >= len(args) {
-				fmt.Fprintln(os.Stderr, "Error: Not enough arguments")
-				os.Exit(0)
+		result, err := callFunctionValue(fn, args, named)
+		if err != nil {
+			return nil, withFrame(err, fn.Name, pos)
+		}
+		return result, nil
+	} else if function.Type() == Class {
+		class := function.(*ClassValue)
+		result, err := instantiateClass(class, args, named)
+		if err != nil {
+			return nil, withFrame(err, class.Name, pos)
+		}
+		return result, nil
+	} else if function.Type() == Super {
+		super := function.(SuperValue)
+		constructor, owner := super.Class.FindConstructor()
+		if constructor == nil {
+			if len(args)+len(named) > 0 {
+				return nil, withFrame(NewJamErrorf(ArgumentError, "%s has no constructor but was given %d arguments", super.Class.Name, len(args)+len(named)), "super", pos)
 			}
-			varname := fn.Parameters[i]
-			scope.DeclareVariable(varname, args[i], false, ast.FunctionType)
-		}
-		var result RuntimeValue = MakeNullValue()
-		for _, stmt := range fn.Body {
-			if stmt.Kind() == ast.ReturnStatementType {
-				result, err = Evaluate(stmt, *scope)
-				if err != nil {
-					fmt.Fprintln(os.Stderr, err)
-					os.Exit(0)
-				}
+			return MakeNullValue(), nil
+		}
+		if _, err := callFunctionValue(bindMethod(*constructor, super.This, owner), args, named); err != nil {
+			return nil, withFrame(err, super.Class.Name+".constructor", pos)
+		}
+		return MakeNullValue(), nil
+	}
 
-				if result.VarType() != fn.ReturnType && fn.ReturnType != ast.AnyType && isNotANumber(result.Type(), fn.ReturnType) {
-					fmt.Fprintln(os.Stderr, "Error: Return type does not match function return type")
-					os.Exit(0)
-				}
+	return nil, NewJamError(TypeError, "Not a function")
+}
+
+func callFunctionValue(fn FunctionValue, args []RuntimeValue, named []namedArgument) (RuntimeValue, error) {
+	scope := NewEnvironment(fn.DeclarationEnvironment)
 
-				return result
+	byName, err := matchNamedArguments(fn, len(args), named)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkArity(fn, len(args)+len(named)); err != nil {
+		return nil, err
+	}
+
+	for i, param := range fn.Parameters {
+		if param.IsRest {
+			var rest []RuntimeValue
+			if i < len(args) {
+				rest = args[i:]
 			}
-			result, err = Evaluate(stmt, *scope)
-			if err == IsReturnError {
-				return result
+			if err := declareRestParameter(scope, param, rest); err != nil {
+				return nil, err
 			}
+			break
+		}
+
+		var arg RuntimeValue
+		if i < len(args) {
+			arg = args[i]
+		} else if value, ok := byName[param.Name]; ok {
+			arg = value
+		} else if param.Default != nil {
+			value, err := Evaluate(param.Default, scope)
 			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(0)
+				return nil, err
 			}
+			arg = value
+		} else {
+			return nil, NewJamErrorf(ArgumentError, "%s is missing argument %s", functionName(fn), param.Name)
+		}
+
+		if err := declareParameter(scope, param, arg); err != nil {
+			return nil, err
+		}
+	}
+	if fn.code != nil {
+		result, returned, err := run(fn.code, scope, MakeNullValue())
+		if err != nil {
+			return nil, err
+		}
+		if returned {
+			return checkReturnValue(fn, result)
+		}
+		return result, nil
+	}
+
+	var result RuntimeValue = MakeNullValue()
+	for _, stmt := range fn.Body {
+		result, err = Evaluate(stmt, scope)
+		if err == IsReturnError {
+			return checkReturnValue(fn, result)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func functionName(fn FunctionValue) string {
+	if fn.Name == "" {
+		return "<anonymous>"
+	}
+	return fn.Name
+}
+
+// checkArity reports an ArgumentError unless argc arguments can fill the
+// parameters of fn, taking defaults and a rest parameter into account.
+func checkArity(fn FunctionValue, argc int) error {
+	required, max := 0, len(fn.Parameters)
+	for _, param := range fn.Parameters {
+		if param.IsRest {
+			max = -1
+			break
+		}
+		if param.Default == nil {
+			required++
 		}
+	}
 
-		return result
+	switch {
+	case argc < required && required == max:
+		return NewJamErrorf(ArgumentError, "%s expects %d arguments, got %d", functionName(fn), required, argc)
+	case argc < required:
+		return NewJamErrorf(ArgumentError, "%s expects at least %d arguments, got %d", functionName(fn), required, argc)
+	case max >= 0 && argc > max && required == max:
+		return NewJamErrorf(ArgumentError, "%s expects %d arguments, got %d", functionName(fn), max, argc)
+	case max >= 0 && argc > max:
+		return NewJamErrorf(ArgumentError, "%s expects at most %d arguments, got %d", functionName(fn), max, argc)
 	}
 
-	fmt.Fprintln(os.Stderr, "Error: Not a function")
-	os.Exit(0)
 	return nil
 }
 
+// matchNamedArguments returns the values of named by parameter name, failing
+// if one names no parameter of fn, the rest parameter, or one of the first
+// positional parameters, which are given by position.
+func matchNamedArguments(fn FunctionValue, positional int, named []namedArgument) (map[string]RuntimeValue, error) {
+	if len(named) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]RuntimeValue, len(named))
+	for _, arg := range named {
+		i := slices.IndexFunc(fn.Parameters, func(param ast.Parameter) bool {
+			return param.Name == arg.name
+		})
+		switch {
+		case i < 0:
+			return nil, NewJamErrorf(ArgumentError, "%s has no parameter named %s", functionName(fn), arg.name)
+		case fn.Parameters[i].IsRest:
+			return nil, NewJamErrorf(ArgumentError, "Rest parameter %s of %s cannot be passed by name", arg.name, functionName(fn))
+		case i < positional:
+			return nil, NewJamErrorf(ArgumentError, "%s was given argument %s both by position and by name", functionName(fn), arg.name)
+		}
+		values[arg.name] = arg.value
+	}
+	return values, nil
+}
+
+// declareRestParameter binds the remaining args to param as an array. A type
+// annotation on a rest parameter applies to each element.
+func declareRestParameter(scope *Environment, param ast.Parameter, args []RuntimeValue) error {
+	values := make([]RuntimeValue, len(args))
+	for i, arg := range args {
+		what := fmt.Sprintf("argument %s[%d]", param.Name, i)
+		var err error
+		if param.UserDefinedType != nil {
+			values[i], err = conformToType(arg, param.UserDefinedType, scope, what)
+		} else {
+			values[i], err = conformToVarType(arg, param.Type, what)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := scope.DeclareVariable(param.Name, MakeArrayValue(values), false, ast.ArrayType)
+	return err
+}
+
+// declareParameter binds arg to param in scope, checking it against the
+// declared parameter type.
+func declareParameter(scope *Environment, param ast.Parameter, arg RuntimeValue) error {
+	if param.UserDefinedType != nil {
+		_, err := scope.DeclareUserTypedVariable(param.Name, arg, false, param.UserDefinedType)
+		return err
+	}
+
+	value, err := conformToVarType(arg, param.Type, "argument "+param.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = scope.DeclareVariable(param.Name, value, false, param.Type)
+	return err
+}
+
+func checkReturnValue(fn FunctionValue, result RuntimeValue) (RuntimeValue, error) {
+	if fn.UserDefinedReturnType != nil {
+		return conformToType(result, fn.UserDefinedReturnType, fn.DeclarationEnvironment, "return value of "+functionName(fn))
+	}
+
+	if result.VarType() != fn.ReturnType && fn.ReturnType != ast.AnyType && isNotANumber(result.Type(), fn.ReturnType) {
+		return nil, NewJamError(TypeError, "Return type does not match function return type")
+	}
+
+	return result, nil
+}
+
+// bindMethod returns method with `this` bound to instance, and `super` bound
+// to the parent of owner, the class that declares the method.
+func bindMethod(method FunctionValue, instance ObjectValue, owner *ClassValue) FunctionValue {
+	scope := NewEnvironment(method.DeclarationEnvironment)
+	scope.DeclareVariable("this", instance, true, ast.ObjectType)
+	if owner.Parent != nil {
+		scope.DeclareVariable("super", SuperValue{Class: owner.Parent, This: instance}, true, ast.AnyType)
+	}
+	method.DeclarationEnvironment = scope
+	return method
+}
+
+func instantiateClass(class *ClassValue, args []RuntimeValue, named []namedArgument) (RuntimeValue, error) {
+	instance := newObjectValue(class, len(class.Fields))
+
+	var hierarchy []*ClassValue
+	for c := class; c != nil; c = c.Parent {
+		hierarchy = append(hierarchy, c)
+	}
+	for i := len(hierarchy) - 1; i >= 0; i-- {
+		for _, name := range hierarchy[i].fieldNames {
+			instance.Set(name, hierarchy[i].Fields[name].Clone())
+		}
+	}
+
+	constructor, owner := class.FindConstructor()
+	if constructor == nil {
+		if len(args)+len(named) > 0 {
+			return nil, NewJamErrorf(ArgumentError, "%s has no constructor but was given %d arguments", class.Name, len(args)+len(named))
+		}
+		return instance, nil
+	}
+
+	if _, err := callFunctionValue(bindMethod(*constructor, instance, owner), args, named); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
 func isNotANumber(resultType ValueType, returnType ast.VariableType) bool {
 	return !(resultType == Number && returnType == ast.Int8Type) || !(resultType == Number && returnType == ast.Int16Type) || !(resultType == Number && returnType == ast.Int32Type) || !(resultType == Number && returnType == ast.Int64Type) || !(resultType == Number && returnType == ast.Float32Type) || !(resultType == Number && returnType == ast.Float64Type)
 }
 
-func EvaluateMemberExpression(expr ast.MemberExpression, env Environment) RuntimeValue {
+func EvaluateMemberExpression(expr ast.MemberExpression, env *Environment) (RuntimeValue, error) {
 	obj, err := Evaluate(expr.Object, env)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
 	}
 
 	if expr.Computed {
 		property, err := Evaluate(expr.Property, env)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(0)
-		}
-		if _, ok := obj.(ArrayValue); ok {
-			if _, ok := property.(IntValue); ok {
-				val := property.(IntValue).GetInt()
-				if val >= len(obj.(ArrayValue).Values) {
-					fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-					os.Exit(0)
-				}
+			return nil, err
+		}
+		return indexValue(obj, property)
+	}
 
-				if val < 0 {
-					if -val > len(obj.(ArrayValue).Values) {
-						fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-						os.Exit(0)
-					}
-					return obj.(ArrayValue).Values[val+len(obj.(ArrayValue).Values)]
-				}
+	return propertyValue(obj, expr.Property.(*ast.Identifier).Symbol)
+}
 
-				return obj.(ArrayValue).Values[int(val)]
+// indexValue evaluates obj[property].
+func indexValue(obj, property RuntimeValue) (RuntimeValue, error) {
+	if _, ok := obj.(*ArrayValue); ok {
+		if _, ok := property.(IntValue); ok {
+			val := property.(IntValue).GetInt()
+			if val >= len(obj.(*ArrayValue).Values) {
+				return nil, NewJamError(IndexError, "Index out of bounds")
 			}
 
-			fmt.Fprintln(os.Stderr, "Error: Index must be an integer")
-			os.Exit(0)
-		}
-
-		if _, ok := obj.(TupleValue); ok {
-			if _, ok := property.(IntValue); ok {
-				val := property.(IntValue).GetInt()
-				if val >= len(obj.(TupleValue).Values) {
-					fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-					os.Exit(0)
-				}
-
-				if val < 0 {
-					if -val > len(obj.(TupleValue).Values) {
-						fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-						os.Exit(0)
-					}
-					return obj.(TupleValue).Values[val+len(obj.(TupleValue).Values)]
+			if val < 0 {
+				if -val > len(obj.(*ArrayValue).Values) {
+					return nil, NewJamError(IndexError, "Index out of bounds")
 				}
-
-				return obj.(TupleValue).Values[int(val)]
+				return obj.(*ArrayValue).Values[val+len(obj.(*ArrayValue).Values)], nil
 			}
-			fmt.Fprintln(os.Stderr, "Error: Index must be an integer")
-			os.Exit(0)
+
+			return obj.(*ArrayValue).Values[int(val)], nil
 		}
 
-		if _, ok := obj.(StringValue); ok {
-			if int32(property.(IntValue).GetInt()) >= int32(len(obj.(StringValue).Value)) {
-				fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-				os.Exit(0)
+		return nil, NewJamError(TypeError, "Index must be an integer")
+	}
+
+	if _, ok := obj.(TupleValue); ok {
+		if _, ok := property.(IntValue); ok {
+			val := property.(IntValue).GetInt()
+			if val >= len(obj.(TupleValue).Values) {
+				return nil, NewJamError(IndexError, "Index out of bounds")
 			}
 
-			if property.(IntValue).GetInt() < 0 {
-				if -int32(property.(IntValue).GetInt()) > int32(len(obj.(StringValue).Value)) {
-					fmt.Fprintln(os.Stderr, "Error: Index out of bounds")
-					os.Exit(0)
+			if val < 0 {
+				if -val > len(obj.(TupleValue).Values) {
+					return nil, NewJamError(IndexError, "Index out of bounds")
 				}
-				return MakeStringValue(string(obj.(StringValue).Value[property.(IntValue).GetInt()+len(obj.(StringValue).Value)]))
+				return obj.(TupleValue).Values[val+len(obj.(TupleValue).Values)], nil
 			}
 
-			return MakeStringValue(string(obj.(StringValue).Value[property.(IntValue).GetInt()]))
+			return obj.(TupleValue).Values[int(val)], nil
+		}
+		return nil, NewJamError(TypeError, "Index must be an integer")
+	}
+
+	if m, ok := obj.(*MapValue); ok {
+		if value, found := m.entries.get(property); found {
+			return value, nil
+		}
+		if _, err := m.entries.hash(property); err != nil {
+			return nil, err
 		}
+		return MakeNullValue(), nil
+	}
 
-		if v, ok := property.(IntValue); ok {
-			if _, ok := obj.(ObjectValue).Properties[strconv.Itoa(v.GetInt())]; !ok {
-				return MakeNullValue()
+	if _, ok := obj.(StringValue); ok {
+		if _, ok := property.(IntValue); !ok {
+			return nil, NewJamError(TypeError, "Index must be an integer")
+		}
+		runes := []rune(obj.(StringValue).Value)
+		val := property.(IntValue).GetInt()
+		if val >= len(runes) {
+			return nil, NewJamError(IndexError, "Index out of bounds")
+		}
+
+		if val < 0 {
+			if -val > len(runes) {
+				return nil, NewJamError(IndexError, "Index out of bounds")
 			}
-			return obj.(ObjectValue).Properties[strconv.Itoa(v.GetInt())]
+			return MakeStringValue(string(runes[val+len(runes)])), nil
 		}
-		if _, ok := obj.(ObjectValue).Properties[property.(StringValue).Value]; !ok {
-			return MakeNullValue()
+
+		return MakeStringValue(string(runes[val])), nil
+	}
+
+	if _, ok := obj.(ObjectValue); !ok {
+		return nil, NewJamErrorf(TypeError, "Cannot index a value of type %s", obj.Type())
+	}
+	if v, ok := property.(IntValue); ok {
+		if _, ok := obj.(ObjectValue).Properties[strconv.Itoa(v.GetInt())]; !ok {
+			return MakeNullValue(), nil
 		}
-		return obj.(ObjectValue).Properties[property.(StringValue).Value]
-	} else {
-		if _, ok := obj.(NullValue); ok {
-			return obj
+		return obj.(ObjectValue).Properties[strconv.Itoa(v.GetInt())], nil
+	}
+	if _, ok := property.(StringValue); !ok {
+		return nil, NewJamErrorf(TypeError, "Object key must be a string, got %s", property.Type())
+	}
+	if _, ok := obj.(ObjectValue).Properties[property.(StringValue).Value]; !ok {
+		return MakeNullValue(), nil
+	}
+	return obj.(ObjectValue).Properties[property.(StringValue).Value], nil
+}
+
+// propertyValue evaluates obj.name, binding methods of class instances and
+// the builtin methods of arrays, tuples and strings.
+func propertyValue(obj RuntimeValue, name string) (RuntimeValue, error) {
+	if _, ok := obj.(NullValue); ok {
+		return obj, nil
+	}
+
+	if super, ok := obj.(SuperValue); ok {
+		method, owner := super.Class.FindMethod(name)
+		if method == nil {
+			return nil, NewJamErrorf(TypeError, "%s has no method %s", super.Class.Name, name)
 		}
+		return bindMethod(*method, super.This, owner), nil
+	}
 
-		if _, ok := obj.(ArrayValue); ok {
-			switch expr.Property.(*ast.Identifier).Symbol {
-			case "length":
-				return MakeInt32Value(int32(len(obj.(ArrayValue).Values)))
-			case "push":
-				if arr, ok := obj.(ArrayValue); ok {
-					a := &arr.Values
-					return jamlangArrayPush(&a)
-				}
-			case "pop":
-				return jamlangArrayPop(obj.(ArrayValue).Values)
-			case "shift":
-				return jamlangArrayShift(obj.(ArrayValue).Values)
-			case "contains":
-				return jamlangArrayContains(obj.(ArrayValue).Values)
-			case "insert":
-				return jamlangArrayInsertInto(obj.(ArrayValue).Values)
-			case "pushAll":
-				return jamlangArrayPushAll(obj.(ArrayValue).Values)
-			default:
-				fmt.Fprintln(os.Stderr, "Error: Array does not have property "+expr.Property.(*ast.Identifier).Symbol)
-				os.Exit(0)
-			}
+	if _, ok := obj.(*ArrayValue); ok {
+		switch name {
+		case "length":
+			return MakeInt32Value(int32(len(obj.(*ArrayValue).Values))), nil
+		case "push":
+			return jamlangArrayPush(obj.(*ArrayValue)), nil
+		case "pop":
+			return jamlangArrayPop(obj.(*ArrayValue)), nil
+		case "shift":
+			return jamlangArrayShift(obj.(*ArrayValue)), nil
+		case "contains":
+			return jamlangArrayContains(obj.(*ArrayValue)), nil
+		case "insert":
+			return jamlangArrayInsertInto(obj.(*ArrayValue)), nil
+		case "pushAll":
+			return jamlangArrayPushAll(obj.(*ArrayValue)), nil
+		case "clone":
+			return jamlangClone(obj), nil
+		case "map":
+			return jamlangArrayMap(obj.(*ArrayValue)), nil
+		case "filter":
+			return jamlangArrayFilter(obj.(*ArrayValue)), nil
+		case "reduce":
+			return jamlangArrayReduce(obj.(*ArrayValue)), nil
+		case "find":
+			return jamlangArrayFind(obj.(*ArrayValue)), nil
+		case "findIndex":
+			return jamlangArrayFindIndex(obj.(*ArrayValue)), nil
+		case "some":
+			return jamlangArraySome(obj.(*ArrayValue)), nil
+		case "every":
+			return jamlangArrayEvery(obj.(*ArrayValue)), nil
+		case "forEach":
+			return jamlangArrayForEach(obj.(*ArrayValue)), nil
+		case "slice":
+			return jamlangArraySlice(obj.(*ArrayValue)), nil
+		case "concat":
+			return jamlangArrayConcat(obj.(*ArrayValue)), nil
+		case "reverse":
+			return jamlangArrayReverse(obj.(*ArrayValue)), nil
+		case "join":
+			return jamlangArrayJoin(obj.(*ArrayValue)), nil
+		case "indexOf":
+			return jamlangArrayIndexOf(obj.(*ArrayValue)), nil
+		case "sort":
+			return jamlangArraySort(obj.(*ArrayValue)), nil
+		default:
+			return nil, NewJamError(TypeError, "Array does not have property "+name)
 		}
+	}
 
-		if _, ok := obj.(TupleValue); ok {
-			switch expr.Property.(*ast.Identifier).Symbol {
-			case "length":
-				return MakeInt64Value(int64(len(obj.(TupleValue).Values)))
-			default:
-				fmt.Fprintln(os.Stderr, "Error: Tuple does not have property "+expr.Property.(*ast.Identifier).Symbol)
-				os.Exit(0)
-			}
+	if _, ok := obj.(TupleValue); ok {
+		switch name {
+		case "length":
+			return MakeInt64Value(int64(len(obj.(TupleValue).Values))), nil
+		default:
+			return nil, NewJamError(TypeError, "Tuple does not have property "+name)
 		}
+	}
 
-		if _, ok := obj.(StringValue); ok {
-			switch expr.Property.(*ast.Identifier).Symbol {
-			case "length":
-				return MakeInt32Value(int32(len(obj.(StringValue).Value)))
-			case "shift":
-				return jamlangStringShift(obj.(StringValue).Value)
-			case "push":
-				return jamlangStringPush(obj.(StringValue).Value)
-			case "pop":
-				return jamlangStringPop(obj.(StringValue).Value)
-			case "toUpper":
-				return jamlangStringToUpper(obj.(StringValue).Value)
-			case "toLower":
-				return jamlangStringToLower(obj.(StringValue).Value)
-			case "contains":
-				return jamlangStringContains(obj.(StringValue).Value)
-			case "split":
-				return jamlangStringSplit(obj.(StringValue).Value)
-			case "equalsIgnoreCase":
-				return jamlangStringEqualsIgnoreCase(obj.(StringValue).Value)
-			case "startsWith":
-				return jamlangStringStartsWith(obj.(StringValue).Value)
-			case "endsWith":
-				return jamlangStringEndsWith(obj.(StringValue).Value)
-			case "indexOf":
-				return jamlangStringIndexOf(obj.(StringValue).Value)
-			case "lastIndexOf":
-				return jamlangStringLastIndexOf(obj.(StringValue).Value)
-			case "substring":
-				return jamlangStringSubstring(obj.(StringValue).Value)
-			case "replace":
-				return jamlangStringReplace(obj.(StringValue).Value)
-			case "trim":
-				return jamlangStringTrim(obj.(StringValue).Value)
-			case "trimLeft":
-				return jamlangStringTrimLeft(obj.(StringValue).Value)
-			case "trimRight":
-				return jamlangStringTrimRight(obj.(StringValue).Value)
-			case "repeat":
-				return jamlangStringRepeat(obj.(StringValue).Value)
-			case "leftPad":
-				return jamlangStringLeftPad(obj.(StringValue).Value)
-			case "rightPad":
-				return jamlangStringRightPad(obj.(StringValue).Value)
-			default:
-				fmt.Fprintln(os.Stderr, "Error: String has no property "+expr.Property.(*ast.Identifier).Symbol)
-				os.Exit(0)
-			}
+	if _, ok := obj.(StringValue); ok {
+		switch name {
+		case "length":
+			return MakeInt32Value(int32(utf8.RuneCountInString(obj.(StringValue).Value))), nil
+		case "bytes":
+			return jamlangStringBytes(obj.(StringValue).Value), nil
+		case "runes":
+			return jamlangStringRunes(obj.(StringValue).Value), nil
+		case "shift":
+			return jamlangStringShift(obj.(StringValue).Value), nil
+		case "push":
+			return jamlangStringPush(obj.(StringValue).Value), nil
+		case "pop":
+			return jamlangStringPop(obj.(StringValue).Value), nil
+		case "toUpper":
+			return jamlangStringToUpper(obj.(StringValue).Value), nil
+		case "toLower":
+			return jamlangStringToLower(obj.(StringValue).Value), nil
+		case "contains":
+			return jamlangStringContains(obj.(StringValue).Value), nil
+		case "split":
+			return jamlangStringSplit(obj.(StringValue).Value), nil
+		case "equalsIgnoreCase":
+			return jamlangStringEqualsIgnoreCase(obj.(StringValue).Value), nil
+		case "startsWith":
+			return jamlangStringStartsWith(obj.(StringValue).Value), nil
+		case "endsWith":
+			return jamlangStringEndsWith(obj.(StringValue).Value), nil
+		case "indexOf":
+			return jamlangStringIndexOf(obj.(StringValue).Value), nil
+		case "lastIndexOf":
+			return jamlangStringLastIndexOf(obj.(StringValue).Value), nil
+		case "substring":
+			return jamlangStringSubstring(obj.(StringValue).Value), nil
+		case "replace":
+			return jamlangStringReplace(obj.(StringValue).Value), nil
+		case "trim":
+			return jamlangStringTrim(obj.(StringValue).Value), nil
+		case "trimLeft":
+			return jamlangStringTrimLeft(obj.(StringValue).Value), nil
+		case "trimRight":
+			return jamlangStringTrimRight(obj.(StringValue).Value), nil
+		case "repeat":
+			return jamlangStringRepeat(obj.(StringValue).Value), nil
+		case "leftPad":
+			return jamlangStringLeftPad(obj.(StringValue).Value), nil
+		case "rightPad":
+			return jamlangStringRightPad(obj.(StringValue).Value), nil
+		default:
+			return nil, NewJamError(TypeError, "String has no property "+name)
 		}
+	}
 
-		if _, ok := obj.(ObjectValue); !ok {
-			fmt.Fprintf(os.Stderr, "Error: %s has no property %s\n", obj.Type(), expr.Property.(*ast.Identifier).Symbol)
-			os.Exit(0)
+	if m, ok := obj.(*MapValue); ok {
+		switch name {
+		case "size":
+			return MakeInt32Value(int32(m.entries.len())), nil
+		case "get":
+			return jamlangMapGet(m), nil
+		case "set":
+			return jamlangMapSet(m), nil
+		case "has":
+			return jamlangHashTableHas(&m.entries), nil
+		case "delete":
+			return jamlangHashTableDelete(&m.entries), nil
+		case "clear":
+			return jamlangHashTableClear(&m.entries), nil
+		case "keys":
+			return jamlangHashTableKeys(&m.entries), nil
+		case "values":
+			return jamlangHashTableValues(&m.entries), nil
+		case "entries":
+			return jamlangHashTableEntries(&m.entries), nil
+		case "clone":
+			return jamlangClone(m), nil
+		default:
+			return nil, NewJamError(TypeError, "Map has no property "+name)
 		}
+	}
 
-		return obj.(ObjectValue).Properties[expr.Property.(*ast.Identifier).Symbol]
+	if set, ok := obj.(*SetValue); ok {
+		switch name {
+		case "size":
+			return MakeInt32Value(int32(set.entries.len())), nil
+		case "add":
+			return jamlangSetAdd(set), nil
+		case "has":
+			return jamlangHashTableHas(&set.entries), nil
+		case "delete":
+			return jamlangHashTableDelete(&set.entries), nil
+		case "clear":
+			return jamlangHashTableClear(&set.entries), nil
+		case "keys", "values":
+			return jamlangHashTableKeys(&set.entries), nil
+		case "entries":
+			return jamlangHashTableEntries(&set.entries), nil
+		case "clone":
+			return jamlangClone(set), nil
+		default:
+			return nil, NewJamError(TypeError, "Set has no property "+name)
+		}
 	}
-}
 
-func EvaluateArrayExpression(expr ast.ArrayLiteral, env Environment) RuntimeValue {
-	array := ArrayValue{
-		Values: make([]RuntimeValue, len(expr.Elements)),
+	if result, ok := obj.(ResultValue); ok {
+		switch name {
+		case "isOk":
+			return jamlangResultIsOk(result), nil
+		case "isErr":
+			return jamlangResultIsErr(result), nil
+		case "unwrap":
+			return jamlangResultUnwrap(result), nil
+		case "unwrapErr":
+			return jamlangResultUnwrapErr(result), nil
+		case "unwrapOr":
+			return jamlangResultUnwrapOr(result), nil
+		case "map":
+			return jamlangResultMap(result), nil
+		default:
+			return nil, NewJamError(TypeError, "Result has no property "+name)
+		}
+	}
+
+	if _, ok := obj.(ObjectValue); !ok {
+		return nil, NewJamErrorf(TypeError, "%s has no property %s", obj.Type(), name)
+	}
+
+	object := obj.(ObjectValue)
+	if value, ok := object.Properties[name]; ok {
+		return value, nil
+	}
+	if object.Class != nil {
+		if method, owner := object.Class.FindMethod(name); method != nil {
+			return bindMethod(*method, object, owner), nil
+		}
+	}
+	if name == "clone" {
+		return jamlangClone(object), nil
+	}
+	if object.Class == nil {
+		return MakeNullValue(), nil
 	}
+	return nil, NewJamErrorf(TypeError, "%s has no property %s", object.Class.Name, name)
+}
+
+func EvaluateArrayExpression(expr ast.ArrayLiteral, env *Environment) (RuntimeValue, error) {
+	array := MakeArrayValue(make([]RuntimeValue, len(expr.Elements)))
 
 	for i, element := range expr.Elements {
-		array.Values[i], _ = Evaluate(element, env)
+		value, err := Evaluate(element, env)
+		if err != nil {
+			return nil, err
+		}
+		array.Values[i] = value
 	}
 
-	return array
+	return array, nil
 }
 
-func EvaluateTupleExpression(expr ast.TupleLiteral, env Environment) RuntimeValue {
+func EvaluateTupleExpression(expr ast.TupleLiteral, env *Environment) (RuntimeValue, error) {
 	tuple := TupleValue{
 		Values: make([]RuntimeValue, len(expr.Elements)),
 	}
 
 	for i, element := range expr.Elements {
-		tuple.Values[i], _ = Evaluate(element, env)
+		value, err := Evaluate(element, env)
+		if err != nil {
+			return nil, err
+		}
+		tuple.Values[i] = value
 	}
 
-	return tuple
+	return tuple, nil
 }
 
-func EvaluateObjectExpression(obj ast.ObjectLiteral, env Environment) RuntimeValue {
-	object := ObjectValue{
-		Properties: make(map[string]RuntimeValue),
+func EvaluateTemplateLiteral(expr ast.TemplateLiteral, env *Environment) (RuntimeValue, error) {
+	values := make([]RuntimeValue, len(expr.Expressions))
+	for i, expression := range expr.Expressions {
+		value, err := Evaluate(expression, env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
 	}
 
+	result := interpolate(expr.Strings, values)
+	return result, env.engine.checkValue(result)
+}
+
+// interpolate joins the text of a template literal with its evaluated
+// expressions, formatted the way println prints them.
+func interpolate(texts []string, values []RuntimeValue) StringValue {
+	var sb strings.Builder
+	sb.WriteString(texts[0])
+	for i, value := range values {
+		fmt.Fprint(&sb, value.Get())
+		sb.WriteString(texts[i+1])
+	}
+	return MakeStringValue(sb.String())
+}
+
+func EvaluateObjectExpression(obj ast.ObjectLiteral, env *Environment) (RuntimeValue, error) {
+	object := newObjectValue(nil, len(obj.Properties))
+
 	for _, property := range obj.Properties {
 		key := property.Key
 		var value RuntimeValue
@@ -333,11 +786,13 @@ func EvaluateObjectExpression(obj ast.ObjectLiteral, env Environment) RuntimeVal
 		if property.Value != nil {
 			value, err = Evaluate(property.Value, env)
 			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(0)
+				return nil, err
 			}
 		} else {
-			value = env.LookupVariable(key)
+			value, err = env.LookupVariable(key)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		var runtimeValue RuntimeValue
@@ -347,15 +802,14 @@ func EvaluateObjectExpression(obj ast.ObjectLiteral, env Environment) RuntimeVal
 			runtimeValue = value
 		}
 
-		object.Properties[key] = runtimeValue
+		object.Set(key, runtimeValue)
 	}
 
-	return object
+	return object, nil
 }
 
-func EvaluateStatement(statement ast.Statement, env Environment) RuntimeValue {
-	value, _ := Evaluate(statement, env)
-	return value
+func EvaluateStatement(statement ast.Statement, env *Environment) (RuntimeValue, error) {
+	return Evaluate(statement, env)
 }
 
 func isNumber(value RuntimeValue) bool {
@@ -377,572 +831,618 @@ func isNumber(value RuntimeValue) bool {
 	}
 }
 
-func EvaluateBinaryExpression(binaryExpression ast.BinaryExpression, env Environment) RuntimeValue {
+func EvaluateBinaryExpression(binaryExpression ast.BinaryExpression, env *Environment) (RuntimeValue, error) {
 	lhs, err := Evaluate(binaryExpression.Left, env)
-	if lhs == nil {
-		fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on null")
-		os.Exit(0)
-	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
 	}
 	rhs, err := Evaluate(binaryExpression.Right, env)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
+	}
+
+	result, err := binaryOperation(lhs, rhs, binaryExpression.Operator)
+	if err != nil {
+		return nil, err
+	}
+	return result, env.engine.checkValue(result)
+}
+
+// binaryOperation applies operator to already evaluated operands.
+func binaryOperation(lhs, rhs RuntimeValue, operator string) (RuntimeValue, error) {
+	if lhs == nil {
+		return nil, NewJamError(TypeError, "Cannot perform operation on null")
 	}
 
 	switch lhs.Type() {
 	case I8:
 		if isNumber(rhs) {
-			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, binaryExpression.Operator)
+			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, operator)
 		} else if rhs.Type() == String {
 			i8Value := lhs.(Int8Value)
-			return EvaluateNumericStringBinaryExpression(float64(i8Value.Value), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(float64(i8Value.Value), rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, binaryExpression.Operator)
+			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, binaryExpression.Operator)
+			return EvaluateI8BinaryExpression(lhs.(Int8Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case I16:
 		if isNumber(rhs) {
-			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, binaryExpression.Operator)
+			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, operator)
 		} else if rhs.Type() == String {
 			i16Value := lhs.(Int16Value)
-			return EvaluateNumericStringBinaryExpression(float64(i16Value.Value), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(float64(i16Value.Value), rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, binaryExpression.Operator)
+			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, binaryExpression.Operator)
+			return EvaluateI16BinaryExpression(lhs.(Int16Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case I32:
 		if isNumber(rhs) {
-			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, binaryExpression.Operator)
+			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, operator)
 		} else if rhs.Type() == String {
 			i32Value := lhs.(Int32Value)
-			return EvaluateNumericStringBinaryExpression(float64(i32Value.Value), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(float64(i32Value.Value), rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, binaryExpression.Operator)
+			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, binaryExpression.Operator)
+			return EvaluateI32BinaryExpression(lhs.(Int32Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case I64:
 		if isNumber(rhs) {
-			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, binaryExpression.Operator)
+			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, operator)
 		} else if rhs.Type() == String {
 			i64Value := lhs.(Int64Value)
-			return EvaluateNumericStringBinaryExpression(float64(i64Value.Value), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(float64(i64Value.Value), rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, binaryExpression.Operator)
+			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, binaryExpression.Operator)
+			return EvaluateI64BinaryExpression(lhs.(Int64Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case F32:
 		if isNumber(rhs) {
-			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, binaryExpression.Operator)
+			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, operator)
 		} else if rhs.Type() == String {
 			f32Value := lhs.(Float32Value)
-			return EvaluateNumericStringBinaryExpression(float64(f32Value.Value), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(float64(f32Value.Value), rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, binaryExpression.Operator)
+			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, binaryExpression.Operator)
+			return EvaluateF32BinaryExpression(lhs.(Float32Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case F64:
 		if isNumber(rhs) {
-			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, binaryExpression.Operator)
+			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, operator)
 		} else if rhs.Type() == String {
 			f64Value := lhs.(Float64Value)
-			return EvaluateNumericStringBinaryExpression(f64Value.Value, rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateNumericStringBinaryExpression(f64Value.Value, rhs.(StringValue), operator)
 		} else if rhs.Type() == Bool {
-			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, binaryExpression.Operator)
+			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, operator)
 		} else if rhs.Type() == Null {
-			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, binaryExpression.Operator)
+			return EvaluateF64BinaryExpression(lhs.(Float64Value), rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot perform operation on "+lhs.Type()+" and "+rhs.Type()+", you need to cast one of them to the other type")
-			os.Exit(0)
+			return nil, NewJamErrorf(TypeError, "Cannot perform operation on %s and %s, you need to cast one of them to the other type", lhs.Type(), rhs.Type())
 		}
 	case String:
 		if rhs.Type() == String {
-			return EvaluateStringBinaryExpression(lhs.(StringValue), rhs.(StringValue), binaryExpression.Operator)
+			return EvaluateStringBinaryExpression(lhs.(StringValue), rhs.(StringValue), operator)
 		}
 		if rhs.Type() == I8 || rhs.Type() == I16 || rhs.Type() == I32 || rhs.Type() == I64 || rhs.Type() == F32 || rhs.Type() == F64 {
 			switch rhs.Type() {
 			case I8:
 				i8Value := rhs.(Int8Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i8Value.Value), binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i8Value.Value), operator)
 			case I16:
 				i16Value := rhs.(Int16Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i16Value.Value), binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i16Value.Value), operator)
 			case I32:
 				i32Value := rhs.(Int32Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i32Value.Value), binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i32Value.Value), operator)
 			case I64:
 				i64Value := rhs.(Int64Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i64Value.Value), binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(i64Value.Value), operator)
 			case F32:
 				f32Value := rhs.(Float32Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(f32Value.Value), binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), float64(f32Value.Value), operator)
 			case F64:
 				f64Value := rhs.(Float64Value)
-				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), f64Value.Value, binaryExpression.Operator)
+				return EvaluateStringNumericBinaryExpression(lhs.(StringValue), f64Value.Value, operator)
 			}
 		}
+	case Class:
+		switch operator {
+		case "==":
+			return MakeBoolValue(lhs.Equals(rhs)), nil
+		case "!=":
+			return MakeBoolValue(!lhs.Equals(rhs)), nil
+		}
+		return nil, NewJamErrorf(TypeError, "Cannot use operator %s on a class", operator)
+	case Result:
+		switch operator {
+		case "==":
+			return MakeBoolValue(lhs.Equals(rhs)), nil
+		case "!=":
+			return MakeBoolValue(!lhs.Equals(rhs)), nil
+		}
+		return nil, NewJamErrorf(TypeError, "Cannot use operator %s on a result", operator)
+	case Map, Set:
+		switch operator {
+		case "==":
+			return MakeBoolValue(lhs.Equals(rhs)), nil
+		case "!=":
+			return MakeBoolValue(!lhs.Equals(rhs)), nil
+		}
+		return nil, NewJamErrorf(TypeError, "Cannot use operator %s on a %s", operator, lhs.Type())
 	case Null:
 		if rhs.Type() == Null {
-			return EvaluateNullBinaryExpression(lhs, rhs, binaryExpression.Operator)
+			return EvaluateNullBinaryExpression(lhs, rhs, operator)
 		}
 	case Object:
 		if rhs.Type() == Object {
 			if _, ok := rhs.(NullValue); ok {
-				return EvaluateNullBinaryExpression(lhs, rhs, binaryExpression.Operator)
+				return EvaluateNullBinaryExpression(lhs, rhs, operator)
+			}
+			if _, ok := lhs.(NullValue); ok {
+				return EvaluateNullBinaryExpression(lhs, rhs, operator)
 			}
-			return EvaluateObjectBinaryExpression(lhs.(ObjectValue), rhs.(ObjectValue), binaryExpression.Operator)
+			return EvaluateObjectBinaryExpression(lhs.(ObjectValue), rhs.(ObjectValue), operator)
 		} else if rhs.Type() == Null {
-			return EvaluateNullBinaryExpression(lhs, rhs, binaryExpression.Operator)
+			return EvaluateNullBinaryExpression(lhs, rhs, operator)
 		} else {
-			fmt.Fprintln(os.Stderr, "Error: Cannot use operator "+binaryExpression.Operator+" on "+string(lhs.Type())+" and "+string(rhs.Type()))
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "Cannot use operator "+operator+" on "+string(lhs.Type())+" and "+string(rhs.Type()))
 		}
 	}
 
-	return MakeNullValue()
+	return MakeNullValue(), nil
 }
 
-func EvaluateObjectBinaryExpression(lhs ObjectValue, rhs ObjectValue, op string) RuntimeValue {
+func EvaluateObjectBinaryExpression(lhs ObjectValue, rhs ObjectValue, op string) (RuntimeValue, error) {
 	if op == "==" {
 		for key, value := range lhs.Properties {
 			if !value.Equals(rhs.Properties[key]) {
-				return BoolValue{false}
+				return BoolValue{false}, nil
 			}
 		}
-		return BoolValue{true}
+		return BoolValue{true}, nil
 	} else if op == "!=" {
 		for key, value := range lhs.Properties {
 			if !value.Equals(rhs.Properties[key]) {
-				return BoolValue{true}
+				return BoolValue{true}, nil
 			}
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	}
 
-	return MakeNullValue()
+	return MakeNullValue(), nil
 }
 
-func EvaluateNullBinaryExpression(lhs RuntimeValue, rhs RuntimeValue, op string) RuntimeValue {
-	if lhs.Type() == Null && rhs.Type() == Null {
-		if op == "==" {
-			return BoolValue{true}
-		}
-		return BoolValue{false}
-	} else if lhs.Type() == Bool && rhs.Type() == Null {
-		if op == "==" {
-			return BoolValue{false}
-		}
-		return BoolValue{true}
-	} else if lhs.Type() == Null && rhs.Type() == Bool {
-		if op == "==" {
-			return BoolValue{false}
-		}
-		return BoolValue{true}
-	} else if lhs.Type() == Object && rhs.Type() == Null {
-		if op == "==" {
-			return BoolValue{false}
-		}
-		return BoolValue{true}
-	} else if lhs.Type() == Null && rhs.Type() == Object {
-		if op == "==" {
-			return BoolValue{false}
-		}
-		return BoolValue{true}
+func EvaluateNullBinaryExpression(lhs RuntimeValue, rhs RuntimeValue, op string) (RuntimeValue, error) {
+	_, lhsIsNull := lhs.(NullValue)
+	_, rhsIsNull := rhs.(NullValue)
+	equal := lhsIsNull && rhsIsNull
+
+	switch op {
+	case "==":
+		return BoolValue{equal}, nil
+	case "!=":
+		return BoolValue{!equal}, nil
 	}
 
-	return MakeBoolValue(false)
+	return MakeBoolValue(false), nil
 }
 
-func EvaluateStringNumericBinaryExpression(lhs StringValue, rhs float64, op string) RuntimeValue {
+func EvaluateStringNumericBinaryExpression(lhs StringValue, rhs float64, op string) (RuntimeValue, error) {
 	if op == "+" {
 		rhsAsString := strconv.FormatFloat(rhs, 'f', -1, 64)
-		return StringValue{lhs.Value + rhsAsString}
+		return StringValue{lhs.Value + rhsAsString}, nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Unknown operator %s for string\n", op)
-	os.Exit(0)
-	return nil
+	return nil, NewJamErrorf(RuntimeError, "Unknown operator %s for string", op)
 }
 
-func EvaluateNumericStringBinaryExpression(lhs float64, rhs StringValue, op string) RuntimeValue {
+func EvaluateNumericStringBinaryExpression(lhs float64, rhs StringValue, op string) (RuntimeValue, error) {
 	if op == "+" {
 		lhsAsString := strconv.FormatFloat(lhs, 'f', -1, 64)
-		return StringValue{lhsAsString + rhs.Value}
+		return StringValue{lhsAsString + rhs.Value}, nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Unknown operator %s for string\n", op)
-	os.Exit(0)
-	return nil
+	return nil, NewJamErrorf(RuntimeError, "Unknown operator %s for string", op)
 }
 
-func EvaluateStringBinaryExpression(lhs, rhs StringValue, op string) RuntimeValue {
+func EvaluateStringBinaryExpression(lhs, rhs StringValue, op string) (RuntimeValue, error) {
 	if op == "+" {
-		return StringValue{lhs.Value + rhs.Value}
+		return StringValue{lhs.Value + rhs.Value}, nil
 	} else if op == "==" {
-		return BoolValue{lhs.Value == rhs.Value}
+		return BoolValue{lhs.Value == rhs.Value}, nil
 	} else if op == "!=" {
-		return BoolValue{lhs.Value != rhs.Value}
+		return BoolValue{lhs.Value != rhs.Value}, nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Unknown operator %s for string\n", op)
-	os.Exit(0)
-	return nil
+	return nil, NewJamErrorf(RuntimeError, "Unknown operator %s for string", op)
 }
 
-func EvaluateUnaryExpression(node ast.UnaryExpression, env Environment) RuntimeValue {
+func EvaluateUnaryExpression(node ast.UnaryExpression, env *Environment) (RuntimeValue, error) {
 	value, err := Evaluate(node.Value, env)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
 	}
 
 	switch node.Operator {
+	case "++", "--":
+		return evaluateIncrement(node, value, env)
+	default:
+		return unaryOperation(value, node.Operator)
+	}
+}
+
+// unaryOperation applies one of the operators !, - and + to value.
+func unaryOperation(value RuntimeValue, operator string) (RuntimeValue, error) {
+	switch operator {
 	case "!":
 		if value.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: ! operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
-		}
-		return BoolValue{!value.(BoolValue).Value}
-	case "++":
-		if value.Type() != I8 && value.Type() != I16 && value.Type() != I32 && value.Type() != I64 && value.Type() != F32 && value.Type() != F64 {
-			fmt.Fprintln(os.Stderr, "Error: ++ operator can only be applied to number values")
-			os.Exit(0)
-			return nil
-		}
-		if node.Value.Kind() == ast.MemberExpressionType {
-			val, err := Evaluate(node.Value.(*ast.MemberExpression).Property, env)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(0)
-			}
-			arr, err := Evaluate(node.Value.(*ast.MemberExpression).Object, env)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(0)
-			}
-
-			switch val.Type() {
-			case I8:
-				i8Value := val.(Int8Value)
-				arr.(ArrayValue).Values[i8Value.Value] = Int8Value{i8Value.Value + 1}
-				return Int8Value{i8Value.Value + 1}
-			case I16:
-				i16Value := val.(Int16Value)
-				arr.(ArrayValue).Values[i16Value.Value] = Int16Value{i16Value.Value + 1}
-				return Int16Value{i16Value.Value + 1}
-			case I32:
-				i32Value := val.(Int32Value)
-				arr.(ArrayValue).Values[i32Value.Value] = Int32Value{i32Value.Value + 1}
-				return Int32Value{i32Value.Value + 1}
-			case I64:
-				i64Value := val.(Int64Value)
-				arr.(ArrayValue).Values[i64Value.Value] = Int64Value{i64Value.Value + 1}
-				return Int64Value{i64Value.Value + 1}
-			case F32:
-				f32Value := val.(Float32Value)
-				arr.(ArrayValue).Values[int(f32Value.Value)] = Float32Value{f32Value.Value + 1}
-				return Float32Value{f32Value.Value + 1}
-			case F64:
-				f64Value := val.(Float64Value)
-				arr.(ArrayValue).Values[int(f64Value.Value)] = Float64Value{f64Value.Value + 1}
-				return Float64Value{f64Value.Value + 1}
-			default:
-				fmt.Fprintln(os.Stderr, "Error: ++ operator can only be applied to number values")
-				os.Exit(0)
-			}
-		}
-		switch value.Type() {
-		case I8:
-			i8Value := value.(Int8Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int8Value{i8Value.Value + 1})
-			return Int8Value{i8Value.Value + 1}
-		case I16:
-			i16Value := value.(Int16Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int16Value{i16Value.Value + 1})
-			return Int16Value{i16Value.Value + 1}
-		case I32:
-			i32Value := value.(Int32Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int32Value{i32Value.Value + 1})
-			return Int32Value{i32Value.Value + 1}
-		case I64:
-			i64Value := value.(Int64Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int64Value{i64Value.Value + 1})
-			return Int64Value{i64Value.Value + 1}
-		case F32:
-			f32Value := value.(Float32Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Float32Value{f32Value.Value + 1})
-			return Float32Value{f32Value.Value + 1}
-		case F64:
-			f64Value := value.(Float64Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Float64Value{f64Value.Value + 1})
-			return Float64Value{f64Value.Value + 1}
-		default:
-			fmt.Fprintln(os.Stderr, "Error: ++ operator can only be applied to number values")
-			os.Exit(0)
-		}
-	case "--":
-		switch value.Type() {
-		case I8:
-			i8Value := value.(Int8Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int8Value{i8Value.Value - 1})
-			return Int8Value{i8Value.Value - 1}
-		case I16:
-			i16Value := value.(Int16Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int16Value{i16Value.Value - 1})
-			return Int16Value{i16Value.Value - 1}
-		case I32:
-			i32Value := value.(Int32Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int32Value{i32Value.Value - 1})
-			return Int32Value{i32Value.Value - 1}
-		case I64:
-			i64Value := value.(Int64Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Int64Value{i64Value.Value - 1})
-			return Int64Value{i64Value.Value - 1}
-		case F32:
-			f32Value := value.(Float32Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Float32Value{f32Value.Value - 1})
-			return Float32Value{f32Value.Value - 1}
-		case F64:
-			f64Value := value.(Float64Value)
-			env.AssignVariable(node.Value.(*ast.Identifier).Symbol, Float64Value{f64Value.Value - 1})
-			return Float64Value{f64Value.Value - 1}
-		default:
-			fmt.Fprintln(os.Stderr, "Error: -- operator can only be applied to number values")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "! operator can only be applied to boolean values")
 		}
+		return BoolValue{!value.(BoolValue).Value}, nil
 	case "-":
 		switch value.Type() {
 		case I8:
 			i8Value := value.(Int8Value)
-			return Int8Value{-i8Value.Value}
+			return Int8Value{-i8Value.Value}, nil
 		case I16:
 			i16Value := value.(Int16Value)
-			return Int16Value{-i16Value.Value}
+			return Int16Value{-i16Value.Value}, nil
 		case I32:
 			i32Value := value.(Int32Value)
-			return Int32Value{-i32Value.Value}
+			return Int32Value{-i32Value.Value}, nil
 		case I64:
 			i64Value := value.(Int64Value)
-			return Int64Value{-i64Value.Value}
+			return Int64Value{-i64Value.Value}, nil
 		case F32:
 			f32Value := value.(Float32Value)
-			return Float32Value{-f32Value.Value}
+			return Float32Value{-f32Value.Value}, nil
 		case F64:
 			f64Value := value.(Float64Value)
-			return Float64Value{-f64Value.Value}
+			return Float64Value{-f64Value.Value}, nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: - operator can only be applied to number values")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "- operator can only be applied to number values")
 		}
 	case "+":
 		switch value.Type() {
 		case I8:
 			i8Value := value.(Int8Value)
-			return Int8Value{i8Value.Value}
+			return Int8Value{i8Value.Value}, nil
 		case I16:
 			i16Value := value.(Int16Value)
-			return Int16Value{i16Value.Value}
+			return Int16Value{i16Value.Value}, nil
 		case I32:
 			i32Value := value.(Int32Value)
-			return Int32Value{i32Value.Value}
+			return Int32Value{i32Value.Value}, nil
 		case I64:
 			i64Value := value.(Int64Value)
-			return Int64Value{i64Value.Value}
+			return Int64Value{i64Value.Value}, nil
 		case F32:
 			f32Value := value.(Float32Value)
-			return Float32Value{f32Value.Value}
+			return Float32Value{f32Value.Value}, nil
 		case F64:
 			f64Value := value.(Float64Value)
-			return Float64Value{f64Value.Value}
+			return Float64Value{f64Value.Value}, nil
 		default:
-			fmt.Fprintln(os.Stderr, "Error: - operator can only be applied to number values")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "- operator can only be applied to number values")
 		}
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", node.Operator)
-		os.Exit(0)
-		return nil
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", operator)
+	}
+}
+
+// evaluateIncrement applies ++ or -- to the variable or member node.Value,
+// whose current value is value.
+func evaluateIncrement(node ast.UnaryExpression, value RuntimeValue, env *Environment) (RuntimeValue, error) {
+	next, err := stepValue(value, node.Operator)
+	if err != nil {
+		return nil, err
+	}
+
+	switch target := node.Value.(type) {
+	case *ast.Identifier:
+		return env.AssignVariable(target.Symbol, next)
+	case *ast.MemberExpression:
+		object, err := Evaluate(target.Object, env)
+		if err != nil {
+			return nil, err
+		}
+		property, err := memberKey(target, env)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := assignMember(object, property, next); err != nil {
+			return nil, err
+		}
+		return next, nil
+	default:
+		return nil, NewJamErrorf(TypeError, "%s operator can only be applied to variables and members", node.Operator)
+	}
+}
+
+// stepValue returns value incremented by one for ++ and decremented by one
+// for --.
+func stepValue(value RuntimeValue, operator string) (RuntimeValue, error) {
+	var delta int8 = 1
+	if operator == "--" {
+		delta = -1
+	}
+
+	switch value := value.(type) {
+	case Int8Value:
+		return Int8Value{value.Value + delta}, nil
+	case Int16Value:
+		return Int16Value{value.Value + int16(delta)}, nil
+	case Int32Value:
+		return Int32Value{value.Value + int32(delta)}, nil
+	case Int64Value:
+		return Int64Value{value.Value + int64(delta)}, nil
+	case Float32Value:
+		return Float32Value{value.Value + float32(delta)}, nil
+	case Float64Value:
+		return Float64Value{value.Value + float64(delta)}, nil
+	default:
+		return nil, NewJamErrorf(TypeError, "%s operator can only be applied to number values", operator)
 	}
-	return nil
 }
 
-func EvaluateLogicalExpression(node ast.LogicalExpression, env Environment) RuntimeValue {
+func EvaluateLogicalExpression(node ast.LogicalExpression, env *Environment) (RuntimeValue, error) {
 	switch node.Operator {
 	case "and":
 		left, err := Evaluate(node.Left, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if left.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: and operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "and operator can only be applied to boolean values")
 		}
 		if left.(BoolValue).Value == false {
-			return BoolValue{false}
+			return BoolValue{false}, nil
 		}
 
 		right, err := Evaluate(node.Right, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if right.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: and operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "and operator can only be applied to boolean values")
 		}
-		return BoolValue{right.(BoolValue).Value}
+		return BoolValue{right.(BoolValue).Value}, nil
 	case "or":
 		left, err := Evaluate(node.Left, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if left.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: or operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "or operator can only be applied to boolean values")
 		}
 		if left.(BoolValue).Value == true {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
 
 		right, err := Evaluate(node.Right, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if right.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: or operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "or operator can only be applied to boolean values")
 		}
-		return BoolValue{right.(BoolValue).Value}
+		return BoolValue{right.(BoolValue).Value}, nil
 	case "xor":
 		left, err := Evaluate(node.Left, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if left.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: xor operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "xor operator can only be applied to boolean values")
 		}
 
 		right, err := Evaluate(node.Right, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		if right.Type() != Bool {
-			fmt.Fprintln(os.Stderr, "Error: xor operator can only be applied to boolean values")
-			os.Exit(0)
-			return nil
+			return nil, NewJamError(TypeError, "xor operator can only be applied to boolean values")
 		}
-		return BoolValue{left.(BoolValue).Value != right.(BoolValue).Value}
+		return BoolValue{left.(BoolValue).Value != right.(BoolValue).Value}, nil
 	case "not":
 		operand, err := Evaluate(node.Right, env)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 
-		if operand != nil {
-			if isNumber(operand) {
-				if operand.(IntValue).GetInt() == 0 {
-					return BoolValue{true}
-				}
-				return BoolValue{false}
-			}
-			if operand.Type() == Null {
-				return BoolValue{true}
-			}
-			if operand.Type() != Bool {
-				fmt.Fprintln(os.Stderr, "Error: not operator can only be applied to boolean values")
-				os.Exit(0)
-				return nil
-			}
-			return BoolValue{!operand.(BoolValue).Value}
-		}
-		return BoolValue{false}
+		return logicalNot(operand)
 	default:
-		fmt.Fprintln(os.Stderr, "Error: unknown operator")
-		os.Exit(0)
-		return nil
-	}
-}
-
-func EvaluateAssignment(node ast.AssignmentExpression, env Environment) RuntimeValue {
-	if node.Assignee.Kind() == ast.MemberExpressionType {
-		objectLiteral := node.Assignee.(*ast.MemberExpression).Object
-		objectValue, _ := Evaluate(objectLiteral, env)
-		if objectValue.Type() == Array {
-			index, _ := Evaluate(node.Assignee.(*ast.MemberExpression).Property, env)
-			if index.Type() != I8 && index.Type() != I16 && index.Type() != I32 && index.Type() != I64 {
-				fmt.Fprintln(os.Stderr, "Error: array index must be a number")
-				os.Exit(0)
-				return nil
-			}
-			if val, ok := index.(IntValue); ok {
-				if val.GetInt() < 0 {
-					if -val.GetInt() > len(objectValue.(ArrayValue).Values) {
-						fmt.Fprintln(os.Stderr, "Error: array index out of bounds")
-						os.Exit(0)
-						return nil
-					}
-
-					objectValue.(ArrayValue).Values[len(objectValue.(ArrayValue).Values)+val.GetInt()], _ = Evaluate(node.Value, env)
-					return objectValue
-				}
+		return nil, NewJamError(RuntimeError, "unknown operator")
+	}
+}
 
-				objectValue.(ArrayValue).Values[val.GetInt()], _ = Evaluate(node.Value, env)
-				return objectValue
-			} else {
-				fmt.Fprintln(os.Stderr, "Error: array index must be a number")
-				os.Exit(0)
+func logicalNot(operand RuntimeValue) (RuntimeValue, error) {
+	if operand != nil {
+		if isNumber(operand) {
+			if operand.(IntValue).GetInt() == 0 {
+				return BoolValue{true}, nil
 			}
+			return BoolValue{false}, nil
+		}
+		if operand.Type() == Null {
+			return BoolValue{true}, nil
 		}
-		if objectValue.Type() == Null {
-			return objectValue.(NullValue)
+		if operand.Type() != Bool {
+			return nil, NewJamError(TypeError, "not operator can only be applied to boolean values")
 		}
-		if objectValue.Type() == String {
-			fmt.Fprintln(os.Stderr, "Error: string does not support assignment")
-			os.Exit(0)
+		return BoolValue{!operand.(BoolValue).Value}, nil
+	}
+	return BoolValue{false}, nil
+}
+
+func EvaluateAssignment(node ast.AssignmentExpression, env *Environment) (RuntimeValue, error) {
+	if member, ok := node.Assignee.(*ast.MemberExpression); ok {
+		objectValue, err := Evaluate(member.Object, env)
+		if err != nil {
+			return nil, err
+		}
+		property, err := memberKey(member, env)
+		if err != nil {
+			return nil, err
+		}
+		value, err := Evaluate(node.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		result, err := assignTypedMember(memberRoot(member), objectValue, property, value, env)
+		if err != nil {
+			return nil, err
 		}
-		objectValue.(ObjectValue).Properties[node.Assignee.(*ast.MemberExpression).Property.(*ast.Identifier).Symbol], _ = Evaluate(node.Value, env)
-		return objectValue
+		return result, env.engine.checkValue(objectValue)
 	}
 
 	if node.Assignee.Kind() != ast.IdentifierType {
-		fmt.Fprintln(os.Stderr, "Error: Left side of assignment must be a variable")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Left side of assignment must be a variable")
 	}
 
 	variableName := node.Assignee.(*ast.Identifier).Symbol
 	environment, err := Evaluate(node.Value, env)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return nil, err
 	}
 	return env.AssignVariable(variableName, environment)
 }
+
+// memberKey evaluates the property of a computed member expression, and
+// returns the property name as a string otherwise.
+func memberKey(member *ast.MemberExpression, env *Environment) (RuntimeValue, error) {
+	if member.Computed {
+		return Evaluate(member.Property, env)
+	}
+	property, ok := member.Property.(*ast.Identifier)
+	if !ok {
+		return nil, NewJamError(TypeError, "object property must be an identifier")
+	}
+	return MakeStringValue(property.Symbol), nil
+}
+
+// assignMember stores value at objectValue[property], where property is an
+// array index, an object key or a map key.
+func assignMember(objectValue, property, value RuntimeValue) (RuntimeValue, error) {
+	if m, ok := objectValue.(*MapValue); ok {
+		if err := m.entries.set(property, value); err != nil {
+			return nil, err
+		}
+		return objectValue, nil
+	}
+
+	if objectValue.Type() == Array {
+		index := property
+		if index.Type() != I8 && index.Type() != I16 && index.Type() != I32 && index.Type() != I64 {
+			return nil, NewJamError(TypeError, "array index must be a number")
+		}
+		if val, ok := index.(IntValue); ok {
+			if val.GetInt() < 0 {
+				if -val.GetInt() > len(objectValue.(*ArrayValue).Values) {
+					return nil, NewJamError(IndexError, "array index out of bounds")
+				}
+
+				objectValue.(*ArrayValue).Values[len(objectValue.(*ArrayValue).Values)+val.GetInt()] = value
+				return objectValue, nil
+			}
+
+			if val.GetInt() >= len(objectValue.(*ArrayValue).Values) {
+				return nil, NewJamError(IndexError, "array index out of bounds")
+			}
+
+			objectValue.(*ArrayValue).Values[val.GetInt()] = value
+			return objectValue, nil
+		} else {
+			return nil, NewJamError(TypeError, "array index must be a number")
+		}
+	}
+	if objectValue.Type() == Null {
+		return objectValue.(NullValue), nil
+	}
+	if objectValue.Type() == String {
+		return nil, NewJamError(TypeError, "string does not support assignment")
+	}
+	if _, ok := objectValue.(ObjectValue); !ok {
+		return nil, NewJamErrorf(TypeError, "%s does not support assignment", objectValue.Type())
+	}
+	key, err := objectKey(property)
+	if err != nil {
+		return nil, err
+	}
+	objectValue.(ObjectValue).Set(key, value)
+	return objectValue, nil
+}
+
+// objectKey returns the object key property, a string or integer, stands for.
+func objectKey(property RuntimeValue) (string, error) {
+	switch property := property.(type) {
+	case StringValue:
+		return property.Value, nil
+	case IntValue:
+		return strconv.Itoa(property.GetInt()), nil
+	}
+	return "", NewJamErrorf(TypeError, "Object key must be a string, got %s", property.Type())
+}
+
+// memberRoot returns the variable a chain of member expressions, such as
+// p.pos.x, starts at, or "" if it does not start at a variable.
+func memberRoot(member *ast.MemberExpression) string {
+	var object ast.Expression = member
+	for {
+		switch expr := object.(type) {
+		case *ast.MemberExpression:
+			object = expr.Object
+		case *ast.Identifier:
+			return expr.Symbol
+		default:
+			return ""
+		}
+	}
+}
+
+// assignTypedMember is assignMember for a member of the variable root. If
+// the type of root was written as a type alias or object type, root is
+// checked against it after the assignment, which is undone if root no longer
+// fits. Types belong to variables, so assignments through other variables
+// holding the same object are not checked.
+func assignTypedMember(root string, objectValue, property, value RuntimeValue, env *Environment) (RuntimeValue, error) {
+	scope := env.Resolve(root)
+	if root == "" || scope == nil {
+		return assignMember(objectValue, property, value)
+	}
+	userType, typed := scope.userTypes[root]
+	object, isObject := objectValue.(ObjectValue)
+	if !typed || !isObject {
+		return assignMember(objectValue, property, value)
+	}
+
+	key, err := objectKey(property)
+	if err != nil {
+		return nil, err
+	}
+	old, had := object.Properties[key]
+	object.Set(key, value)
+	if _, err := conformToType(scope.variables[root], userType, scope, root); err != nil {
+		if had {
+			object.Properties[key] = old
+		} else {
+			object.Delete(key)
+		}
+		return nil, err
+	}
+	return object, nil
+}
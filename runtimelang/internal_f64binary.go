@@ -1,470 +1,420 @@
 package runtimelang
 
 import (
-	"fmt"
 	"math"
-	"os"
 )
 
-func internal_F64Plus(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Plus(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 
 	switch rhs.Type() {
 	case I8, I16, I32, I64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot add floating point values to integer values")
-		fmt.Fprintln(os.Stderr, "Consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot add floating point values to integer values, consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down")
 	case F32:
 		var result float64 = 0
 		result = lhs.Value + float64(rhs.(Float32Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
-		return Float64Value{lhs.Value + rhs.(Float64Value).Value}
+		return Float64Value{lhs.Value + rhs.(Float64Value).Value}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Minus(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Minus(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8, I16, I32, I64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot subtract floating point values from integer values")
-		fmt.Fprintln(os.Stderr, "Consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot subtract floating point values from integer values, consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down")
 	case F32:
 		var result float64 = 0
 		result = lhs.Value - float64(rhs.(Float32Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
-		return Float64Value{lhs.Value - rhs.(Float64Value).Value}
+		return Float64Value{lhs.Value - rhs.(Float64Value).Value}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Mult(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Mult(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8, I16, I32, I64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot multiply floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot multiply floating point values with integer values, consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down")
 	case F32:
-		return Float32Value{float32(lhs.Value) * rhs.(Float32Value).Value}
+		return Float32Value{float32(lhs.Value) * rhs.(Float32Value).Value}, nil
 	case F64:
-		return Float64Value{lhs.Value * rhs.(Float64Value).Value}
+		return Float64Value{lhs.Value * rhs.(Float64Value).Value}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Pow(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Pow(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8, I16, I32, I64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot raise floating point values to integer powers")
-		fmt.Fprintln(os.Stderr, "Consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot raise floating point values to integer powers, consider using float32(), float64() to cast up or int8(), int16(), int32(), int64() to down")
 	case F32:
 		var result float64 = 0
 		result = math.Pow(float64(lhs.Value), float64(rhs.(Float32Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
-		return Float64Value{math.Pow(lhs.Value, rhs.(Float64Value).Value)}
+		return Float64Value{math.Pow(lhs.Value, rhs.(Float64Value).Value)}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Div(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Div(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = float64(lhs.Value) / float64(rhs.(Int8Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I16:
 		if rhs.(Int16Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = float64(lhs.Value) / float64(rhs.(Int16Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = float64(lhs.Value) / float64(rhs.(Int32Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = float64(lhs.Value) / float64(rhs.(Int64Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F32:
 		if rhs.(Float32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = float64(lhs.Value) / float64(rhs.(Float32Value).Value)
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
 		if rhs.(Float64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
-		return Float64Value{lhs.Value / rhs.(Float64Value).Value}
+		return Float64Value{lhs.Value / rhs.(Float64Value).Value}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64IntDiv(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64IntDiv(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Floor(float64(lhs.Value) / float64(rhs.(Int8Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I16:
 		if rhs.(Int16Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Floor(float64(lhs.Value) / float64(rhs.(Int16Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Floor(float64(lhs.Value) / float64(rhs.(Int32Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Floor(float64(lhs.Value) / float64(rhs.(Int64Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F32:
 		if rhs.(Float32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Floor(float64(lhs.Value) / float64(rhs.(Float32Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
 		if rhs.(Float64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
-		return Float64Value{math.Floor(lhs.Value / rhs.(Float64Value).Value)}
+		return Float64Value{math.Floor(lhs.Value / rhs.(Float64Value).Value)}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Mod(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Mod(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Int8Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I16:
 		if rhs.(Int16Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Int16Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Int32Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Int64Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F32:
 		if rhs.(Float32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Float32Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	case F64:
 		if rhs.(Float64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
-		return Float64Value{math.Mod(lhs.Value, rhs.(Float64Value).Value)}
+		return Float64Value{math.Mod(lhs.Value, rhs.(Float64Value).Value)}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64GreaterThan(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64GreaterThan(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isGreaterThan := lhs.Value > float64(rhs.(Int8Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isGreaterThan := lhs.Value > float64(rhs.(Int16Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isGreaterThan := lhs.Value > float64(rhs.(Int32Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isGreaterThan := lhs.Value > float64(rhs.(Int64Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isGreaterThan := lhs.Value > float64(rhs.(Float32Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isGreaterThan := lhs.Value > rhs.(Float64Value).Value
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64GreaterThanEqual(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64GreaterThanEqual(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isGreaterThanEqual := lhs.Value >= float64(rhs.(Int8Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isGreaterThanEqual := lhs.Value >= float64(rhs.(Int16Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isGreaterThanEqual := lhs.Value >= float64(rhs.(Int32Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isGreaterThanEqual := lhs.Value >= float64(rhs.(Int64Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isGreaterThanEqual := lhs.Value >= float64(rhs.(Float32Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isGreaterThanEqual := lhs.Value >= rhs.(Float64Value).Value
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64LessThan(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64LessThan(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isLessThan := lhs.Value < float64(rhs.(Int8Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isLessThan := lhs.Value < float64(rhs.(Int16Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isLessThan := lhs.Value < float64(rhs.(Int32Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isLessThan := lhs.Value < float64(rhs.(Int64Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isLessThan := lhs.Value < float64(rhs.(Float32Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isLessThan := lhs.Value < rhs.(Float64Value).Value
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64LessThanEqual(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64LessThanEqual(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isLessThanEqual := lhs.Value <= float64(rhs.(Int8Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isLessThanEqual := lhs.Value <= float64(rhs.(Int16Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isLessThanEqual := lhs.Value <= float64(rhs.(Int32Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isLessThanEqual := lhs.Value <= float64(rhs.(Int64Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isLessThanEqual := lhs.Value <= float64(rhs.(Float32Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isLessThanEqual := lhs.Value <= rhs.(Float64Value).Value
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64Equal(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64Equal(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isEqual := lhs.Value == float64(rhs.(Int8Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isEqual := lhs.Value == float64(rhs.(Int16Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isEqual := lhs.Value == float64(rhs.(Int32Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isEqual := lhs.Value == float64(rhs.(Int64Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isEqual := lhs.Value == float64(rhs.(Float32Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isEqual := lhs.Value == rhs.(Float64Value).Value
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case Bool:
 		isEqual := false
 		if rhs.(BoolValue).Value {
@@ -473,63 +423,59 @@ func internal_F64Equal(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
 			isEqual = lhs.Value == float64(0)
 		}
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case Null:
 		isEqual := lhs.Value == float64(0)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_F64NotEqual(lhs Float64Value, rhs RuntimeValue) RuntimeValue {
+func internal_F64NotEqual(lhs Float64Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isNotEqual := lhs.Value != float64(rhs.(Int8Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isNotEqual := lhs.Value != float64(rhs.(Int16Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isNotEqual := lhs.Value != float64(rhs.(Int32Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isNotEqual := lhs.Value != float64(rhs.(Int64Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isNotEqual := lhs.Value != float64(rhs.(Float32Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isNotEqual := lhs.Value != rhs.(Float64Value).Value
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
@@ -1,578 +1,517 @@
 package runtimelang
 
 import (
-	"fmt"
 	"math"
-	"os"
 )
 
-func internal_I32Plus(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Plus(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32
 		result = lhs.Value + int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value + int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value + rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) + rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot add floating point values to integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot add floating point values to integer values, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Minus(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Minus(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = lhs.Value - int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value - int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value - rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) - rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot subtract floating point values from integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot subtract floating point values from integer values, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Mult(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Mult(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = lhs.Value * int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value * int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value * rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) * rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot multiply floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot multiply floating point values with integer values, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Pow(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Pow(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = int32(math.Pow(float64(lhs.Value), float64(rhs.(Int8Value).Value)))
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = int32(math.Pow(float64(lhs.Value), float64(rhs.(Int16Value).Value)))
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = int32(math.Pow(float64(lhs.Value), float64(rhs.(Int32Value).Value)))
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(math.Pow(float64(lhs.Value), float64(rhs.(Int64Value).Value)))
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot raise integer values to floating point powers")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot raise integer values to floating point powers, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Div(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Div(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int64 = 0
 		result = int64(lhs.Value) / rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot divide floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot divide floating point values with integer values, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32IntDiv(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32IntDiv(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		if rhs.(Int16Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value / rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int64 = 0
 		result = int64(lhs.Value) / rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot divide floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32(), float32(), float64() to cast up or down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot divide floating point values with integer values, consider using int32(), float32(), float64() to cast up or down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Mod(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Mod(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		if rhs.(Int8Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value % int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		if rhs.(Int16Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value % int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		if rhs.(Int32Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int32 = 0
 		result = lhs.Value % rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		if rhs.(Int64Value).Value == 0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result int64 = 0
 		result = int64(lhs.Value) % rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32:
 		if rhs.(Float32Value).Value == 0.0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float32 = 0.0
 		result = float32(math.Mod(float64(lhs.Value), float64(rhs.(Float32Value).Value)))
-		return Float32Value{result}
+		return Float32Value{result}, nil
 	case F64:
 		if rhs.(Float64Value).Value == 0.0 {
-			fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-			os.Exit(0)
+			return nil, NewJamError(ArithmeticError, "Division by zero")
 		}
 		var result float64 = 0.0
 		result = math.Mod(float64(lhs.Value), float64(rhs.(Float64Value).Value))
-		return Float64Value{result}
+		return Float64Value{result}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32BitwiseAnd(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32BitwiseAnd(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = lhs.Value & int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value & int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value & rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) & rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot bitwise and floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32() to cast down.")
+		return nil, NewJamError(TypeError, "Cannot bitwise and floating point values with integer values. Consider using int32() to cast down.")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32BitwiseOr(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32BitwiseOr(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = lhs.Value | int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value | int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value | rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) | rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot bitwise or floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32() to cast down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot bitwise or floating point values with integer values, consider using int32() to cast down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32BitwiseXor(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32BitwiseXor(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		var result int32 = 0
 		result = lhs.Value ^ int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		var result int32 = 0
 		result = lhs.Value ^ int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		var result int32 = 0
 		result = lhs.Value ^ rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		var result int64 = 0
 		result = int64(lhs.Value) ^ rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintln(os.Stderr, "Error: Cannot bitwise xor floating point values with integer values")
-		fmt.Fprintln(os.Stderr, "Consider using int32() to cast down.")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot bitwise xor floating point values with integer values, consider using int32() to cast down")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32GreaterThan(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32GreaterThan(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isGreaterThan := lhs.Value > int32(rhs.(Int8Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isGreaterThan := lhs.Value > int32(rhs.(Int16Value).Value)
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isGreaterThan := lhs.Value > rhs.(Int32Value).Value
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isGreaterThan := int64(lhs.Value) > rhs.(Int64Value).Value
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isGreaterThan := float32(lhs.Value) > rhs.(Float32Value).Value
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isGreaterThan := float64(lhs.Value) > rhs.(Float64Value).Value
 		if isGreaterThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32GreaterThanEqual(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32GreaterThanEqual(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isGreaterThanEqual := lhs.Value >= int32(rhs.(Int8Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isGreaterThanEqual := lhs.Value >= int32(rhs.(Int16Value).Value)
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isGreaterThanEqual := lhs.Value >= rhs.(Int32Value).Value
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isGreaterThanEqual := int64(lhs.Value) >= rhs.(Int64Value).Value
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isGreaterThanEqual := float32(lhs.Value) >= rhs.(Float32Value).Value
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isGreaterThanEqual := float64(lhs.Value) >= rhs.(Float64Value).Value
 		if isGreaterThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32LessThan(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32LessThan(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isLessThan := lhs.Value < int32(rhs.(Int8Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isLessThan := lhs.Value < int32(rhs.(Int16Value).Value)
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isLessThan := int32(lhs.Value) < rhs.(Int32Value).Value
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isLessThan := int64(lhs.Value) < rhs.(Int64Value).Value
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isLessThan := float32(lhs.Value) < rhs.(Float32Value).Value
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isLessThan := float64(lhs.Value) < rhs.(Float64Value).Value
 		if isLessThan {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32LessThanEqual(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32LessThanEqual(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isLessThanEqual := lhs.Value <= int32(rhs.(Int8Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isLessThanEqual := lhs.Value <= int32(rhs.(Int16Value).Value)
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isLessThanEqual := lhs.Value <= rhs.(Int32Value).Value
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isLessThanEqual := int64(lhs.Value) <= rhs.(Int64Value).Value
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isLessThanEqual := float32(lhs.Value) <= rhs.(Float32Value).Value
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isLessThanEqual := float64(lhs.Value) <= rhs.(Float64Value).Value
 		if isLessThanEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32Equal(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32Equal(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isEqual := lhs.Value == int32(rhs.(Int8Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isEqual := lhs.Value == int32(rhs.(Int16Value).Value)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isEqual := lhs.Value == rhs.(Int32Value).Value
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isEqual := int64(lhs.Value) == rhs.(Int64Value).Value
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isEqual := float32(lhs.Value) == rhs.(Float32Value).Value
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isEqual := float64(lhs.Value) == rhs.(Float64Value).Value
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case Bool:
 		isEqual := false
 		if rhs.(BoolValue).Value {
@@ -581,111 +520,101 @@ func internal_I32Equal(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
 			isEqual = lhs.Value == int32(0)
 		}
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case Null:
 		isEqual := lhs.Value == int32(0)
 		if isEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32NotEqual(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32NotEqual(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		isNotEqual := lhs.Value != int32(rhs.(Int8Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I16:
 		isNotEqual := lhs.Value != int32(rhs.(Int16Value).Value)
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I32:
 		isNotEqual := lhs.Value != rhs.(Int32Value).Value
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case I64:
 		isNotEqual := int64(lhs.Value) != rhs.(Int64Value).Value
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F32:
 		isNotEqual := float32(lhs.Value) != rhs.(Float32Value).Value
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	case F64:
 		isNotEqual := float64(lhs.Value) != rhs.(Float64Value).Value
 		if isNotEqual {
-			return BoolValue{true}
+			return BoolValue{true}, nil
 		}
-		return BoolValue{false}
+		return BoolValue{false}, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32LeftShift(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32LeftShift(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		result := lhs.Value << int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		result := lhs.Value << int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		result := int32(lhs.Value) << rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		result := int64(lhs.Value) << rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintf(os.Stderr, "Error: Cannot left shift by a floating point value\n")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot left shift by a floating point value")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
 
-func internal_I32RightShift(lhs Int32Value, rhs RuntimeValue) RuntimeValue {
+func internal_I32RightShift(lhs Int32Value, rhs RuntimeValue) (RuntimeValue, error) {
 	switch rhs.Type() {
 	case I8:
 		result := lhs.Value >> int32(rhs.(Int8Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I16:
 		result := lhs.Value >> int32(rhs.(Int16Value).Value)
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I32:
 		result := int32(lhs.Value) >> rhs.(Int32Value).Value
-		return Int32Value{result}
+		return Int32Value{result}, nil
 	case I64:
 		result := int64(lhs.Value) >> rhs.(Int64Value).Value
-		return Int64Value{result}
+		return Int64Value{result}, nil
 	case F32, F64:
-		fmt.Fprintf(os.Stderr, "Error: Cannot right shift by a floating point value\n")
-		os.Exit(0)
+		return nil, NewJamError(TypeError, "Cannot right shift by a floating point value")
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown type for rhs of binary expression: %s\n", rhs.Type())
-		os.Exit(0)
+		return nil, NewJamErrorf(TypeError, "Unknown type for rhs of binary expression: %s", rhs.Type())
 	}
-	return nil
 }
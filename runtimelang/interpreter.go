@@ -2,11 +2,9 @@ package runtimelang
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/Jamlie/Jamlang/ast"
-	"github.com/Jamlie/Jamlang/internal"
 )
 
 func hasDecimalZero(num float64) bool {
@@ -15,7 +13,27 @@ func hasDecimalZero(num float64) bool {
 	return strings.HasSuffix(strNum, ".0")
 }
 
-func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
+// Evaluate evaluates astNode in env. Errors raised while evaluating it,
+// including Go panics, are given its position unless they have one already.
+func Evaluate(astNode ast.Statement, env *Environment) (result RuntimeValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, withPosition(panicError(r), astNode.Pos())
+		}
+	}()
+
+	if err := env.engine.step(); err != nil {
+		return nil, withPosition(err, astNode.Pos())
+	}
+
+	result, err = evaluate(astNode, env)
+	if err != nil {
+		return result, withPosition(err, astNode.Pos())
+	}
+	return result, nil
+}
+
+func evaluate(astNode ast.Statement, env *Environment) (RuntimeValue, error) {
 	switch astNode.Kind() {
 	case ast.CommentType:
 		return MakeNullValue(), nil
@@ -40,142 +58,127 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 	case ast.BinaryExpressionType:
 		binaryExpression, ok := astNode.(*ast.BinaryExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected BinaryExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected BinaryExpression, got %T", astNode)
 		}
-		return EvaluateBinaryExpression(*binaryExpression, env), nil
+		return EvaluateBinaryExpression(*binaryExpression, env)
 	case ast.UnaryExpressionType:
 		unaryExpression, ok := astNode.(*ast.UnaryExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected UnaryExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected UnaryExpression, got %T", astNode)
 		}
-		return EvaluateUnaryExpression(*unaryExpression, env), nil
+		return EvaluateUnaryExpression(*unaryExpression, env)
 	case ast.LogicalExpressionType:
 		logicalExpression, ok := astNode.(*ast.LogicalExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected LogicalExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected LogicalExpression, got %T", astNode)
 		}
-		return EvaluateLogicalExpression(*logicalExpression, env), nil
+		return EvaluateLogicalExpression(*logicalExpression, env)
 	case ast.IdentifierType:
 		identifier, ok := astNode.(*ast.Identifier)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected Identifier, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected Identifier, got %T", astNode)
 		}
-		return EvaluateIdentifier(identifier, &env), nil
+		return EvaluateIdentifier(identifier, env)
 	case ast.ObjectLiteralType:
 		objectLiteral, ok := astNode.(*ast.ObjectLiteral)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ObjectLiteral, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ObjectLiteral, got %T", astNode)
 		}
-		return EvaluateObjectExpression(*objectLiteral, env), nil
+		return EvaluateObjectExpression(*objectLiteral, env)
 	case ast.ArrayLiteralType:
 		arrayLiteral, ok := astNode.(*ast.ArrayLiteral)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ArrayLiteral, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ArrayLiteral, got %T", astNode)
+		}
+		return EvaluateArrayExpression(*arrayLiteral, env)
+	case ast.TemplateLiteralType:
+		templateLiteral, ok := astNode.(*ast.TemplateLiteral)
+		if !ok {
+			return nil, NewJamErrorf(RuntimeError, "Expected TemplateLiteral, got %T", astNode)
 		}
-		return EvaluateArrayExpression(*arrayLiteral, env), nil
+		return EvaluateTemplateLiteral(*templateLiteral, env)
 	case ast.TupleLiteralType:
 		tupleLiteral, ok := astNode.(*ast.TupleLiteral)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected TupleLiteral, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected TupleLiteral, got %T", astNode)
 		}
-		return EvaluateTupleExpression(*tupleLiteral, env), nil
+		return EvaluateTupleExpression(*tupleLiteral, env)
 	case ast.MemberExpressionType:
 		memberExpression, ok := astNode.(*ast.MemberExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected MemberExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected MemberExpression, got %T", astNode)
 		}
-		return EvaluateMemberExpression(*memberExpression, env), nil
+		return EvaluateMemberExpression(*memberExpression, env)
 	case ast.CallExpressionType:
 		callExpression, ok := astNode.(*ast.CallExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected CallExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected CallExpression, got %T", astNode)
 		}
-		return EvaluateCallExpression(*callExpression, env), nil
+		return EvaluateCallExpression(*callExpression, env)
 	case ast.ReturnStatementType:
 		returnStatement, ok := astNode.(*ast.ReturnStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ReturnStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ReturnStatement, got %T", astNode)
 		}
-		return EvaluateReturnStatement(*returnStatement, env), nil
+		return EvaluateReturnStatement(*returnStatement, env)
 	case ast.BreakStatementType:
 		breakStatement, ok := astNode.(*ast.BreakStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected BreakStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected BreakStatement, got %T", astNode)
 		}
-		return EvaluateBreakStatement(*breakStatement, env), nil
+		return EvaluateBreakStatement(*breakStatement, env)
 	case ast.ContinueStatementType:
 		continueStatement, ok := astNode.(*ast.ContinueStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ContinueStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ContinueStatement, got %T", astNode)
 		}
-		return EvaluateContinueStatement(*continueStatement, env), nil
+		return EvaluateContinueStatement(*continueStatement, env)
 	case ast.AssignmentExpressionType:
 		assignmentExpression, ok := astNode.(*ast.AssignmentExpression)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected AssignmentExpression, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected AssignmentExpression, got %T", astNode)
 		}
-		return EvaluateAssignment(*assignmentExpression, env), nil
+		return EvaluateAssignment(*assignmentExpression, env)
 	case ast.ProgramType:
 		program, ok := astNode.(*ast.Program)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected Program, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected Program, got %T", astNode)
 		}
-		return EvaluateProgram(*program, env), nil
+		return EvaluateProgram(*program, env)
 	case ast.VariableDeclarationType:
 		variableDeclaration, ok := astNode.(*ast.VariableDeclaration)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected VariableDeclaration, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected VariableDeclaration, got %T", astNode)
 		}
-		return EvaluateVariableDeclaration(*variableDeclaration, &env, variableDeclaration.Type), nil
+		return EvaluateVariableDeclaration(*variableDeclaration, env, variableDeclaration.Type)
 	case ast.FunctionDeclarationType:
 		functionDeclaration, ok := astNode.(*ast.FunctionDeclaration)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected FunctionDeclaration, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected FunctionDeclaration, got %T", astNode)
 		}
 
-		fn, _ := EvaluateFunctionDeclaration(*functionDeclaration, &env, functionDeclaration.ReturnType)
-		return fn, nil
+		return EvaluateFunctionDeclaration(*functionDeclaration, env, functionDeclaration.ReturnType)
+	case ast.ClassDeclarationType:
+		classDeclaration, ok := astNode.(*ast.ClassDeclaration)
+		if !ok {
+			return nil, NewJamErrorf(RuntimeError, "Expected ClassDeclaration, got %T", astNode)
+		}
+
+		return EvaluateClassDeclaration(*classDeclaration, env)
+	case ast.TypeDeclarationType:
+		typeDeclaration, ok := astNode.(*ast.TypeDeclaration)
+		if !ok {
+			return nil, NewJamErrorf(RuntimeError, "Expected TypeDeclaration, got %T", astNode)
+		}
+
+		return EvaluateTypeDeclaration(*typeDeclaration, env)
 	case ast.ConditionalStatementType:
 		conditionalStatement, ok := astNode.(*ast.ConditionalStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ConditionalStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ConditionalStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateConditionalStatement(*conditionalStatement, &env)
+		result, err := EvaluateConditionalStatement(*conditionalStatement, env)
 
 		if err == IsReturnError {
 			return result, IsReturnError
@@ -190,21 +193,17 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
 	case ast.WhileStatementType:
 		whileStatement, ok := astNode.(*ast.WhileStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected WhileStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected WhileStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateWhileStatement(*whileStatement, &env)
+		result, err := EvaluateWhileStatement(*whileStatement, env)
 
 		if err == IsReturnError {
 			return result, IsReturnError
@@ -219,21 +218,17 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
 	case ast.LoopStatementType:
 		loopStatement, ok := astNode.(*ast.LoopStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected LoopStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected LoopStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateLoopStatement(*loopStatement, &env)
+		result, err := EvaluateLoopStatement(*loopStatement, env)
 
 		if err == IsReturnError {
 			return result, IsReturnError
@@ -248,21 +243,17 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
 	case ast.ForEachStatementType:
 		forEachStatement, ok := astNode.(*ast.ForEachStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ForEachStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ForEachStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateForEachStatement(*forEachStatement, &env)
+		result, err := EvaluateForEachStatement(*forEachStatement, env)
 
 		if err == IsReturnError {
 			return result, IsReturnError
@@ -273,21 +264,17 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
 	case ast.ForStatementType:
 		forStatement, ok := astNode.(*ast.ForStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ForStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ForStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateForStatement(*forStatement, &env)
+		result, err := EvaluateForStatement(*forStatement, env)
 
 		if err == IsReturnError {
 			return result, IsReturnError
@@ -298,31 +285,37 @@ func Evaluate(astNode ast.Statement, env Environment) (RuntimeValue, error) {
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
+	case ast.ThrowStatementType:
+		throwStatement, ok := astNode.(*ast.ThrowStatement)
+		if !ok {
+			return nil, NewJamErrorf(RuntimeError, "Expected ThrowStatement, got %T", astNode)
+		}
+
+		return EvaluateThrowStatement(*throwStatement, env)
+	case ast.TryStatementType:
+		tryStatement, ok := astNode.(*ast.TryStatement)
+		if !ok {
+			return nil, NewJamErrorf(RuntimeError, "Expected TryStatement, got %T", astNode)
+		}
+
+		return EvaluateTryStatement(*tryStatement, env)
 	case ast.ImportStatementType:
 		importStatement, ok := astNode.(*ast.ImportStatement)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "Error on line %d: Expected ImportStatement, got %T\n", internal.Line(), astNode)
-			os.Exit(0)
-			return nil, nil
+			return nil, NewJamErrorf(RuntimeError, "Expected ImportStatement, got %T", astNode)
 		}
 
-		result, err := EvaluateImportStatement(*importStatement, &env)
+		result, err := EvaluateImportStatement(*importStatement, env)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error on line %d: %s\n", internal.Line(), err.Error())
-			os.Exit(0)
-			return nil, nil
+			return nil, err
 		}
 
 		return result, nil
 	default:
-		fmt.Fprintf(os.Stderr, "Error on line %d: Unknown AST node type %T\n", internal.Line(), astNode)
-		os.Exit(0)
-		return nil, nil
+		return nil, NewJamErrorf(RuntimeError, "Unknown AST node type %T", astNode)
 	}
 }
@@ -0,0 +1,275 @@
+package runtimelang
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func jamlangJsonParse(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 {
+		return nil, NewJamError(ArgumentError, "JSON.parse takes 1 argument")
+	}
+
+	if args[0].Type() != String {
+		return nil, NewJamError(TypeError, "JSON.parse takes a string")
+	}
+	text := args[0].(StringValue).Value
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, err := decodeJSON(decoder)
+	if err == nil {
+		if _, extra := decoder.Token(); extra != io.EOF {
+			err = fmt.Errorf("unexpected data after the JSON value")
+		}
+	}
+	if err != nil {
+		return nil, jsonSyntaxError(text, decoder.InputOffset(), err)
+	}
+
+	return value, nil
+}
+
+// decodeJSON reads the next JSON value from decoder. Objects keep their keys
+// in the order they are written in.
+func decodeJSON(decoder *json.Decoder) (RuntimeValue, error) {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			values := []RuntimeValue{}
+			for decoder.More() {
+				value, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return MakeArrayValue(values), nil
+		}
+
+		object := newObjectValue(nil, 0)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(key.(string), value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case string:
+		return MakeStringValue(token), nil
+	case json.Number:
+		if n, err := token.Int64(); err == nil {
+			return MakeInt64Value(n), nil
+		}
+		n, err := token.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("number %s is out of range", token)
+		}
+		return MakeFloat64Value(n), nil
+	case bool:
+		return MakeBoolValue(token), nil
+	}
+	return MakeNullValue(), nil
+}
+
+// jsonSyntaxError describes why text is not valid JSON, with the line and
+// column the decoder stopped at.
+func jsonSyntaxError(text string, offset int64, err error) *JamError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = errors.New("unexpected end of JSON input")
+		offset = int64(len(text))
+	}
+
+	before := text[:min(int(offset), len(text))]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	return NewJamErrorf(SyntaxError, "JSON.parse: %s at line %d, column %d", err, line, column)
+}
+
+func jamlangJsonStringify(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, NewJamError(ArgumentError, "JSON.stringify takes 1 or 2 arguments")
+	}
+
+	indent := ""
+	if len(args) == 2 {
+		switch value := args[1].(type) {
+		case IntValue:
+			if value.GetInt() < 0 || value.GetInt() > 10 {
+				return nil, NewJamError(ArgumentError, "JSON.stringify indent must be between 0 and 10 spaces")
+			}
+			indent = strings.Repeat(" ", value.GetInt())
+		case StringValue:
+			indent = value.Value
+		default:
+			return nil, NewJamError(TypeError, "JSON.stringify takes a number of spaces or a string to indent with")
+		}
+	}
+
+	encoder := jsonEncoder{indent: indent, seen: map[any]bool{}}
+	if err := encoder.encode(args[0], "$", 0); err != nil {
+		return nil, err
+	}
+	return MakeStringValue(encoder.String()), nil
+}
+
+// jsonEncoder writes Jamlang values as JSON. Object keys are written in the
+// order they were added.
+type jsonEncoder struct {
+	bytes.Buffer
+	indent string
+	// seen holds the arrays and objects being written, to find cycles.
+	seen map[any]bool
+}
+
+// encode writes value, found at path and nested depth levels deep.
+func (e *jsonEncoder) encode(value RuntimeValue, path string, depth int) error {
+	switch value := value.(type) {
+	case NullValue:
+		e.WriteString("null")
+	case BoolValue:
+		e.WriteString(strconv.FormatBool(value.Value))
+	case IntValue:
+		e.WriteString(strconv.Itoa(value.GetInt()))
+	case Float32Value, Float64Value:
+		n, _ := numberValue(value)
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return NewJamErrorf(TypeError, "JSON.stringify cannot represent %s at %s", value.ToString(), path)
+		}
+		data, _ := json.Marshal(value.Get())
+		e.Write(data)
+	case StringValue:
+		e.writeString(value.Value)
+	case *ArrayValue:
+		return e.encodeArray(value.Values, path, depth)
+	case TupleValue:
+		return e.encodeArray(value.Values, path, depth)
+	case ObjectValue:
+		return e.encodeObject(value, path, depth)
+	case JSONValue:
+		data, err := json.Marshal(value.Value)
+		if err != nil {
+			return NewJamErrorf(TypeError, "JSON.stringify: %s at %s", err, path)
+		}
+		e.Write(data)
+	case FunctionValue, NativeFunctionValue, *ClassValue, SuperValue:
+		return NewJamErrorf(TypeError, "JSON.stringify cannot serialize the function at %s", path)
+	default:
+		return NewJamErrorf(TypeError, "JSON.stringify cannot serialize %s at %s", value.Type(), path)
+	}
+	return nil
+}
+
+func (e *jsonEncoder) encodeArray(values []RuntimeValue, path string, depth int) error {
+	if len(values) == 0 {
+		e.WriteString("[]")
+		return nil
+	}
+	if err := e.enter(&values[0], path); err != nil {
+		return err
+	}
+	defer delete(e.seen, &values[0])
+
+	e.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := e.encode(value, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	e.newline(depth)
+	e.WriteByte(']')
+	return nil
+}
+
+func (e *jsonEncoder) encodeObject(object ObjectValue, path string, depth int) error {
+	if len(object.Properties) == 0 {
+		e.WriteString("{}")
+		return nil
+	}
+	identity := reflect.ValueOf(object.Properties).UnsafePointer()
+	if err := e.enter(identity, path); err != nil {
+		return err
+	}
+	defer delete(e.seen, identity)
+
+	e.WriteByte('{')
+	for i, key := range object.Keys() {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		e.writeString(key)
+		e.WriteByte(':')
+		if e.indent != "" {
+			e.WriteByte(' ')
+		}
+		if err := e.encode(object.Properties[key], path+"."+key, depth+1); err != nil {
+			return err
+		}
+	}
+	e.newline(depth)
+	e.WriteByte('}')
+	return nil
+}
+
+// enter records that the collection identified by identity is being
+// written, failing if it already is, which means it contains itself.
+func (e *jsonEncoder) enter(identity any, path string) error {
+	if e.seen[identity] {
+		return NewJamErrorf(TypeError, "JSON.stringify cannot serialize a cycle: %s refers back to an array or object that contains it", path)
+	}
+	e.seen[identity] = true
+	return nil
+}
+
+func (e *jsonEncoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		e.WriteString(e.indent)
+	}
+}
+
+func (e *jsonEncoder) writeString(s string) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	e.Write(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")))
+}
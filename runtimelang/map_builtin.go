@@ -0,0 +1,342 @@
+package runtimelang
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// hashTable holds the entries of a Map or Set in the order their keys were
+// added, indexed by the hash keys of their keys.
+type hashTable struct {
+	// name is Map or Set, for error messages.
+	name   string
+	keys   []RuntimeValue
+	values []RuntimeValue
+	hashes []string
+	index  map[string]int
+}
+
+func newHashTable(name string) hashTable {
+	return hashTable{name: name, index: make(map[string]int)}
+}
+
+func (t *hashTable) len() int {
+	return len(t.keys)
+}
+
+// hash returns the hash key of key, failing if key cannot be a key.
+func (t *hashTable) hash(key RuntimeValue) (string, error) {
+	hash, ok := hashKey(key)
+	if !ok && hasNaN(key) {
+		return "", NewJamErrorf(TypeError, "%s keys cannot be NaN, which is not equal to itself", t.name)
+	}
+	if !ok {
+		return "", NewJamErrorf(TypeError, "%s keys must be numbers, strings, bools or tuples of them, got %s", t.name, key.Type())
+	}
+	return hash, nil
+}
+
+// hasNaN reports whether key is NaN or a tuple holding NaN.
+func hasNaN(key RuntimeValue) bool {
+	switch key := key.(type) {
+	case FloatValue:
+		return math.IsNaN(key.GetFloat())
+	case TupleValue:
+		return slices.ContainsFunc(key.Values, hasNaN)
+	}
+	return false
+}
+
+// find returns the position of key in t, or -1 if t does not have it.
+func (t *hashTable) find(key RuntimeValue) (int, error) {
+	hash, err := t.hash(key)
+	if err != nil {
+		return -1, err
+	}
+	if i, ok := t.index[hash]; ok {
+		return i, nil
+	}
+	return -1, nil
+}
+
+func (t *hashTable) get(key RuntimeValue) (RuntimeValue, bool) {
+	i, err := t.find(key)
+	if err != nil || i < 0 {
+		return nil, false
+	}
+	return t.values[i], true
+}
+
+// set sets the value of key, adding key after the others if t does not have
+// it yet.
+func (t *hashTable) set(key, value RuntimeValue) error {
+	hash, err := t.hash(key)
+	if err != nil {
+		return err
+	}
+	if i, ok := t.index[hash]; ok {
+		t.values[i] = value
+		return nil
+	}
+
+	t.index[hash] = len(t.keys)
+	t.keys = append(t.keys, key)
+	t.values = append(t.values, value)
+	t.hashes = append(t.hashes, hash)
+	return nil
+}
+
+// delete removes key from t, reporting whether t had it.
+func (t *hashTable) delete(key RuntimeValue) (bool, error) {
+	i, err := t.find(key)
+	if err != nil || i < 0 {
+		return false, err
+	}
+
+	delete(t.index, t.hashes[i])
+	t.keys = slices.Delete(t.keys, i, i+1)
+	t.values = slices.Delete(t.values, i, i+1)
+	t.hashes = slices.Delete(t.hashes, i, i+1)
+	for j := i; j < len(t.hashes); j++ {
+		t.index[t.hashes[j]] = j
+	}
+	return true, nil
+}
+
+func (t *hashTable) clear() {
+	t.keys, t.values, t.hashes = nil, nil, nil
+	t.index = make(map[string]int)
+}
+
+// hashKey returns the string that identifies key in a hashTable, the same
+// for keys that are Equals: numbers of any size and kind with the same value
+// share one, as in equalNumbers. ok is false for values that cannot be keys,
+// including NaN, which is not equal to itself.
+func hashKey(key RuntimeValue) (hash string, ok bool) {
+	switch key := key.(type) {
+	case IntValue:
+		return "i" + strconv.Itoa(key.GetInt()), true
+	case FloatValue:
+		return floatHashKey(key.GetFloat())
+	case StringValue:
+		return strconv.Quote(key.Value), true
+	case BoolValue:
+		return strconv.FormatBool(key.Value), true
+	case TupleValue:
+		hashes := make([]string, len(key.Values))
+		for i, value := range key.Values {
+			if hashes[i], ok = hashKey(value); !ok {
+				return "", false
+			}
+		}
+		return "(" + strings.Join(hashes, ",") + ")", true
+	}
+	return "", false
+}
+
+// floatHashKey returns the hash key of a float of either size. Like
+// equalNumbers, it takes f32 and f64 values to be the same key when they hold
+// the same number, and a float holding a whole number to be the same key as
+// that integer.
+func floatHashKey(n float64) (string, bool) {
+	if math.IsNaN(n) {
+		return "", false
+	}
+	if n == math.Trunc(n) && math.Abs(n) < math.MaxInt64 {
+		// This also makes -0 the same key as 0.
+		return "i" + strconv.FormatInt(int64(n), 10), true
+	}
+	return "f" + strconv.FormatFloat(n, 'g', -1, 64), true
+}
+
+// jamlangMap makes a Map, empty or with the entries of an array or tuple of
+// (key, value) pairs or of another Map.
+func jamlangMap(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) > 1 {
+		return nil, NewJamError(ArgumentError, "Map takes 0 or 1 arguments")
+	}
+
+	m := MakeMapValue()
+	if len(args) == 0 {
+		return m, nil
+	}
+	if other, ok := args[0].(*MapValue); ok {
+		for i, key := range other.entries.keys {
+			m.entries.set(key, other.entries.values[i])
+		}
+		return m, nil
+	}
+
+	entries, ok := sequenceValues(args[0])
+	if !ok {
+		return nil, NewJamErrorf(TypeError, "Map takes an array of (key, value) pairs, got %s", args[0].Type())
+	}
+	for _, entry := range entries {
+		pair, ok := sequenceValues(entry)
+		if !ok || len(pair) != 2 {
+			return nil, NewJamErrorf(TypeError, "Map entries must be (key, value) pairs, got %s", entry.ToString())
+		}
+		if err := m.entries.set(pair[0], pair[1]); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// jamlangSet makes a Set, empty or with the values of an array, tuple or
+// another Set.
+func jamlangSet(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) > 1 {
+		return nil, NewJamError(ArgumentError, "Set takes 0 or 1 arguments")
+	}
+
+	set := MakeSetValue()
+	if len(args) == 0 {
+		return set, nil
+	}
+	values, ok := sequenceValues(args[0])
+	if other, isSet := args[0].(*SetValue); isSet {
+		values, ok = other.entries.keys, true
+	}
+	if !ok {
+		return nil, NewJamErrorf(TypeError, "Set takes an array of values, got %s", args[0].Type())
+	}
+	for _, value := range values {
+		if err := set.entries.set(value, value); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// jamlangMapGet returns the value of a key, or the default, null unless
+// given, when the map does not have it.
+func jamlangMapGet(m *MapValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, NewJamError(ArgumentError, "get takes 1 or 2 arguments")
+		}
+
+		i, err := m.entries.find(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if i >= 0 {
+			return m.entries.values[i], nil
+		}
+		if len(args) == 2 {
+			return args[1], nil
+		}
+		return MakeNullValue(), nil
+	}, "get")
+}
+
+// jamlangMapSet sets the value of a key in place and returns the map, so that
+// calls can be chained.
+func jamlangMapSet(m *MapValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 2 {
+			return nil, NewJamError(ArgumentError, "set takes 2 arguments")
+		}
+
+		if err := m.entries.set(args[0], args[1]); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}, "set")
+}
+
+// jamlangSetAdd adds a value to set in place and returns set.
+func jamlangSetAdd(set *SetValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "add takes 1 argument")
+		}
+
+		if err := set.entries.set(args[0], args[0]); err != nil {
+			return nil, err
+		}
+		return set, nil
+	}, "add")
+}
+
+func jamlangHashTableHas(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "has takes 1 argument")
+		}
+
+		i, err := table.find(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return MakeBoolValue(i >= 0), nil
+	}, "has")
+}
+
+// jamlangHashTableDelete removes a key and reports whether it was there.
+func jamlangHashTableDelete(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "delete takes 1 argument")
+		}
+
+		deleted, err := table.delete(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return MakeBoolValue(deleted), nil
+	}, "delete")
+}
+
+func jamlangHashTableClear(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "clear takes 0 arguments")
+		}
+
+		table.clear()
+		return MakeNullValue(), nil
+	}, "clear")
+}
+
+// jamlangHashTableKeys returns a new array of the keys of table.
+func jamlangHashTableKeys(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "keys takes 0 arguments")
+		}
+
+		return MakeArrayValue(slices.Clone(table.keys)), nil
+	}, "keys")
+}
+
+// jamlangHashTableValues returns a new array of the values of table. The
+// values of a Set are its keys.
+func jamlangHashTableValues(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "values takes 0 arguments")
+		}
+
+		return MakeArrayValue(slices.Clone(table.values)), nil
+	}, "values")
+}
+
+// jamlangHashTableEntries returns a new array of the (key, value) tuples of
+// table.
+func jamlangHashTableEntries(table *hashTable) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "entries takes 0 arguments")
+		}
+
+		entries := make([]RuntimeValue, table.len())
+		for i, key := range table.keys {
+			entries[i] = MakeTupleValue([]RuntimeValue{key, table.values[i]})
+		}
+		return MakeArrayValue(entries), nil
+	}, "entries")
+}
@@ -1,12 +1,6 @@
 package runtimelang
 
-import (
-	"fmt"
-	"os"
-)
-
-func EvaluateI8BinaryExpression(lhs Int8Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result int8 = 0
+func EvaluateI8BinaryExpression(lhs Int8Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_I8Plus(lhs, rhs)
@@ -45,15 +39,11 @@ func EvaluateI8BinaryExpression(lhs Int8Value, rhs RuntimeValue, op string) Runt
 	case ">>":
 		return internal_I8RightShift(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Int8Value{result}
 }
 
-func EvaluateI16BinaryExpression(lhs Int16Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result int16 = 0
+func EvaluateI16BinaryExpression(lhs Int16Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_I16Plus(lhs, rhs)
@@ -92,15 +82,11 @@ func EvaluateI16BinaryExpression(lhs Int16Value, rhs RuntimeValue, op string) Ru
 	case ">>":
 		return internal_I16RightShift(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Int16Value{result}
 }
 
-func EvaluateI32BinaryExpression(lhs Int32Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result int32 = 0
+func EvaluateI32BinaryExpression(lhs Int32Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_I32Plus(lhs, rhs)
@@ -139,15 +125,11 @@ func EvaluateI32BinaryExpression(lhs Int32Value, rhs RuntimeValue, op string) Ru
 	case ">>":
 		return internal_I32RightShift(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Int32Value{result}
 }
 
-func EvaluateI64BinaryExpression(lhs Int64Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result int64 = 0
+func EvaluateI64BinaryExpression(lhs Int64Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_I64Plus(lhs, rhs)
@@ -186,15 +168,11 @@ func EvaluateI64BinaryExpression(lhs Int64Value, rhs RuntimeValue, op string) Ru
 	case ">>":
 		return internal_I64RightShift(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Int64Value{result}
 }
 
-func EvaluateF32BinaryExpression(lhs Float32Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result float32 = 0
+func EvaluateF32BinaryExpression(lhs Float32Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_F32Plus(lhs, rhs)
@@ -223,15 +201,11 @@ func EvaluateF32BinaryExpression(lhs Float32Value, rhs RuntimeValue, op string)
 	case "!=":
 		return internal_F32NotEqual(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Float32Value{result}
 }
 
-func EvaluateF64BinaryExpression(lhs Float64Value, rhs RuntimeValue, op string) RuntimeValue {
-	var result float64 = 0
+func EvaluateF64BinaryExpression(lhs Float64Value, rhs RuntimeValue, op string) (RuntimeValue, error) {
 	switch op {
 	case "+":
 		return internal_F64Plus(lhs, rhs)
@@ -260,9 +234,6 @@ func EvaluateF64BinaryExpression(lhs Float64Value, rhs RuntimeValue, op string)
 	case "!=":
 		return internal_F64NotEqual(lhs, rhs)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unknown operator: %s\n", op)
-		os.Exit(0)
+		return nil, NewJamErrorf(RuntimeError, "Unknown operator: %s", op)
 	}
-
-	return Float64Value{result}
 }
@@ -0,0 +1,134 @@
+package runtimelang
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Capability names something a builtin does outside of the script, which the
+// host of an Engine may grant or deny.
+type Capability string
+
+const (
+	// FSRead allows opening files for reading.
+	FSRead Capability = "fs.read"
+	// FSWrite allows creating files and opening them for writing.
+	FSWrite Capability = "fs.write"
+	// NetClient allows HTTP requests.
+	NetClient Capability = "net.client"
+	// NetServer allows listening for HTTP connections.
+	NetServer Capability = "net.server"
+	// ProcessExit allows exit to end the run with an exit code, which the
+	// CLI exits the process with.
+	ProcessExit Capability = "process.exit"
+	// Eval allows running code given as a string with eval.
+	Eval Capability = "eval"
+	// Import allows import statements.
+	Import Capability = "import"
+)
+
+// Capabilities lists every capability.
+var Capabilities = []Capability{FSRead, FSWrite, NetClient, NetServer, ProcessExit, Eval, Import}
+
+// Permissions are the capabilities an Engine grants the scripts it runs.
+// Calling a builtin that needs a capability that is not granted raises a
+// PermissionError.
+type Permissions struct {
+	Granted []Capability
+	// Paths limits fs.read, fs.write and import to files inside these
+	// directories. When it is empty, they allow any file.
+	Paths []string
+}
+
+// AllPermissions grants every capability, for any file.
+func AllPermissions() Permissions {
+	return Permissions{Granted: slices.Clone(Capabilities)}
+}
+
+// DefaultPermissions grants eval and import, for any file, and nothing that
+// reaches files, the network or the process. It is what new engines start
+// with.
+func DefaultPermissions() Permissions {
+	return Permissions{Granted: []Capability{Eval, Import}}
+}
+
+// Has reports whether p grants capability.
+func (p Permissions) Has(capability Capability) bool {
+	return slices.Contains(p.Granted, capability)
+}
+
+// allowsPath reports whether path is inside one of p.Paths.
+func (p Permissions) allowsPath(path string) bool {
+	if len(p.Paths) == 0 {
+		return true
+	}
+
+	file, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range p.Paths {
+		dir, err := resolvePath(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath makes path absolute and resolves the symbolic links of the
+// part of it that exists, so that links cannot lead out of a directory.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// require fails with a PermissionError unless the engine grants capability.
+// name is the builtin that needs it. Code running outside of an engine is
+// granted nothing.
+func (e *Engine) require(capability Capability, name string) error {
+	if e == nil {
+		return NewJamErrorf(PermissionError, "%s needs the %s permission, which is not granted outside of an Engine", name, capability)
+	}
+	if e.Permissions.Has(capability) {
+		return nil
+	}
+	return NewJamErrorf(PermissionError, "%s needs the %s permission, which is not granted", name, capability)
+}
+
+// requirePath is require for a builtin that accesses the file path.
+func (e *Engine) requirePath(capability Capability, name, path string) error {
+	if err := e.require(capability, name); err != nil {
+		return err
+	}
+	if e.Permissions.allowsPath(path) {
+		return nil
+	}
+	return NewJamErrorf(PermissionError, "%s is not allowed to access %s: %s is only granted for %s", name, path, capability, strings.Join(e.Permissions.Paths, ", "))
+}
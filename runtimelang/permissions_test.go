@@ -0,0 +1,118 @@
+package runtimelang_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jamlie/Jamlang/runtimelang"
+)
+
+// tempFile writes content to a file called name in a new temporary
+// directory and returns its path.
+func tempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func wantKind(t *testing.T, err error, kind runtimelang.ErrorKind) {
+	t.Helper()
+	var jamErr *runtimelang.JamError
+	if !errors.As(err, &jamErr) || jamErr.Kind != kind {
+		t.Fatalf("err = %v, want a %s", err, kind)
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	path := tempFile(t, "secret.txt", "secret")
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		for _, permissions := range []runtimelang.Permissions{{}, runtimelang.DefaultPermissions()} {
+			engine.Permissions = permissions
+			_, err := engine.RunString("test.jam", fmt.Sprintf(`OS.open(%q, "r")`, path))
+			wantKind(t, err, runtimelang.PermissionError)
+		}
+
+		engine.Permissions = runtimelang.Permissions{Granted: []runtimelang.Capability{runtimelang.FSRead}}
+		if _, err := engine.RunString("test.jam", fmt.Sprintf(`OS.open(%q, "r")`, path)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestGoCallbackPermissions(t *testing.T) {
+	path := tempFile(t, "secret.txt", "secret")
+	scripts := []string{
+		`apply(fn(path, mode) { return OS.open(path, mode) })`,
+		`apply(OS.open)`,
+	}
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		err := engine.Environment().DeclareGoFunction("apply", func(open func(string, string) any) any {
+			return open(path, "r")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, script := range scripts {
+			engine.Permissions = runtimelang.Permissions{}
+			_, err := engine.RunString("test.jam", script)
+			wantKind(t, err, runtimelang.PermissionError)
+
+			engine.Permissions = runtimelang.AllPermissions()
+			if _, err := engine.RunString("test.jam", script); err != nil {
+				t.Fatalf("%s: %v", script, err)
+			}
+		}
+	})
+}
+
+func TestCallFunctionGrantsNothing(t *testing.T) {
+	path := tempFile(t, "secret.txt", "secret")
+	engine := runtimelang.NewEngine()
+	engine.Permissions = runtimelang.AllPermissions()
+	open, err := engine.RunString("test.jam", "OS.open")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := []runtimelang.RuntimeValue{runtimelang.MakeStringValue(path), runtimelang.MakeStringValue("r")}
+	_, err = runtimelang.CallFunction(open, args)
+	wantKind(t, err, runtimelang.PermissionError)
+}
+
+func TestImportPaths(t *testing.T) {
+	allowed := tempFile(t, "allowed.jam", "fn allowed() { return 1 }")
+	denied := tempFile(t, "denied.jam", "fn denied() { return 2 }")
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		engine.Permissions.Paths = []string{filepath.Dir(allowed)}
+		if _, err := engine.RunString("test.jam", fmt.Sprintf(`import %q;`, allowed)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := engine.RunString("test.jam", fmt.Sprintf(`import %q;`, denied))
+		wantKind(t, err, runtimelang.PermissionError)
+	})
+}
+
+func TestExit(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine *runtimelang.Engine) {
+		_, err := engine.RunString("test.jam", "exit(3)")
+		wantKind(t, err, runtimelang.PermissionError)
+
+		engine.Permissions = runtimelang.AllPermissions()
+		_, err = engine.RunString("test.jam", "try { exit(3) } catch (e) { println(e) } finally { println(1) }")
+		var exitErr *runtimelang.ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+			t.Fatalf("err = %v, want exit status 3", err)
+		}
+		if output := engine.Stdout.(*bytes.Buffer).String(); output != "" {
+			t.Errorf("output = %q, want catch and finally not to run", output)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package runtimelang
+
+import "math"
+
+func jamlangOk(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 {
+		return nil, NewJamError(ArgumentError, "Ok takes 1 argument")
+	}
+
+	return MakeOkValue(args[0]), nil
+}
+
+func jamlangErr(args []RuntimeValue, environment Environment) (RuntimeValue, error) {
+	if len(args) != 1 {
+		return nil, NewJamError(ArgumentError, "Err takes 1 argument")
+	}
+
+	return MakeErrValue(args[0]), nil
+}
+
+// jamlangTry makes the non-fatal variant of a fallible builtin: it returns Ok
+// with the result of call, or Err with the error object try/catch would see,
+// positioned at the call.
+func jamlangTry(call FunctionCall) FunctionCall {
+	return func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		value, err := call(args, env)
+		if err != nil {
+			if !isCatchable(err) {
+				return nil, err
+			}
+			return MakeErrValue(errorValue(withPosition(err, env.callPosition))), nil
+		}
+		return MakeOkValue(value), nil
+	}
+}
+
+// jamlangTryInt is jamlangTry for call, the conversion to the integer type
+// typeName of the given bits. Numbers outside of its range give Err instead
+// of wrapping around as they do with call.
+func jamlangTryInt(call FunctionCall, typeName string, bits int) FunctionCall {
+	return jamlangTry(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) == 1 && !fitsInt(args[0], bits) {
+			return nil, NewJamErrorf(ArithmeticError, "%s overflows %s", args[0].ToString(), typeName)
+		}
+		return call(args, env)
+	})
+}
+
+// fitsInt reports whether value, if it is a number, is in the range of a
+// signed integer of the given bits once its fraction is dropped.
+func fitsInt(value RuntimeValue, bits int) bool {
+	switch value := value.(type) {
+	case IntValue:
+		n := int64(value.GetInt())
+		return n >= -1<<(bits-1) && n <= 1<<(bits-1)-1
+	case FloatValue:
+		limit := math.Ldexp(1, bits-1)
+		n := math.Trunc(value.GetFloat())
+		return n >= -limit && n < limit
+	}
+	return true
+}
+
+func jamlangResultIsOk(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "isOk takes 0 arguments")
+		}
+
+		return MakeBoolValue(result.Ok), nil
+	}, "isOk")
+}
+
+func jamlangResultIsErr(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "isErr takes 0 arguments")
+		}
+
+		return MakeBoolValue(!result.Ok), nil
+	}, "isErr")
+}
+
+func jamlangResultUnwrap(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "unwrap takes 0 arguments")
+		}
+
+		if !result.Ok {
+			return nil, newThrownError(result.Value)
+		}
+		return result.Value, nil
+	}, "unwrap")
+}
+
+func jamlangResultUnwrapErr(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "unwrapErr takes 0 arguments")
+		}
+
+		if result.Ok {
+			return nil, NewJamErrorf(TypeError, "unwrapErr called on %s", result.ToString())
+		}
+		return result.Value, nil
+	}, "unwrapErr")
+}
+
+func jamlangResultUnwrapOr(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "unwrapOr takes 1 argument")
+		}
+
+		if !result.Ok {
+			return args[0], nil
+		}
+		return result.Value, nil
+	}, "unwrapOr")
+}
+
+func jamlangResultMap(result ResultValue) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 1 {
+			return nil, NewJamError(ArgumentError, "map takes 1 argument")
+		}
+
+		if !result.Ok {
+			return result, nil
+		}
+
+		value, err := callback(args[0], env, result.Value)
+		if err != nil {
+			return nil, err
+		}
+		return MakeOkValue(value), nil
+	}, "map")
+}
@@ -1,335 +1,355 @@
 package runtimelang
 
 import (
-	"fmt"
-	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 func jamlangStringToUpper(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: toUpper takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "toUpper takes 0 arguments")
 		}
 
-		return MakeStringValue(strings.ToUpper(str))
+		return MakeStringValue(strings.ToUpper(str)), nil
 	}, "toUpper")
 }
 
 func jamlangStringToLower(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: toLower takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "toLower takes 0 arguments")
 		}
 
-		return MakeStringValue(strings.ToLower(str))
+		return MakeStringValue(strings.ToLower(str)), nil
 	}, "toLower")
 }
 
 func jamlangStringContains(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: contains takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "contains takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: contains takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "contains takes a string as an argument")
 		}
 
-		return MakeBoolValue(strings.Contains(str, args[0].(StringValue).Value))
+		return MakeBoolValue(strings.Contains(str, args[0].(StringValue).Value)), nil
 	}, "contains")
 }
 
 func jamlangStringSplit(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: split takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "split takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: split takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "split takes a string as an argument")
 		}
 
 		var split []RuntimeValue
 		for _, s := range strings.Split(str, args[0].(StringValue).Value) {
 			split = append(split, MakeStringValue(s))
 		}
-		return MakeArrayValue(split)
+		return MakeArrayValue(split), nil
 	}, "split")
 }
 
 func jamlangStringEqualsIgnoreCase(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: equalsIgnoreCase takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "equalsIgnoreCase takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: equalsIgnoreCase takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "equalsIgnoreCase takes a string as an argument")
 		}
 
-		return MakeBoolValue(strings.EqualFold(str, args[0].(StringValue).Value))
+		return MakeBoolValue(strings.EqualFold(str, args[0].(StringValue).Value)), nil
 	}, "equalsIgnoreCase")
 }
 
 func jamlangStringStartsWith(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: startsWith takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "startsWith takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: startsWith takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "startsWith takes a string as an argument")
 		}
 
-		return MakeBoolValue(strings.HasPrefix(str, args[0].(StringValue).Value))
+		return MakeBoolValue(strings.HasPrefix(str, args[0].(StringValue).Value)), nil
 	}, "startsWith")
 }
 
 func jamlangStringEndsWith(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: endsWith takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "endsWith takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: endsWith takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "endsWith takes a string as an argument")
 		}
 
-		return MakeBoolValue(strings.HasSuffix(str, args[0].(StringValue).Value))
+		return MakeBoolValue(strings.HasSuffix(str, args[0].(StringValue).Value)), nil
 	}, "endsWith")
 }
 
 func jamlangStringIndexOf(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: indexOf takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "indexOf takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: indexOf takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "indexOf takes a string as an argument")
 		}
 
-		return MakeInt32Value(int32(strings.Index(str, args[0].(StringValue).Value)))
+		return MakeInt32Value(int32(runeIndex(str, strings.Index(str, args[0].(StringValue).Value)))), nil
 	}, "indexOf")
 }
 
 func jamlangStringLastIndexOf(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: lastIndexOf takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "lastIndexOf takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: lastIndexOf takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "lastIndexOf takes a string as an argument")
 		}
 
-		return MakeInt32Value(int32(strings.LastIndex(str, args[0].(StringValue).Value)))
+		return MakeInt32Value(int32(runeIndex(str, strings.LastIndex(str, args[0].(StringValue).Value)))), nil
 	}, "lastIndexOf")
 }
 
 func jamlangStringSubstring(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 2 {
-			fmt.Fprintln(os.Stderr, "Error: substring takes 2 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "substring takes 2 arguments")
 		}
 
-		if (!isNumber(args[0])) || (!isNumber(args[1])) {
-			fmt.Fprintln(os.Stderr, "Error: substring takes 2 integers as arguments")
-			os.Exit(0)
+		start, ok := args[0].(IntValue)
+		end, ok2 := args[1].(IntValue)
+		if !ok || !ok2 {
+			return nil, NewJamError(TypeError, "substring takes 2 integers as arguments")
 		}
 
-		var first RuntimeValue
-		var second RuntimeValue
-
-		switch args[0].Type() {
-		case I8:
-			first = args[0].(Int8Value)
-		case I16:
-			first = args[0].(Int16Value)
-		case I32:
-			first = args[0].(Int32Value)
-		case I64:
-			first = args[0].(Int64Value)
-		}
-
-		switch args[1].Type() {
-		case I8:
-			second = args[1].(Int8Value)
-		case I16:
-			second = args[1].(Int16Value)
-		case I32:
-			second = args[1].(Int32Value)
-		case I64:
-			second = args[1].(Int64Value)
+		runes := []rune(str)
+		if start.GetInt() < 0 || start.GetInt() > end.GetInt() || end.GetInt() > len(runes) {
+			return nil, NewJamError(IndexError, "substring index out of bounds")
 		}
 
-		return MakeStringValue(str[first.(IntValue).GetInt():second.(IntValue).GetInt()])
-
+		return MakeStringValue(string(runes[start.GetInt():end.GetInt()])), nil
 	}, "substring")
 }
 
 func jamlangStringReplace(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 2 {
-			fmt.Fprintln(os.Stderr, "Error: replace takes 2 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "replace takes 2 arguments")
 		}
 
-		if isNumber(args[0]) {
-			num := args[0].(IntValue).GetInt()
-			return MakeStringValue(str[:num] + args[1].(StringValue).Value + str[num+1:])
+		if args[1].Type() != String {
+			return nil, NewJamError(TypeError, "replace takes 2 strings or a number and a string as arguments")
 		}
 
-		if args[0].Type() != String || args[1].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: replace takes 2 strings or a number and a string as arguments")
-			os.Exit(0)
+		if index, ok := args[0].(IntValue); ok {
+			runes := []rune(str)
+			num := index.GetInt()
+			if num < 0 || num >= len(runes) {
+				return nil, NewJamError(IndexError, "replace index out of bounds")
+			}
+			return MakeStringValue(string(runes[:num]) + args[1].(StringValue).Value + string(runes[num+1:])), nil
 		}
 
-		return MakeStringValue(strings.Replace(str, args[0].(StringValue).Value, args[1].(StringValue).Value, -1))
+		if args[0].Type() != String {
+			return nil, NewJamError(TypeError, "replace takes 2 strings or a number and a string as arguments")
+		}
+
+		return MakeStringValue(strings.Replace(str, args[0].(StringValue).Value, args[1].(StringValue).Value, -1)), nil
 	}, "replace")
 }
 
 func jamlangStringTrim(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: trim takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "trim takes 0 arguments")
 		}
 
-		return MakeStringValue(strings.TrimSpace(str))
+		return MakeStringValue(strings.TrimSpace(str)), nil
 	}, "trim")
 }
 
 func jamlangStringTrimLeft(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: trimLeft takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "trimLeft takes 0 arguments")
 		}
 
 		return MakeStringValue(strings.TrimLeftFunc(str, func(r rune) bool {
 			return r == ' ' || r == '\t' || r == '\n' || r == '\r'
-		}))
+		})), nil
 	}, "trimLeft")
 }
 
 func jamlangStringTrimRight(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: trimRight takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "trimRight takes 0 arguments")
 		}
 
 		return MakeStringValue(strings.TrimRightFunc(str, func(r rune) bool {
 			return r == ' ' || r == '\t' || r == '\n' || r == '\r'
-		}))
+		})), nil
 	}, "trimRight")
 }
 
 func jamlangStringRepeat(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: repeat takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "repeat takes 1 argument")
 		}
 
-		if args[0].Type() != Number {
-			fmt.Fprintln(os.Stderr, "Error: repeat takes a number as an argument")
-			os.Exit(0)
+		count, ok := args[0].(IntValue)
+		if !ok || count.GetInt() < 0 {
+			return nil, NewJamError(TypeError, "repeat takes a non-negative integer as an argument")
 		}
 
-		return MakeStringValue(strings.Repeat(str, int(args[0].(NumberValue[any]).GetV().(float64))))
+		if err := env.engine.checkSize(len(str) * count.GetInt()); err != nil {
+			return nil, err
+		}
+		return MakeStringValue(strings.Repeat(str, count.GetInt())), nil
 	}, "repeat")
 }
 
 func jamlangStringLeftPad(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 2 {
-			fmt.Fprintln(os.Stderr, "Error: leftPad takes 2 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "leftPad takes 2 arguments")
 		}
 
-		if args[0].Type() != Number || args[1].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: leftPad takes a number and a string as arguments")
-			os.Exit(0)
+		length, ok := args[0].(IntValue)
+		if !ok || args[1].Type() != String || args[1].(StringValue).Value == "" {
+			return nil, NewJamError(TypeError, "leftPad takes a number and a non-empty string as arguments")
+		}
+		if err := env.engine.checkSize(length.GetInt()); err != nil {
+			return nil, err
 		}
 
-		i := args[0].(NumberValue[any]).GetV().(int32)
-		j := fmt.Sprintf("%d", i)
-		return MakeStringValue(fmt.Sprintf("%"+j+"s", str))
+		return MakeStringValue(padding(str, length.GetInt(), args[1].(StringValue).Value) + str), nil
 	}, "leftPad")
 }
 
 func jamlangStringRightPad(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 2 {
-			fmt.Fprintln(os.Stderr, "Error: rightPad takes 2 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "rightPad takes 2 arguments")
 		}
 
-		if args[0].Type() != Number || args[1].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: rightPad takes a number and a string as arguments")
-			os.Exit(0)
+		length, ok := args[0].(IntValue)
+		if !ok || args[1].Type() != String || args[1].(StringValue).Value == "" {
+			return nil, NewJamError(TypeError, "rightPad takes a number and a non-empty string as arguments")
+		}
+		if err := env.engine.checkSize(length.GetInt()); err != nil {
+			return nil, err
 		}
 
-		i := args[0].(NumberValue[any]).GetV().(int32)
-		j := fmt.Sprintf("%d", i)
-		return MakeStringValue(fmt.Sprintf("%-"+j+"s", str))
+		return MakeStringValue(str + padding(str, length.GetInt(), args[1].(StringValue).Value)), nil
 	}, "rightPad")
 }
 
 func jamlangStringShift(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: shift takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "shift takes 0 arguments")
+		}
+
+		if str == "" {
+			return nil, NewJamError(IndexError, "shift on empty string")
 		}
 
-		return MakeStringValue(str[1:])
+		_, size := utf8.DecodeRuneInString(str)
+		return MakeStringValue(str[size:]), nil
 	}, "shift")
 }
 
 func jamlangStringPush(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Error: push takes 1 argument")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "push takes 1 argument")
 		}
 
 		if args[0].Type() != String {
-			fmt.Fprintln(os.Stderr, "Error: push takes a string as an argument")
-			os.Exit(0)
+			return nil, NewJamError(TypeError, "push takes a string as an argument")
 		}
 
-		return MakeStringValue(str + args[0].(StringValue).Value)
+		return MakeStringValue(str + args[0].(StringValue).Value), nil
 	}, "push")
 }
 
 func jamlangStringPop(str string) RuntimeValue {
-	return MakeNativeFunction(func(args []RuntimeValue, env Environment) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
 		if len(args) != 0 {
-			fmt.Fprintln(os.Stderr, "Error: pop takes 0 arguments")
-			os.Exit(0)
+			return nil, NewJamError(ArgumentError, "pop takes 0 arguments")
 		}
 
-		return MakeStringValue(str[:len(str)-1])
+		if str == "" {
+			return nil, NewJamError(IndexError, "pop on empty string")
+		}
+
+		_, size := utf8.DecodeLastRuneInString(str)
+		return MakeStringValue(str[:len(str)-size]), nil
 	}, "pop")
 }
+
+func jamlangStringBytes(str string) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "bytes takes 0 arguments")
+		}
+
+		bytes := make([]RuntimeValue, len(str))
+		for i := 0; i < len(str); i++ {
+			bytes[i] = MakeInt32Value(int32(str[i]))
+		}
+		return MakeArrayValue(bytes), nil
+	}, "bytes")
+}
+
+func jamlangStringRunes(str string) RuntimeValue {
+	return MakeNativeFunction(func(args []RuntimeValue, env Environment) (RuntimeValue, error) {
+		if len(args) != 0 {
+			return nil, NewJamError(ArgumentError, "runes takes 0 arguments")
+		}
+
+		runes := []RuntimeValue{}
+		for _, r := range str {
+			runes = append(runes, MakeInt32Value(r))
+		}
+		return MakeArrayValue(runes), nil
+	}, "runes")
+}
+
+// runeIndex converts index, a byte offset into str or -1, to a rune offset.
+func runeIndex(str string, index int) int {
+	if index < 0 {
+		return index
+	}
+	return utf8.RuneCountInString(str[:index])
+}
+
+// padding returns pad repeated and cut to the number of runes str needs to
+// be length runes long.
+func padding(str string, length int, pad string) string {
+	missing := length - utf8.RuneCountInString(str)
+	if missing <= 0 {
+		return ""
+	}
+	padRunes := []rune(strings.Repeat(pad, missing))
+	return string(padRunes[:missing])
+}
@@ -0,0 +1,180 @@
+package runtimelang
+
+import (
+	"reflect"
+
+	"github.com/Jamlie/Jamlang/ast"
+	"github.com/Jamlie/Jamlang/parser"
+)
+
+// typeChecker validates values against user-defined types. Names are
+// resolved in env; visiting guards against cyclic objects. The narrowed
+// fields of each object are kept in commits until the whole value fits.
+type typeChecker struct {
+	env      *Environment
+	visiting map[uintptr]bool
+	commits  []func()
+}
+
+// conformToType checks value against typeExpr, an Identifier naming a
+// builtin type or a type declared with `type`, or an ObjectTypeLiteral.
+// Numbers inside the value are narrowed to the declared types, only if it
+// fits. what names the checked value in error messages.
+func conformToType(value RuntimeValue, typeExpr ast.Expression, env *Environment, what string) (RuntimeValue, error) {
+	checker := typeChecker{
+		env:      env,
+		visiting: make(map[uintptr]bool),
+	}
+	value, err := checker.conform(value, typeExpr, what, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, commit := range checker.commits {
+		commit()
+	}
+	return value, nil
+}
+
+func (c *typeChecker) conform(value RuntimeValue, typeExpr ast.Expression, what, typeName string) (RuntimeValue, error) {
+	switch typeExpr := typeExpr.(type) {
+	case *ast.Identifier:
+		resolved, err := c.resolve(typeExpr.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if varType, ok := resolved.(*ast.Identifier); ok {
+			return conformToVarType(value, parser.Types[varType.Symbol], what)
+		}
+		return c.conform(value, resolved, what, typeExpr.Symbol)
+	case *ast.ObjectTypeLiteral:
+		return c.conformObject(value, typeExpr, what, typeName)
+	default:
+		return nil, NewJamErrorf(TypeError, "Invalid type %s", typeExpr.ToString())
+	}
+}
+
+// resolve follows type aliases until it reaches an object type or an
+// Identifier naming a builtin type.
+func (c *typeChecker) resolve(name string) (ast.Expression, error) {
+	seen := make(map[string]bool)
+	for {
+		if _, ok := parser.Types[name]; ok {
+			return &ast.Identifier{Symbol: name}, nil
+		}
+		if seen[name] {
+			return nil, NewJamErrorf(TypeError, "Type %s refers to itself", name)
+		}
+		seen[name] = true
+
+		value, err := c.env.LookupVariable(name)
+		if err != nil {
+			return nil, NewJamErrorf(ReferenceError, "Unknown type %s", name)
+		}
+		typeValue, ok := value.(TypeValue)
+		if !ok {
+			return nil, NewJamErrorf(TypeError, "%s is not a type", name)
+		}
+
+		identifier, ok := typeValue.Value.(*ast.Identifier)
+		if !ok {
+			return typeValue.Value, nil
+		}
+		name = identifier.Symbol
+	}
+}
+
+func (c *typeChecker) conformObject(value RuntimeValue, typeExpr *ast.ObjectTypeLiteral, what, typeName string) (RuntimeValue, error) {
+	if typeName == "" {
+		typeName = typeExpr.ToString()
+	}
+
+	if _, ok := value.(NullValue); ok {
+		return value, nil
+	}
+	object, ok := value.(ObjectValue)
+	if !ok {
+		return nil, NewJamErrorf(TypeError, "Expected %s for %s, got %s", typeName, what, value.VarType())
+	}
+
+	id := reflect.ValueOf(object.Properties).Pointer()
+	if c.visiting[id] {
+		return value, nil
+	}
+	c.visiting[id] = true
+	defer delete(c.visiting, id)
+
+	fields := make(map[string]bool)
+	narrowed := make(map[string]RuntimeValue, len(typeExpr.Fields))
+	for _, field := range typeExpr.Fields {
+		fields[field.Name] = true
+
+		property, ok := object.Properties[field.Name]
+		if !ok {
+			return nil, NewJamErrorf(TypeError, "%s is missing field %s of %s", what, field.Name, typeName)
+		}
+		property, err := c.conform(property, field.Type, what+"."+field.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		narrowed[field.Name] = property
+	}
+
+	for _, key := range object.Keys() {
+		if !fields[key] {
+			return nil, NewJamErrorf(TypeError, "%s has unexpected field %s, not in %s", what, key, typeName)
+		}
+	}
+
+	c.commits = append(c.commits, func() {
+		for key, property := range narrowed {
+			object.Properties[key] = property
+		}
+	})
+	return object, nil
+}
+
+// conformToVarType checks value against a builtin type, narrowing integers
+// and floats that fit the declared size.
+func conformToVarType(value RuntimeValue, varType ast.VariableType, what string) (RuntimeValue, error) {
+	fits := true
+	switch varType {
+	case ast.AnyType:
+		return value, nil
+	case ast.Int8Type, ast.Int16Type, ast.Int32Type, ast.Int64Type:
+		intValue, ok := value.(IntValue)
+		if !ok {
+			fits = false
+			break
+		}
+		n := float64(intValue.GetInt())
+		switch varType {
+		case ast.Int8Type:
+			fits = isInt8(n)
+		case ast.Int16Type:
+			fits = isInt16(n)
+		case ast.Int32Type:
+			fits = isInt32(n)
+		}
+	case ast.Float32Type, ast.Float64Type:
+		floatValue, ok := value.(FloatValue)
+		if !ok {
+			fits = false
+			break
+		}
+		fits = varType == ast.Float64Type || isFloat32(floatValue.GetFloat())
+	case ast.ObjectType:
+		_, isNull := value.(NullValue)
+		_, isObject := value.(ObjectValue)
+		fits = isNull || isObject
+	case ast.FunctionType:
+		fits = value.Type() == Function || value.Type() == NativeFunction
+	default:
+		fits = value.VarType() == varType
+	}
+
+	if !fits {
+		return nil, NewJamErrorf(TypeError, "Expected %s for %s, got %s", varType, what, value.VarType())
+	}
+
+	return makeValueWithVarType(value, varType)
+}
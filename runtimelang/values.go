@@ -1,7 +1,12 @@
 package runtimelang
 
 import (
+	"fmt"
+	"math"
 	"os"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 
 	"github.com/Jamlie/Jamlang/ast"
@@ -28,8 +33,12 @@ const (
 	Break          ValueType = "break"
 	Continue       ValueType = "continue"
 	Class          ValueType = "class"
+	Super          ValueType = "super"
 	File           ValueType = "file"
 	Type           ValueType = "type"
+	Result         ValueType = "result"
+	Map            ValueType = "map"
+	Set            ValueType = "set"
 	JSON      		 ValueType = "json_value"
 )
 
@@ -114,15 +123,40 @@ type IntValue interface {
 	GetInt() int
 }
 
+// equalNumbers reports whether a and b are numbers with the same value,
+// whatever their sizes and whether they are integers or floats. It is the
+// rule hashKey uses, so numbers that are equal are the same Map key.
+func equalNumbers(a, b RuntimeValue) bool {
+	switch a := a.(type) {
+	case IntValue:
+		switch b := b.(type) {
+		case IntValue:
+			return a.GetInt() == b.GetInt()
+		case FloatValue:
+			return floatEqualsInt(b.GetFloat(), a.GetInt())
+		}
+	case FloatValue:
+		switch b := b.(type) {
+		case IntValue:
+			return floatEqualsInt(a.GetFloat(), b.GetInt())
+		case FloatValue:
+			return a.GetFloat() == b.GetFloat()
+		}
+	}
+	return false
+}
+
+// floatEqualsInt reports whether f holds the whole number n.
+func floatEqualsInt(f float64, n int) bool {
+	return f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 && int64(f) == int64(n)
+}
+
 type Int8Value struct {
 	Value int8
 }
 
 func (v Int8Value) Equals(other RuntimeValue) bool {
-	if other.Type() == I8 {
-		return v.Value == other.Get().(int8)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Int8Value) Type() ValueType {
@@ -162,10 +196,7 @@ type Int16Value struct {
 }
 
 func (v Int16Value) Equals(other RuntimeValue) bool {
-	if other.Type() == I16 || other.Type() == I8 {
-		return v.Value == other.Get().(int16)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Int16Value) Type() ValueType {
@@ -205,10 +236,7 @@ type Int32Value struct {
 }
 
 func (v Int32Value) Equals(other RuntimeValue) bool {
-	if other.Type() == I32 || other.Type() == I16 || other.Type() == I8 {
-		return v.Value == other.Get().(int32)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Int32Value) Type() ValueType {
@@ -248,10 +276,7 @@ type Int64Value struct {
 }
 
 func (v Int64Value) Equals(other RuntimeValue) bool {
-	if other.Type() == I64 || other.Type() == I32 || other.Type() == I16 || other.Type() == I8 {
-		return v.Value == other.Get().(int64)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Int64Value) Type() ValueType {
@@ -295,10 +320,7 @@ type Float32Value struct {
 }
 
 func (v Float32Value) Equals(other RuntimeValue) bool {
-	if other.Type() == F32 {
-		return v.Value == other.Get().(float32)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Float32Value) Type() ValueType {
@@ -338,10 +360,7 @@ type Float64Value struct {
 }
 
 func (v Float64Value) Equals(other RuntimeValue) bool {
-	if other.Type() == F64 || other.Type() == F32 {
-		return v.Value == other.Get().(float64)
-	}
-	return false
+	return equalNumbers(v, other)
 }
 
 func (v Float64Value) Type() ValueType {
@@ -427,12 +446,7 @@ func (v StringValue) Type() ValueType {
 }
 
 func (v StringValue) Get() any {
-	str := v.Value
-	decodedStr, err := strconv.Unquote(`"` + str + `"`)
-	if err != nil {
-		return str
-	}
-	return decodedStr
+	return v.Value
 }
 
 func (v StringValue) ToString() string {
@@ -488,25 +502,72 @@ func MakeBoolValue(value bool) BoolValue {
 
 type ObjectValue struct {
 	Properties map[string]RuntimeValue
+	Class      *ClassValue
+	// order holds the keys of Properties in the order they were added. It
+	// is shared by copies of the object, like Properties.
+	order *[]string
+}
+
+// newObjectValue makes an empty object that keeps its keys in the order they
+// are set in.
+func newObjectValue(class *ClassValue, size int) ObjectValue {
+	order := make([]string, 0, size)
+	return ObjectValue{
+		Properties: make(map[string]RuntimeValue, size),
+		Class:      class,
+		order:      &order,
+	}
 }
 
-func (v ObjectValue) Equals(other RuntimeValue) bool {
-	if other.Type() == Object {
-		otherObj, ok := other.(ObjectValue)
-		if !ok {
-			return false
-		}
-		if len(v.Properties) != len(otherObj.Properties) {
-			return false
+// Keys returns the keys of v in the order they were added. Keys added to
+// Properties directly, rather than with Set, come last, sorted.
+func (v ObjectValue) Keys() []string {
+	keys := make([]string, 0, len(v.Properties))
+	seen := make(map[string]bool, len(v.Properties))
+	if v.order != nil {
+		for _, key := range *v.order {
+			if _, ok := v.Properties[key]; ok && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
 		}
-		for key, value := range v.Properties {
-			if !value.Equals(otherObj.Properties[key]) {
-				return false
+	}
+
+	if len(keys) < len(v.Properties) {
+		rest := make([]string, 0, len(v.Properties)-len(keys))
+		for key := range v.Properties {
+			if !seen[key] {
+				rest = append(rest, key)
 			}
 		}
-		return true
+		sort.Strings(rest)
+		keys = append(keys, rest...)
 	}
-	return false
+	return keys
+}
+
+// Set sets the property key of v to value. New keys go after the ones v
+// already has.
+func (v ObjectValue) Set(key string, value RuntimeValue) {
+	if _, ok := v.Properties[key]; !ok && v.order != nil {
+		*v.order = append(*v.order, key)
+	}
+	v.Properties[key] = value
+}
+
+// Delete removes the property key of v.
+func (v ObjectValue) Delete(key string) {
+	if _, ok := v.Properties[key]; !ok {
+		return
+	}
+	delete(v.Properties, key)
+	if v.order != nil {
+		*v.order = slices.DeleteFunc(*v.order, func(other string) bool { return other == key })
+	}
+}
+
+func (v ObjectValue) Equals(other RuntimeValue) bool {
+	return equalValues(v, other, map[[2]any]bool{})
 }
 
 func (v ObjectValue) Type() ValueType {
@@ -514,23 +575,25 @@ func (v ObjectValue) Type() ValueType {
 }
 
 func (v ObjectValue) Get() any {
+	return formatValue(v, map[any]bool{})
+}
+
+func (v ObjectValue) format(visiting map[any]bool) string {
 	str := "{ "
+	if v.Class != nil {
+		str = v.Class.Name + " { "
+	}
 	counter := 0
-	for key, value := range v.Properties {
+	for _, key := range v.Keys() {
+		value := v.Properties[key]
 		counter++
 		str += key + ": "
 
 		switch value.Type() {
-		case Null:
-			str += "null"
-		case I8, I16, I32, I64, F32, F64, String, Bool, Object, Array, Tuple:
-			str += value.ToString()
-		case Function:
-			str += value.Get().(string)
-		case NativeFunction:
-			str += value.Get().(string)
+		case Function, NativeFunction, Class:
+			str += fmt.Sprint(value.Get())
 		default:
-			str += "unknown"
+			str += formatValue(value, visiting)
 		}
 
 		if counter < len(v.Properties) {
@@ -546,17 +609,199 @@ func (v ObjectValue) ToString() string {
 }
 
 func (v ObjectValue) Clone() RuntimeValue {
-	newObject := ObjectValue{Properties: make(map[string]RuntimeValue)}
-	for key, value := range v.Properties {
-		newObject.Properties[key] = value.Clone()
-	}
-	return newObject
+	return cloneValue(v, map[any]RuntimeValue{})
 }
 
 func (v ObjectValue) VarType() ast.VariableType {
 	return ast.ObjectType
 }
 
+// cloneValue copies value and the arrays, objects, maps and sets in it.
+// copies maps the arrays, objects and maps already copied to their copies, so
+// that a value that contains itself is copied into one that contains the
+// copy.
+func cloneValue(value RuntimeValue, copies map[any]RuntimeValue) RuntimeValue {
+	switch value := value.(type) {
+	case *ArrayValue:
+		if copied, ok := copies[value]; ok {
+			return copied
+		}
+		array := MakeArrayValue(make([]RuntimeValue, len(value.Values)))
+		copies[value] = array
+		for i, element := range value.Values {
+			array.Values[i] = cloneValue(element, copies)
+		}
+		return array
+	case ObjectValue:
+		identity := reflect.ValueOf(value.Properties).UnsafePointer()
+		if copied, ok := copies[identity]; ok {
+			return copied
+		}
+		object := newObjectValue(value.Class, len(value.Properties))
+		copies[identity] = object
+		for _, key := range value.Keys() {
+			object.Set(key, cloneValue(value.Properties[key], copies))
+		}
+		return object
+	case *MapValue:
+		if copied, ok := copies[value]; ok {
+			return copied
+		}
+		m := MakeMapValue()
+		copies[value] = m
+		for i, key := range value.entries.keys {
+			m.entries.set(key, cloneValue(value.entries.values[i], copies))
+		}
+		return m
+	case *SetValue:
+		set := MakeSetValue()
+		for _, key := range value.entries.keys {
+			set.entries.set(key, key)
+		}
+		return set
+	case TupleValue:
+		tuple := TupleValue{Values: make([]RuntimeValue, len(value.Values))}
+		for i, element := range value.Values {
+			tuple.Values[i] = cloneValue(element, copies)
+		}
+		return tuple
+	}
+	return value.Clone()
+}
+
+// identityOf returns what tells apart the arrays, objects and maps that may
+// contain themselves, and false for other values.
+func identityOf(value RuntimeValue) (any, bool) {
+	switch value := value.(type) {
+	case *ArrayValue, *MapValue:
+		return value, true
+	case ObjectValue:
+		return reflect.ValueOf(value.Properties).UnsafePointer(), true
+	}
+	return nil, false
+}
+
+// formatValue returns how value prints. visiting holds the arrays, objects
+// and maps being printed, so that one that contains itself prints as
+// [Circular] inside itself instead of forever.
+func formatValue(value RuntimeValue, visiting map[any]bool) string {
+	if identity, ok := identityOf(value); ok {
+		if visiting[identity] {
+			return "[Circular]"
+		}
+		visiting[identity] = true
+		defer delete(visiting, identity)
+	}
+
+	switch value := value.(type) {
+	case *ArrayValue:
+		return "[ " + formatValues(value.Values, visiting) + " ]"
+	case TupleValue:
+		return "( " + formatValues(value.Values, visiting) + " )"
+	case ObjectValue:
+		return value.format(visiting)
+	case *MapValue:
+		if value.entries.len() == 0 {
+			return "Map {}"
+		}
+		str := "Map { "
+		for i, key := range value.entries.keys {
+			str += key.ToString() + ": " + formatValue(value.entries.values[i], visiting)
+			if i < value.entries.len()-1 {
+				str += ", "
+			}
+		}
+		return str + " }"
+	case ResultValue:
+		if value.Ok {
+			return "Ok(" + formatValue(value.Value, visiting) + ")"
+		}
+		return "Err(" + formatValue(value.Value, visiting) + ")"
+	}
+	return value.ToString()
+}
+
+func formatValues(values []RuntimeValue, visiting map[any]bool) string {
+	str := ""
+	for i, value := range values {
+		str += formatValue(value, visiting)
+		if i < len(values)-1 {
+			str += ", "
+		}
+	}
+	return str
+}
+
+// equalValues reports whether a and b are equal. comparing holds the pairs
+// of arrays, objects and maps being compared, which are taken to be equal
+// when they are reached again, so that values that contain themselves are
+// compared without recursing forever.
+func equalValues(a, b RuntimeValue, comparing map[[2]any]bool) bool {
+	if aIdentity, ok := identityOf(a); ok {
+		bIdentity, ok := identityOf(b)
+		if !ok {
+			return false
+		}
+		pair := [2]any{aIdentity, bIdentity}
+		if comparing[pair] {
+			return true
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+	}
+
+	switch a := a.(type) {
+	case *ArrayValue:
+		other, ok := b.(*ArrayValue)
+		return ok && equalSlices(a.Values, other.Values, comparing)
+	case TupleValue:
+		other, ok := b.(TupleValue)
+		return ok && equalSlices(a.Values, other.Values, comparing)
+	case ObjectValue:
+		other, ok := b.(ObjectValue)
+		if !ok || len(a.Properties) != len(other.Properties) {
+			return false
+		}
+		for key, value := range a.Properties {
+			otherValue, found := other.Properties[key]
+			if !found || !equalValues(value, otherValue, comparing) {
+				return false
+			}
+		}
+		return true
+	case *MapValue:
+		other, ok := b.(*MapValue)
+		if !ok || other.entries.len() != a.entries.len() {
+			return false
+		}
+		for i, key := range a.entries.keys {
+			value, found := other.entries.get(key)
+			if !found || !equalValues(a.entries.values[i], value, comparing) {
+				return false
+			}
+		}
+		return true
+	case ResultValue:
+		other, ok := b.(ResultValue)
+		return ok && a.Ok == other.Ok && equalValues(a.Value, other.Value, comparing)
+	}
+	return a.Equals(b)
+}
+
+func equalSlices(a, b []RuntimeValue, comparing map[[2]any]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, value := range a {
+		if !equalValues(value, b[i], comparing) {
+			return false
+		}
+	}
+	return true
+}
+
+// ArrayValue is used by pointer, so that every variable and property holding
+// an array sees the changes push, pop and the other methods make to it.
 type ArrayValue struct {
 	Values []RuntimeValue
 }
@@ -574,49 +819,27 @@ func (v *ArrayValue) Pop() RuntimeValue {
 	return MakeNullValue()
 }
 
-func (v ArrayValue) Equals(other RuntimeValue) bool {
-	if other.Type() == Array {
-		otherArray := other.(ArrayValue)
-		if len(v.Values) != len(otherArray.Values) {
-			return false
-		}
-		for i, value := range v.Values {
-			if !value.Equals(otherArray.Values[i]) {
-				return false
-			}
-		}
-		return true
-	}
-	return false
+func (v *ArrayValue) Equals(other RuntimeValue) bool {
+	return equalValues(v, other, map[[2]any]bool{})
 }
 
-func (v ArrayValue) Type() ValueType {
+func (v *ArrayValue) Type() ValueType {
 	return Array
 }
 
-func (v ArrayValue) Get() any {
-	str := "[ "
-	for i, value := range v.Values {
-		str += value.ToString()
-		if i < len(v.Values)-1 {
-			str += ", "
-		}
-	}
-	str += " ]"
-	return str
+func (v *ArrayValue) Get() any {
+	return formatValue(v, map[any]bool{})
 }
 
-func (v ArrayValue) ToString() string {
+func (v *ArrayValue) ToString() string {
 	return v.Get().(string)
 }
 
-func (v ArrayValue) Clone() RuntimeValue {
-	newArray := ArrayValue{Values: make([]RuntimeValue, len(v.Values))}
-	copy(newArray.Values, v.Values)
-	return newArray
+func (v *ArrayValue) Clone() RuntimeValue {
+	return cloneValue(v, map[any]RuntimeValue{})
 }
 
-func (v ArrayValue) VarType() ast.VariableType {
+func (v *ArrayValue) VarType() ast.VariableType {
 	return ast.ArrayType
 }
 
@@ -625,19 +848,7 @@ type TupleValue struct {
 }
 
 func (v TupleValue) Equals(other RuntimeValue) bool {
-	if other.Type() == Tuple {
-		otherTuple := other.(TupleValue)
-		if len(v.Values) != len(otherTuple.Values) {
-			return false
-		}
-		for i, value := range v.Values {
-			if !value.Equals(otherTuple.Values[i]) {
-				return false
-			}
-		}
-		return true
-	}
-	return false
+	return equalValues(v, other, map[[2]any]bool{})
 }
 
 func (v TupleValue) Type() ValueType {
@@ -645,15 +856,7 @@ func (v TupleValue) Type() ValueType {
 }
 
 func (v TupleValue) Get() any {
-	str := "( "
-	for i, value := range v.Values {
-		str += value.ToString()
-		if i < len(v.Values)-1 {
-			str += ", "
-		}
-	}
-	str += " )"
-	return str
+	return formatValue(v, map[any]bool{})
 }
 
 func (v TupleValue) ToString() string {
@@ -670,7 +873,7 @@ func (v TupleValue) VarType() ast.VariableType {
 	return ast.TupleType
 }
 
-type FunctionCall func(args []RuntimeValue, env Environment) RuntimeValue
+type FunctionCall func(args []RuntimeValue, env Environment) (RuntimeValue, error)
 
 type NativeFunctionValue struct {
 	Call FunctionCall
@@ -706,13 +909,19 @@ func MakeNativeFunction(call FunctionCall, name string) NativeFunctionValue {
 }
 
 type FunctionValue struct {
-	Name                   string
-	Parameters             []string
-	DeclarationEnvironment Environment
+	Name       string
+	Parameters []ast.Parameter
+	// DeclarationEnvironment is the scope the function was made in. It is
+	// shared, not copied, so the function sees later changes to it, and
+	// every call gets a new scope inside it.
+	DeclarationEnvironment *Environment
 	Body                   []ast.Statement
 	IsAnonymous            bool
 	ReturnType             ast.VariableType
+	UserDefinedReturnType  ast.Expression
 	Call									 FunctionCall
+	// code is the compiled body when the function was created by the VM.
+	code *Chunk
 }
 
 func (v FunctionValue) Equals(other RuntimeValue) bool {
@@ -726,7 +935,7 @@ func (v FunctionValue) Type() ValueType {
 func (v FunctionValue) Get() any {
 	str := "fn " + v.Name + "("
 	for i, param := range v.Parameters {
-		str += param
+		str += param.ToString()
 		if i < len(v.Parameters)-1 {
 			str += ", "
 		}
@@ -741,15 +950,20 @@ func (v FunctionValue) ToString() string {
 
 func (v FunctionValue) Clone() RuntimeValue {
 	name := v.Name
-	parameters := make([]string, len(v.Parameters))
+	parameters := make([]ast.Parameter, len(v.Parameters))
 	copy(parameters, v.Parameters)
 	body := make([]ast.Statement, len(v.Body))
 	copy(body, v.Body)
-	return &FunctionValue{
+	return FunctionValue{
 		Name:                   name,
 		Parameters:             parameters,
 		DeclarationEnvironment: v.DeclarationEnvironment,
 		Body:                   body,
+		IsAnonymous:            v.IsAnonymous,
+		ReturnType:             v.ReturnType,
+		UserDefinedReturnType:  v.UserDefinedReturnType,
+		Call:                   v.Call,
+		code:                   v.code,
 	}
 }
 
@@ -819,62 +1033,127 @@ func (v ContinueType) VarType() ast.VariableType {
 
 type ClassValue struct {
 	Name        string
+	Parent      *ClassValue
 	Constructor *FunctionValue
 	Methods     map[string]*FunctionValue
 	Fields      map[string]RuntimeValue
+	// fieldNames holds the keys of Fields in the order they are declared.
+	fieldNames []string
 }
 
-func (v ClassValue) Equals(other RuntimeValue) bool {
-	return false
+func (v *ClassValue) Equals(other RuntimeValue) bool {
+	otherClass, ok := other.(*ClassValue)
+	return ok && otherClass == v
 }
 
-func (v ClassValue) Type() ValueType {
+func (v *ClassValue) Type() ValueType {
 	return Class
 }
 
-func (v ClassValue) Get() any {
-	return v.Methods
+func (v *ClassValue) Get() any {
+	return "class " + v.Name + " { ... }"
 }
 
-func (v ClassValue) ToString() string {
+func (v *ClassValue) ToString() string {
 	return "class"
 }
 
-func (v ClassValue) Clone() RuntimeValue {
-	methods := make(map[string]*FunctionValue)
-	for k, v := range v.Methods {
-		methods[k] = v.Clone().(*FunctionValue)
+// Clone returns the class itself: classes cannot be modified after they are
+// declared, and instances rely on their identity for instanceof checks.
+func (v *ClassValue) Clone() RuntimeValue {
+	return v
+}
+
+func (v *ClassValue) VarType() ast.VariableType {
+	return ast.AnyType
+}
+
+// FindMethod looks name up on the class and then its ancestors, returning the
+// method and the class that declares it.
+func (v *ClassValue) FindMethod(name string) (*FunctionValue, *ClassValue) {
+	for class := v; class != nil; class = class.Parent {
+		if method, ok := class.Methods[name]; ok {
+			return method, class
+		}
 	}
-	fields := make(map[string]RuntimeValue)
-	for k, v := range v.Fields {
-		fields[k] = v.Clone()
+	return nil, nil
+}
+
+// FindConstructor returns the nearest constructor in the class hierarchy.
+func (v *ClassValue) FindConstructor() (*FunctionValue, *ClassValue) {
+	for class := v; class != nil; class = class.Parent {
+		if class.Constructor != nil {
+			return class.Constructor, class
+		}
 	}
+	return nil, nil
+}
 
-	constructor := v.Constructor.Clone().(*FunctionValue)
-	return ClassValue{Name: v.Name, Constructor: constructor, Methods: methods, Fields: fields}
+func (v *ClassValue) IsSubclassOf(other *ClassValue) bool {
+	for class := v; class != nil; class = class.Parent {
+		if class == other {
+			return true
+		}
+	}
+	return false
 }
 
-func (v ClassValue) VarType() ast.VariableType {
-	return ast.AnyType
+func MakeClassValue(name string, methods map[string]*FunctionValue) *ClassValue {
+	return &ClassValue{Name: name, Methods: methods}
+}
+
+// SuperValue is what `super` evaluates to inside a method: calling it runs the
+// parent constructor and its members are the parent's methods, all bound to
+// the current instance.
+type SuperValue struct {
+	Class *ClassValue
+	This  ObjectValue
+}
+
+func (v SuperValue) Equals(other RuntimeValue) bool {
+	return false
+}
+
+func (v SuperValue) Type() ValueType {
+	return Super
+}
+
+func (v SuperValue) Get() any {
+	return "super"
+}
+
+func (v SuperValue) ToString() string {
+	return "super"
+}
+
+func (v SuperValue) Clone() RuntimeValue {
+	return v
 }
 
-func MakeClassValue(name string, methods map[string]*FunctionValue) ClassValue {
-	return ClassValue{Name: name, Methods: methods}
+func (v SuperValue) VarType() ast.VariableType {
+	return ast.AnyType
 }
 
-func MakeArrayValue(values []RuntimeValue) ArrayValue {
-	return ArrayValue{Values: values}
+func MakeArrayValue(values []RuntimeValue) *ArrayValue {
+	return &ArrayValue{Values: values}
 }
 
 func MakeTupleValue(values []RuntimeValue) TupleValue {
 	return TupleValue{Values: values}
 }
 
+// MakeObjectValue makes an object of properties. Its keys are in sorted
+// order; properties added later with Set follow them.
 func MakeObjectValue(properties map[string]RuntimeValue) ObjectValue {
-	return ObjectValue{Properties: properties}
+	order := make([]string, 0, len(properties))
+	for key := range properties {
+		order = append(order, key)
+	}
+	sort.Strings(order)
+	return ObjectValue{Properties: properties, order: &order}
 }
 
-func ToGoArrayValue(v ArrayValue) []RuntimeValue {
+func ToGoArrayValue(v *ArrayValue) []RuntimeValue {
 	return v.Values
 }
 
@@ -975,11 +1254,11 @@ func (v TypeValue) Type() ValueType {
 }
 
 func (v TypeValue) Get() any {
-	return v.Value
+	return v.ToString()
 }
 
 func (v TypeValue) ToString() string {
-	return "type"
+	return "type " + v.Name + " = " + v.Value.ToString()
 }
 
 func (v TypeValue) Clone() RuntimeValue {
@@ -993,3 +1272,132 @@ func (v TypeValue) VarType() ast.VariableType {
 func MakeTypeValue(name string, value ast.Expression) TypeValue {
 	return TypeValue{Name: name, Value: value}
 }
+
+// ResultValue is either Ok, holding the value of something that succeeded,
+// or Err, holding why it failed.
+type ResultValue struct {
+	Ok    bool
+	Value RuntimeValue
+}
+
+func (v ResultValue) Equals(other RuntimeValue) bool {
+	return equalValues(v, other, map[[2]any]bool{})
+}
+
+func (v ResultValue) Type() ValueType {
+	return Result
+}
+
+func (v ResultValue) Get() any {
+	return v.ToString()
+}
+
+func (v ResultValue) ToString() string {
+	return formatValue(v, map[any]bool{})
+}
+
+func (v ResultValue) Clone() RuntimeValue {
+	return ResultValue{Ok: v.Ok, Value: v.Value.Clone()}
+}
+
+func (v ResultValue) VarType() ast.VariableType {
+	return ast.AnyType
+}
+
+func MakeOkValue(value RuntimeValue) ResultValue {
+	return ResultValue{Ok: true, Value: value}
+}
+
+func MakeErrValue(value RuntimeValue) ResultValue {
+	return ResultValue{Ok: false, Value: value}
+}
+
+// MapValue is a hash map whose keys may be numbers, strings, bools or tuples
+// of them. Keys that are Equals are the same key, and keys, values and
+// entries are in the order the keys were added. Like arrays, maps are used by
+// pointer.
+type MapValue struct {
+	entries hashTable
+}
+
+func (v *MapValue) Equals(other RuntimeValue) bool {
+	return equalValues(v, other, map[[2]any]bool{})
+}
+
+func (v *MapValue) Type() ValueType {
+	return Map
+}
+
+func (v *MapValue) Get() any {
+	return formatValue(v, map[any]bool{})
+}
+
+func (v *MapValue) ToString() string {
+	return v.Get().(string)
+}
+
+func (v *MapValue) Clone() RuntimeValue {
+	return cloneValue(v, map[any]RuntimeValue{})
+}
+
+func (v *MapValue) VarType() ast.VariableType {
+	return ast.AnyType
+}
+
+// SetValue is a hash set of numbers, strings, bools or tuples of them, in the
+// order they were added. Like arrays, sets are used by pointer.
+type SetValue struct {
+	entries hashTable
+}
+
+func (v *SetValue) Equals(other RuntimeValue) bool {
+	otherSet, ok := other.(*SetValue)
+	if !ok || otherSet.entries.len() != v.entries.len() {
+		return false
+	}
+	for _, key := range v.entries.keys {
+		if _, found := otherSet.entries.get(key); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *SetValue) Type() ValueType {
+	return Set
+}
+
+func (v *SetValue) Get() any {
+	if v.entries.len() == 0 {
+		return "Set {}"
+	}
+	str := "Set { "
+	for i, key := range v.entries.keys {
+		str += key.ToString()
+		if i < v.entries.len()-1 {
+			str += ", "
+		}
+	}
+	str += " }"
+	return str
+}
+
+func (v *SetValue) ToString() string {
+	return v.Get().(string)
+}
+
+func (v *SetValue) Clone() RuntimeValue {
+	return cloneValue(v, map[any]RuntimeValue{})
+}
+
+func (v *SetValue) VarType() ast.VariableType {
+	return ast.AnyType
+}
+
+func MakeMapValue() *MapValue {
+	return &MapValue{entries: newHashTable("Map")}
+}
+
+func MakeSetValue() *SetValue {
+	return &SetValue{entries: newHashTable("Set")}
+}
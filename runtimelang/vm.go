@@ -0,0 +1,361 @@
+package runtimelang
+
+import (
+	"encoding/binary"
+
+	"github.com/Jamlie/Jamlang/ast"
+)
+
+// Execute runs chunk, produced by Compile, in env and returns the value of
+// its last statement, like Evaluate does for the program the chunk was
+// compiled from.
+func Execute(chunk *Chunk, env *Environment) (result RuntimeValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, NewJamErrorf(RuntimeError, "%v", r)
+		}
+	}()
+
+	result, _, err = run(chunk, env, &InitialValue{})
+	return result, err
+}
+
+// frame is the state of one chunk being run: its operand stack, the current
+// scope and the foreach loops and try statements it is in.
+type frame struct {
+	chunk     *Chunk
+	ip        int
+	env       *Environment
+	stack     []RuntimeValue
+	iterators []*forEachIterator
+	handlers  []tryHandler
+	// pending holds, for each finally block being run, the error to raise
+	// again when it ends, or nil.
+	pending []error
+	last    RuntimeValue
+}
+
+// tryHandler is where to continue when an error is raised in a try or catch
+// block, and the frame state to restore first.
+type tryHandler struct {
+	catch     int
+	finally   int
+	env       *Environment
+	stack     int
+	iterators int
+	pending   int
+}
+
+type forEachIterator struct {
+	collection RuntimeValue
+	keys       []RuntimeValue
+	values     []RuntimeValue
+	next       int
+}
+
+func (f *frame) push(value RuntimeValue) {
+	f.stack = append(f.stack, value)
+}
+
+func (f *frame) pop() RuntimeValue {
+	value := f.stack[len(f.stack)-1]
+	f.stack = f.stack[:len(f.stack)-1]
+	return value
+}
+
+func (f *frame) peek() RuntimeValue {
+	return f.stack[len(f.stack)-1]
+}
+
+// popN pops the top n values, returning them in the order they were pushed.
+func (f *frame) popN(n int) []RuntimeValue {
+	values := make([]RuntimeValue, n)
+	copy(values, f.stack[len(f.stack)-n:])
+	f.stack = f.stack[:len(f.stack)-n]
+	return values
+}
+
+func (f *frame) readOperand() int {
+	operand := int(binary.BigEndian.Uint32(f.chunk.Code[f.ip:]))
+	f.ip += operandSize
+	return operand
+}
+
+// run executes chunk in env. It returns the value of the last statement, or
+// the returned value and true when the chunk executes a return statement.
+func run(chunk *Chunk, env *Environment, last RuntimeValue) (RuntimeValue, bool, error) {
+	f := &frame{
+		chunk: chunk,
+		env:   env,
+		stack: make([]RuntimeValue, 0, 16),
+		last:  last,
+	}
+
+	for {
+		result, returned, err := f.execute()
+		if err == nil {
+			return result, returned, nil
+		}
+		if !f.handle(err) {
+			return nil, false, err
+		}
+	}
+}
+
+// handle continues at the innermost try handler after err was raised, and
+// reports whether there was one. Limit and exit errors are never handled.
+func (f *frame) handle(err error) bool {
+	if len(f.handlers) == 0 || stopsRun(err) {
+		return false
+	}
+
+	handler := f.handlers[len(f.handlers)-1]
+	f.handlers = f.handlers[:len(f.handlers)-1]
+	f.env = handler.env
+	f.stack = f.stack[:handler.stack]
+	f.iterators = f.iterators[:handler.iterators]
+	f.pending = f.pending[:handler.pending]
+
+	if handler.catch != 0 {
+		f.push(errorValue(err))
+		f.ip = handler.catch
+	} else {
+		f.pending = append(f.pending, err)
+		f.ip = handler.finally
+	}
+	return true
+}
+
+// execute runs instructions until the chunk ends, returns or raises an error.
+// Go panics are raised as RuntimeErrors.
+func (f *frame) execute() (result RuntimeValue, returned bool, err error) {
+	chunk := f.chunk
+	start := f.ip
+	defer func() {
+		if r := recover(); r != nil {
+			err = withPosition(NewJamErrorf(RuntimeError, "%v", r), chunk.Positions[start])
+		}
+	}()
+
+	for f.ip < len(chunk.Code) {
+		start = f.ip
+		op := Opcode(chunk.Code[f.ip])
+		f.ip++
+		if err := f.env.engine.step(); err != nil {
+			return nil, false, withPosition(err, chunk.Positions[start])
+		}
+
+		var value RuntimeValue
+		switch op {
+		case OpConstant:
+			f.push(chunk.Constants[f.readOperand()])
+		case OpNull:
+			f.push(MakeNullValue())
+		case OpPop:
+			f.last = f.pop()
+		case OpDiscard:
+			f.pop()
+		case OpGetVar:
+			value, err = f.env.LookupVariable(chunk.Names[f.readOperand()])
+			f.push(value)
+		case OpSetVar:
+			name := chunk.Names[f.readOperand()]
+			value, err = f.env.AssignVariable(name, f.pop())
+			f.push(value)
+		case OpDeclare:
+			declaration := chunk.Nodes[f.readOperand()].(*ast.VariableDeclaration)
+			value, err = declareVariable(*declaration, f.pop(), f.env, declaration.Type)
+			f.push(value)
+		case OpGetProperty:
+			value, err = propertyValue(f.pop(), chunk.Names[f.readOperand()])
+			f.push(value)
+		case OpGetIndex:
+			property := f.pop()
+			value, err = indexValue(f.pop(), property)
+			f.push(value)
+		case OpSetMember:
+			assigned := f.pop()
+			property := f.pop()
+			object := f.pop()
+			value, err = assignTypedMember(chunk.Names[f.readOperand()], object, property, assigned, f.env)
+			if err == nil {
+				err = f.env.engine.checkValue(object)
+			}
+			f.push(value)
+		case OpBinary:
+			operator := chunk.Names[f.readOperand()]
+			rhs := f.pop()
+			value, err = binaryOperation(f.pop(), rhs, operator)
+			if err == nil {
+				err = f.env.engine.checkValue(value)
+			}
+			f.push(value)
+		case OpUnary:
+			value, err = unaryOperation(f.pop(), chunk.Names[f.readOperand()])
+			f.push(value)
+		case OpStep:
+			value, err = stepValue(f.pop(), chunk.Names[f.readOperand()])
+			f.push(value)
+		case OpNot:
+			value, err = logicalNot(f.pop())
+			f.push(value)
+		case OpCheckBool:
+			message := chunk.Names[f.readOperand()]
+			if f.peek().Type() != Bool {
+				err = NewJamError(TypeError, message)
+			}
+		case OpJump:
+			f.ip = f.readOperand()
+		case OpJumpIfFalse, OpJumpIfFalseKeep, OpJumpIfTrueKeep:
+			target := f.readOperand()
+			message := chunk.Names[f.readOperand()]
+			condition := f.peek()
+			if op == OpJumpIfFalse {
+				f.pop()
+			}
+			boolean, ok := condition.(BoolValue)
+			if !ok {
+				err = NewJamError(TypeError, message)
+				break
+			}
+			if boolean.Value == (op == OpJumpIfTrueKeep) {
+				f.ip = target
+			}
+		case OpPushScope:
+			f.env = NewEnvironment(f.env)
+		case OpPopScope:
+			f.env = f.env.parent
+		case OpNextIteration:
+			f.env = f.env.nextIteration()
+		case OpCall:
+			argc := f.readOperand()
+			function := f.pop()
+			args := f.popN(argc)
+			value, err = callValue(function, args, f.env, chunk.Positions[start])
+			f.push(value)
+		case OpCallNamed:
+			call := chunk.Nodes[f.readOperand()].(*ast.CallExpression)
+			function := f.pop()
+			named := make([]namedArgument, len(call.NamedArgs))
+			for i, value := range f.popN(len(named)) {
+				named[i] = namedArgument{name: call.NamedArgs[i].Name, value: value}
+			}
+			args := f.popN(len(call.Args))
+			value, err = callNamed(function, args, named, f.env, chunk.Positions[start])
+			f.push(value)
+		case OpClosure:
+			compiled := chunk.Functions[f.readOperand()]
+			fn := newFunctionValue(*compiled.Declaration, f.env)
+			fn.code = compiled.Chunk
+			value, err = declareFunction(fn, f.env)
+			f.push(value)
+		case OpClass:
+			compiled := chunk.Classes[f.readOperand()]
+			value, err = EvaluateClassDeclaration(*compiled.Declaration, f.env)
+			if class, ok := value.(*ClassValue); ok {
+				attachMethodCode(class, compiled)
+			}
+			f.push(value)
+		case OpEval:
+			value, err = Evaluate(chunk.Nodes[f.readOperand()], f.env)
+			f.push(value)
+		case OpImport:
+			statement := chunk.Nodes[f.readOperand()].(*ast.ImportStatement)
+			value, err = importFile(*statement, f.env, runStatement)
+			f.push(value)
+		case OpIterInit:
+			collection := f.pop()
+			var keys, values []RuntimeValue
+			keys, values, err = forEachEntries(collection)
+			f.iterators = append(f.iterators, &forEachIterator{
+				collection: collection,
+				keys:       keys,
+				values:     values,
+			})
+		case OpIterNext:
+			target := f.readOperand()
+			iterator := f.iterators[len(f.iterators)-1]
+			if iterator.next >= len(iterator.values) {
+				f.ip = target
+				break
+			}
+			iterator.next++
+		case OpBindForEach:
+			statement := chunk.Nodes[f.readOperand()].(*ast.ForEachStatement)
+			iterator := f.iterators[len(f.iterators)-1]
+			i := iterator.next - 1
+			err = declareForEachVariables(*statement, f.env, iterator.collection, iterator.keys[i], iterator.values[i])
+		case OpIterEnd:
+			f.iterators = f.iterators[:len(f.iterators)-1]
+		case OpArray:
+			f.push(MakeArrayValue(f.popN(f.readOperand())))
+		case OpTuple:
+			f.push(TupleValue{Values: f.popN(f.readOperand())})
+		case OpObject:
+			literal := chunk.Nodes[f.readOperand()].(*ast.ObjectLiteral)
+			values := f.popN(len(literal.Properties))
+			object := newObjectValue(nil, len(values))
+			for i, property := range literal.Properties {
+				object.Set(property.Key, values[i])
+			}
+			f.push(object)
+		case OpTemplate:
+			literal := chunk.Nodes[f.readOperand()].(*ast.TemplateLiteral)
+			value = interpolate(literal.Strings, f.popN(len(literal.Expressions)))
+			err = f.env.engine.checkValue(value)
+			f.push(value)
+		case OpReturn:
+			return f.pop(), true, nil
+		case OpThrow:
+			err = newThrownError(f.pop())
+		case OpSetupTry:
+			f.handlers = append(f.handlers, tryHandler{
+				catch:     f.readOperand(),
+				finally:   f.readOperand(),
+				env:       f.env,
+				stack:     len(f.stack),
+				iterators: len(f.iterators),
+				pending:   len(f.pending),
+			})
+		case OpPopTry:
+			f.handlers = f.handlers[:len(f.handlers)-1]
+		case OpDeclareCatch:
+			_, err = f.env.DeclareVariable(chunk.Names[f.readOperand()], f.pop(), false, ast.AnyType)
+		case OpFinally:
+			f.pending = append(f.pending, nil)
+		case OpEndFinally:
+			err = f.pending[len(f.pending)-1]
+			f.pending = f.pending[:len(f.pending)-1]
+		default:
+			err = NewJamErrorf(RuntimeError, "Unknown opcode %s", op)
+		}
+
+		if err != nil {
+			return nil, false, withPosition(err, chunk.Positions[start])
+		}
+	}
+
+	return f.last, false, nil
+}
+
+// attachMethodCode makes the methods of class, declared by the tree-walking
+// EvaluateClassDeclaration, run their compiled bodies.
+func attachMethodCode(class *ClassValue, compiled CompiledClass) {
+	if class.Constructor != nil {
+		class.Constructor.code = compiled.Methods["constructor"]
+	}
+	for name, method := range class.Methods {
+		method.code = compiled.Methods[name]
+	}
+}
+
+// runStatement compiles and runs a single statement in env. The VM imports
+// files with it, so that their functions are compiled too.
+func runStatement(statement ast.Statement, env *Environment) (RuntimeValue, error) {
+	chunk, err := compileStatements([]ast.Statement{statement})
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := run(chunk, env, MakeNullValue())
+	return result, err
+}
@@ -7,6 +7,11 @@ const (
 	Integer
 	Float
 	String
+	// A backtick string with ${} is scanned as TemplateHead, then the tokens
+	// of each expression, separated by TemplateMiddle, then TemplateTail.
+	TemplateHead
+	TemplateMiddle
+	TemplateTail
 	Identifier
 	Equals
 	OpenParen
@@ -24,6 +29,7 @@ const (
 	ColonColon
 	Colon
 	Dot
+	Ellipsis
 	LSquirly
 	RSquirly
 	OpenBracket
@@ -50,6 +56,12 @@ const (
 	Xor
 	Import
 	Class
+	Extends
+	Type
+	Throw
+	Try
+	Catch
+	Finally
 
 	EndOfFile
 )